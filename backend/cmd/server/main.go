@@ -1,18 +1,43 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"github.com/stadtaev/lofam/backend/internal/agenda"
+	"github.com/stadtaev/lofam/backend/internal/attachment"
+	"github.com/stadtaev/lofam/backend/internal/blob"
+	"github.com/stadtaev/lofam/backend/internal/comment"
+	"github.com/stadtaev/lofam/backend/internal/fetch"
 	lofamhttp "github.com/stadtaev/lofam/backend/internal/http"
+	"github.com/stadtaev/lofam/backend/internal/idempotency"
+	"github.com/stadtaev/lofam/backend/internal/linkmeta"
+	"github.com/stadtaev/lofam/backend/internal/mealplan"
+	"github.com/stadtaev/lofam/backend/internal/member"
 	"github.com/stadtaev/lofam/backend/internal/note"
+	"github.com/stadtaev/lofam/backend/internal/notification"
+	"github.com/stadtaev/lofam/backend/internal/occasion"
+	"github.com/stadtaev/lofam/backend/internal/palette"
+	"github.com/stadtaev/lofam/backend/internal/pantry"
+	"github.com/stadtaev/lofam/backend/internal/project"
+	"github.com/stadtaev/lofam/backend/internal/recipe"
 	"github.com/stadtaev/lofam/backend/internal/shopping"
 	"github.com/stadtaev/lofam/backend/internal/sqlite"
+	"github.com/stadtaev/lofam/backend/internal/tag"
 	"github.com/stadtaev/lofam/backend/internal/task"
 	"github.com/stadtaev/lofam/backend/internal/wishlist"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take to finish
+// once the server is asked to stop.
+const shutdownTimeout = 15 * time.Second
+
 func main() {
 	dbPath := getEnv("DB_PATH", "lofam.db")
 	port := getEnv("PORT", "8080")
@@ -28,25 +53,111 @@ func main() {
 		log.Fatalf("failed to run migrations: %v", err)
 	}
 
+	projectStore := sqlite.NewProjectStore(db)
+	projectService := project.NewService(projectStore)
+
+	memberStore := sqlite.NewMemberStore(db)
+	memberService := member.NewService(memberStore)
+
 	taskStore := sqlite.NewTaskStore(db)
-	taskService := task.NewService(taskStore)
+	taskService := task.NewService(taskStore, projectService, memberService)
+
+	paletteStore := sqlite.NewPaletteStore(db)
+	paletteService := palette.NewService(paletteStore)
 
 	noteStore := sqlite.NewNoteStore(db)
-	noteService := note.NewService(noteStore)
+	noteService := note.NewService(noteStore, paletteService)
 
 	wishlistStore := sqlite.NewWishlistStore(db)
-	wishlistService := wishlist.NewService(wishlistStore)
+	linkExtractor := linkmeta.NewExtractor(fetch.NewHTTPFetcher())
+	wishlistService := wishlist.NewService(wishlistStore, memberService, paletteService, linkExtractor)
+
+	occasionStore := sqlite.NewOccasionStore(db)
+	occasionService := occasion.NewService(occasionStore, wishlistService, memberService)
+
+	pantryStore := sqlite.NewPantryStore(db)
+	pantryService := pantry.NewService(pantryStore)
 
 	shoppingStore := sqlite.NewShoppingStore(db)
-	shoppingService := shopping.NewService(shoppingStore)
+	shoppingService := shopping.NewService(shoppingStore, pantryService)
+
+	recipeStore := sqlite.NewRecipeStore(db)
+	recipeService := recipe.NewService(recipeStore, fetch.NewHTTPFetcher())
+
+	mealplanStore := sqlite.NewMealPlanStore(db)
+	mealplanService := mealplan.NewService(mealplanStore, recipeService, shoppingService)
+
+	agendaService := agenda.NewService(taskService, pantryService)
+
+	tagStore := sqlite.NewTagStore(db)
+	tagService := tag.NewService(tagStore)
+
+	blobStore, err := newBlobStore()
+	if err != nil {
+		log.Fatalf("failed to set up attachment storage: %v", err)
+	}
+	maxAttachmentSize, err := strconv.ParseInt(getEnv("ATTACHMENT_MAX_BYTES", strconv.Itoa(attachment.DefaultMaxSize)), 10, 64)
+	if err != nil {
+		log.Fatalf("invalid ATTACHMENT_MAX_BYTES: %v", err)
+	}
+	attachmentStore := sqlite.NewAttachmentStore(db)
+	attachmentService := attachment.NewService(attachmentStore, blobStore, taskService, noteService, maxAttachmentSize)
+	// Catch up on files left behind by deletions that failed to purge them.
+	if _, err := attachmentService.PurgeOrphans(context.Background()); err != nil {
+		log.Printf("failed to purge attachments: %v", err)
+	}
+
+	notificationStore := sqlite.NewNotificationStore(db)
+	notificationService := notification.NewService(notificationStore)
+
+	commentStore := sqlite.NewCommentStore(db)
+	commentService := comment.NewService(commentStore, taskService, memberService, notificationService)
+
+	idempotencyStore := sqlite.NewIdempotencyStore(db)
+	idempotencyService := idempotency.NewService(idempotencyStore)
+
+	server := lofamhttp.NewServer(taskService, noteService, wishlistService, memberService, occasionService, shoppingService, recipeService, mealplanService, pantryService, agendaService, tagService, paletteService, attachmentService, commentService, notificationService, projectService, idempotencyService, db, staticDir)
 
-	server := lofamhttp.NewServer(taskService, noteService, wishlistService, shoppingService, staticDir)
+	httpServer := &http.Server{Addr: ":" + port, Handler: server.Router()}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Printf("starting server on :%s", port)
+		log.Printf("serving static files from %s", staticDir)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("server error: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Printf("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to shut down server: %v", err)
+	}
+	// Let background link lookups finish before the database is closed.
+	wishlistService.Wait()
+}
 
-	log.Printf("starting server on :%s", port)
-	log.Printf("serving static files from %s", staticDir)
-	if err := http.ListenAndServe(":"+port, server.Router()); err != nil {
-		log.Fatalf("server error: %v", err)
+// newBlobStore keeps attachments in ATTACHMENT_DIR, or in an S3 bucket when
+// S3_BUCKET is set.
+func newBlobStore() (blob.Store, error) {
+	bucket := os.Getenv("S3_BUCKET")
+	if bucket == "" {
+		return blob.NewFileStore(getEnv("ATTACHMENT_DIR", "./attachments"))
 	}
+	return blob.NewS3Store(blob.S3Config{
+		Endpoint:  getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+		Region:    getEnv("S3_REGION", "us-east-1"),
+		Bucket:    bucket,
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+		PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+	})
 }
 
 func getEnv(key, fallback string) string {
@@ -0,0 +1,28 @@
+package agenda
+
+import (
+	"github.com/stadtaev/lofam/backend/internal/pantry"
+	"github.com/stadtaev/lofam/backend/internal/task"
+)
+
+// DateLayout is the format of agenda dates.
+const DateLayout = "2006-01-02"
+
+// DefaultExpiryDays is how far ahead pantry expiry dates are looked at when
+// the request does not say.
+const DefaultExpiryDays = 3
+
+// Agenda is what needs attention on a given day: tasks that are due or
+// overdue and food that should be used up.
+type Agenda struct {
+	Date     string                `json:"date"`
+	Tasks    []task.Task           `json:"tasks"`
+	Expiring []pantry.ExpiringItem `json:"expiring"`
+}
+
+type Request struct {
+	// Date defaults to today.
+	Date string
+	// Days is how many days ahead to look for expiring pantry items.
+	Days *int
+}
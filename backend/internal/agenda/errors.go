@@ -0,0 +1,13 @@
+package agenda
+
+type ValidationError struct {
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Message
+}
+
+func ErrValidation(msg string) ValidationError {
+	return ValidationError{Message: msg}
+}
@@ -0,0 +1,66 @@
+package agenda
+
+import (
+	"context"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/pantry"
+	"github.com/stadtaev/lofam/backend/internal/task"
+)
+
+// Tasks supplies the tasks that are due.
+type Tasks interface {
+	Due(ctx context.Context, before time.Time) ([]task.Task, error)
+}
+
+// Pantry supplies the items that are about to expire.
+type Pantry interface {
+	Expiring(ctx context.Context, today time.Time, days int) ([]pantry.ExpiringItem, error)
+}
+
+type Service struct {
+	tasks  Tasks
+	pantry Pantry
+}
+
+func NewService(tasks Tasks, pantry Pantry) *Service {
+	return &Service{tasks: tasks, pantry: pantry}
+}
+
+// Get returns the agenda for a day: every unfinished task due by the end of
+// it, and the pantry items expiring within the requested number of days.
+func (s *Service) Get(ctx context.Context, req Request) (*Agenda, error) {
+	day := time.Now()
+	if req.Date != "" {
+		var err error
+		if day, err = time.ParseInLocation(DateLayout, req.Date, time.Local); err != nil {
+			return nil, ErrValidation("date must be in YYYY-MM-DD format")
+		}
+	}
+	year, month, date := day.Date()
+	day = time.Date(year, month, date, 0, 0, 0, 0, day.Location())
+
+	days := DefaultExpiryDays
+	if req.Days != nil {
+		days = *req.Days
+	}
+	if days < 0 {
+		return nil, ErrValidation("days must not be negative")
+	}
+
+	tasks, err := s.tasks.Due(ctx, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	expiring, err := s.pantry.Expiring(ctx, day, days)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Agenda{
+		Date:     day.Format(DateLayout),
+		Tasks:    tasks,
+		Expiring: expiring,
+	}, nil
+}
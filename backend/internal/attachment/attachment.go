@@ -0,0 +1,122 @@
+package attachment
+
+import (
+	"bytes"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultMaxSize is the upload limit used unless configured otherwise.
+const DefaultMaxSize = 10 << 20
+
+// ThumbnailSize is the largest width or height of a thumbnail, in pixels.
+const ThumbnailSize = 400
+
+// OwnerType names the kind of item a file is attached to.
+type OwnerType string
+
+const (
+	OwnerTask OwnerType = "task"
+	OwnerNote OwnerType = "note"
+)
+
+type Attachment struct {
+	ID        int64     `json:"id"`
+	OwnerType OwnerType `json:"ownerType"`
+	OwnerID   int64     `json:"ownerId"`
+	Filename  string    `json:"filename"`
+	// ContentType is detected from the file's content; the type claimed by
+	// the uploader is ignored.
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	// ThumbnailType is the content type of the thumbnail, or empty for
+	// files that have none.
+	ThumbnailType string `json:"thumbnailType,omitempty"`
+	// Key locates the content in the blob store.
+	Key       string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ThumbnailKey locates the thumbnail in the blob store.
+func (a Attachment) ThumbnailKey() string {
+	return a.Key + "-thumb"
+}
+
+// Inline reports whether browsers may display the file in place rather
+// than download it.
+func (a Attachment) Inline() bool {
+	return strings.HasPrefix(a.ContentType, "image/") || a.ContentType == "application/pdf"
+}
+
+// UploadRequest describes a file being attached.
+type UploadRequest struct {
+	OwnerType OwnerType
+	OwnerID   int64
+	Filename  string
+}
+
+func (r *UploadRequest) Validate() error {
+	r.Filename = cleanFilename(r.Filename)
+	if r.Filename == "" {
+		return ErrValidation("filename is required")
+	}
+	return nil
+}
+
+// cleanFilename drops any directory part and control characters, and
+// shortens overlong names while keeping the extension.
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(name, ""))
+	name = strings.TrimSpace(name)
+
+	const maxLen = 200
+	if len(name) > maxLen {
+		ext := path.Ext(name)
+		if len(ext) > 20 {
+			ext = ""
+		}
+		stem := name[:maxLen-len(ext)]
+		for !utf8.ValidString(stem) {
+			stem = stem[:len(stem)-1]
+		}
+		name = stem + ext
+	}
+	return name
+}
+
+// allowedTypes are the content types that may be attached: photos, scans
+// and plain text.
+var allowedTypes = map[string]bool{
+	"image/jpeg":                true,
+	"image/png":                 true,
+	"image/gif":                 true,
+	"image/webp":                true,
+	"image/heic":                true,
+	"application/pdf":           true,
+	"text/plain; charset=utf-8": true,
+}
+
+// sniff detects the content type from the first bytes of a file. It adds
+// HEIC, the default format of iPhone photos, to what the standard library
+// recognises.
+func sniff(head []byte) string {
+	if len(head) >= 12 && bytes.Equal(head[4:8], []byte("ftyp")) {
+		switch string(head[8:12]) {
+		case "heic", "heix", "heim", "heis", "mif1", "msf1":
+			return "image/heic"
+		}
+	}
+	return http.DetectContentType(head)
+}
@@ -0,0 +1,46 @@
+package attachment
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCleanFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"slip.jpg", "slip.jpg"},
+		{`C:\fakepath\slip.jpg`, "slip.jpg"},
+		{"../../etc/passwd", "passwd"},
+		{"bad\x00\nname.pdf", "badname.pdf"},
+		{"  ", ""},
+		{"/", ""},
+		{strings.Repeat("a", 300) + ".pdf", strings.Repeat("a", 196) + ".pdf"},
+	}
+
+	for _, tt := range tests {
+		if got := cleanFilename(tt.name); got != tt.want {
+			t.Errorf("cleanFilename(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name string
+		head string
+		want string
+	}{
+		{"jpeg", "\xff\xd8\xff\xe0\x00\x10JFIF", "image/jpeg"},
+		{"heic", "\x00\x00\x00\x18ftypheic\x00\x00\x00\x00", "image/heic"},
+		{"pdf", "%PDF-1.7\n", "application/pdf"},
+		{"html", "<!DOCTYPE html><p>hi", "text/html; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		if got := sniff([]byte(tt.head)); got != tt.want {
+			t.Errorf("%s: sniff = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
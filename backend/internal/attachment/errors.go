@@ -0,0 +1,68 @@
+package attachment
+
+import "fmt"
+
+type ValidationError struct {
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Message
+}
+
+func ErrValidation(msg string) ValidationError {
+	return ValidationError{Message: msg}
+}
+
+type NotFoundError struct {
+	ID int64
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("attachment with id %d not found", e.ID)
+}
+
+func ErrNotFound(id int64) NotFoundError {
+	return NotFoundError{ID: id}
+}
+
+// NoThumbnailError is returned when a thumbnail is requested for a file
+// that has none.
+type NoThumbnailError struct {
+	ID int64
+}
+
+func (e NoThumbnailError) Error() string {
+	return fmt.Sprintf("attachment with id %d has no thumbnail", e.ID)
+}
+
+func ErrNoThumbnail(id int64) NoThumbnailError {
+	return NoThumbnailError{ID: id}
+}
+
+// TooLargeError is returned when an upload exceeds the size limit.
+type TooLargeError struct {
+	Limit int64
+}
+
+func (e TooLargeError) Error() string {
+	return fmt.Sprintf("file is larger than the limit of %d bytes", e.Limit)
+}
+
+func ErrTooLarge(limit int64) TooLargeError {
+	return TooLargeError{Limit: limit}
+}
+
+// UnsupportedTypeError is returned when the content of an upload is not
+// one of the accepted file types.
+type UnsupportedTypeError struct {
+	ContentType string
+}
+
+func (e UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("files of type %s cannot be attached", e.ContentType)
+}
+
+func ErrUnsupportedType(contentType string) UnsupportedTypeError {
+	return UnsupportedTypeError{ContentType: contentType}
+}
@@ -0,0 +1,286 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/imaging"
+	"github.com/stadtaev/lofam/backend/internal/note"
+	"github.com/stadtaev/lofam/backend/internal/task"
+)
+
+// Blobs stores the content of attachments.
+type Blobs interface {
+	Put(ctx context.Context, key, contentType string, r io.Reader, size int64) error
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Tasks and Notes look up the items files are attached to.
+type Tasks interface {
+	GetByID(ctx context.Context, id int64) (*task.Task, error)
+}
+
+type Notes interface {
+	GetByID(ctx context.Context, id int64) (*note.Note, error)
+}
+
+type Service struct {
+	store   Store
+	blobs   Blobs
+	tasks   Tasks
+	notes   Notes
+	maxSize int64
+}
+
+func NewService(store Store, blobs Blobs, tasks Tasks, notes Notes, maxSize int64) *Service {
+	return &Service{store: store, blobs: blobs, tasks: tasks, notes: notes, maxSize: maxSize}
+}
+
+// MaxSize is the largest file that may be uploaded, in bytes.
+func (s *Service) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Upload stores the file read from body and attaches it to its owner. The
+// file is spooled to disk first, so that its size and type are known
+// before anything is written to the blob store. Images are stored without
+// their metadata, alongside a thumbnail when their format allows.
+func (s *Service) Upload(ctx context.Context, req UploadRequest, body io.Reader) (*Attachment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.CheckOwner(ctx, req.OwnerType, req.OwnerID); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "lofam-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, io.LimitReader(body, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if size > s.maxSize {
+		return nil, ErrTooLarge(s.maxSize)
+	}
+	if size == 0 {
+		return nil, ErrValidation("file is empty")
+	}
+
+	head := make([]byte, 512)
+	n, err := tmp.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	contentType := sniff(head[:n])
+	if !allowedTypes[contentType] {
+		return nil, ErrUnsupportedType(contentType)
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	a := &Attachment{
+		OwnerType:   req.OwnerType,
+		OwnerID:     req.OwnerID,
+		Filename:    req.Filename,
+		ContentType: contentType,
+		Size:        size,
+		CreatedAt:   time.Now(),
+	}
+	if a.Key, err = newKey(); err != nil {
+		return nil, err
+	}
+
+	var content io.Reader = tmp
+	var thumb []byte
+	if strings.HasPrefix(contentType, "image/") {
+		data, err := io.ReadAll(tmp)
+		if err != nil {
+			return nil, err
+		}
+		if thumb, a.ThumbnailType, err = s.thumbnail(contentType, data); err != nil {
+			return nil, err
+		}
+		if data, err = imaging.StripMetadata(contentType, data); err != nil {
+			return nil, ErrValidation("image could not be read: " + err.Error())
+		}
+		content, a.Size = bytes.NewReader(data), int64(len(data))
+	}
+
+	if err := s.blobs.Put(ctx, a.Key, contentType, content, a.Size); err != nil {
+		return nil, fmt.Errorf("store attachment: %w", err)
+	}
+	if thumb != nil {
+		if err := s.blobs.Put(ctx, a.ThumbnailKey(), a.ThumbnailType, bytes.NewReader(thumb), int64(len(thumb))); err != nil {
+			s.deleteBlobs(context.WithoutCancel(ctx), a)
+			return nil, fmt.Errorf("store thumbnail: %w", err)
+		}
+	}
+
+	if err := s.store.Create(ctx, a); err != nil {
+		s.deleteBlobs(context.WithoutCancel(ctx), a)
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int64) (*Attachment, error) {
+	return s.store.GetByID(ctx, id)
+}
+
+// List returns the files attached to an item, oldest first.
+func (s *Service) List(ctx context.Context, owner OwnerType, ownerID int64) ([]Attachment, error) {
+	if err := s.CheckOwner(ctx, owner, ownerID); err != nil {
+		return nil, err
+	}
+	return s.store.List(ctx, owner, ownerID)
+}
+
+// Open returns an attachment together with its content, which the caller
+// must close.
+func (s *Service) Open(ctx context.Context, id int64) (*Attachment, io.ReadSeekCloser, error) {
+	a, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := s.blobs.Open(ctx, a.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open attachment %d: %w", id, err)
+	}
+	return a, content, nil
+}
+
+// OpenThumbnail returns an image attachment together with its thumbnail,
+// which the caller must close.
+func (s *Service) OpenThumbnail(ctx context.Context, id int64) (*Attachment, io.ReadSeekCloser, error) {
+	a, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if a.ThumbnailType == "" {
+		return nil, nil, ErrNoThumbnail(id)
+	}
+	content, err := s.blobs.Open(ctx, a.ThumbnailKey())
+	if err != nil {
+		return nil, nil, fmt.Errorf("open thumbnail of attachment %d: %w", id, err)
+	}
+	return a, content, nil
+}
+
+// Delete removes the content before the record, so that a failure leaves
+// a record that can be deleted again rather than an unreachable blob.
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	a, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.deleteBlobs(ctx, a); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, id)
+}
+
+// DeleteOwned removes the files attached to a task or note that has just
+// been deleted. Owners are deleted without regard to their attachments,
+// since blobs live outside the database transaction.
+func (s *Service) DeleteOwned(ctx context.Context, owner OwnerType, ownerID int64) error {
+	attachments, err := s.store.List(ctx, owner, ownerID)
+	if err != nil {
+		return err
+	}
+	_, err = s.purge(ctx, attachments)
+	return err
+}
+
+// PurgeOrphans deletes the attachments of every deleted task and note and
+// returns how many were removed. It sweeps the whole table, so it is meant
+// for startup, to catch up on deletions whose files failed to be removed.
+func (s *Service) PurgeOrphans(ctx context.Context) (int, error) {
+	orphans, err := s.store.Orphans(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return s.purge(ctx, orphans)
+}
+
+func (s *Service) purge(ctx context.Context, attachments []Attachment) (int, error) {
+	var errs []error
+	purged := 0
+	for _, a := range attachments {
+		if err := s.deleteBlobs(ctx, &a); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := s.store.Delete(ctx, a.ID); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		purged++
+	}
+	return purged, errors.Join(errs...)
+}
+
+// thumbnail makes the thumbnail of an image. Formats the standard library
+// cannot decode get none; a file that claims to be a JPEG, PNG or GIF but
+// does not decode is refused.
+func (s *Service) thumbnail(contentType string, data []byte) ([]byte, string, error) {
+	thumb, thumbType, err := imaging.Thumbnail(contentType, data, ThumbnailSize)
+	if errors.Is(err, imaging.ErrUnsupported) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", ErrValidation("image could not be read: " + err.Error())
+	}
+	return thumb, thumbType, nil
+}
+
+func (s *Service) deleteBlobs(ctx context.Context, a *Attachment) error {
+	if a.ThumbnailType != "" {
+		if err := s.blobs.Delete(ctx, a.ThumbnailKey()); err != nil {
+			return fmt.Errorf("delete thumbnail of attachment %d: %w", a.ID, err)
+		}
+	}
+	if err := s.blobs.Delete(ctx, a.Key); err != nil {
+		return fmt.Errorf("delete attachment %d: %w", a.ID, err)
+	}
+	return nil
+}
+
+// CheckOwner returns an error unless the item exists.
+func (s *Service) CheckOwner(ctx context.Context, owner OwnerType, id int64) error {
+	switch owner {
+	case OwnerTask:
+		_, err := s.tasks.GetByID(ctx, id)
+		return err
+	case OwnerNote:
+		_, err := s.notes.GetByID(ctx, id)
+		return err
+	}
+	return ErrValidation(fmt.Sprintf("unknown owner type %q", owner))
+}
+
+// newKey returns a random blob key. The first two characters are repeated
+// as a prefix so that no directory or listing grows too large.
+func newKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	name := hex.EncodeToString(b)
+	return name[:2] + "/" + name, nil
+}
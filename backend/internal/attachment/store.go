@@ -0,0 +1,12 @@
+package attachment
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, a *Attachment) error
+	GetByID(ctx context.Context, id int64) (*Attachment, error)
+	List(ctx context.Context, owner OwnerType, ownerID int64) ([]Attachment, error)
+	Delete(ctx context.Context, id int64) error
+	// Orphans returns the attachments whose owner has been deleted.
+	Orphans(ctx context.Context) ([]Attachment, error)
+}
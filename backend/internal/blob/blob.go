@@ -0,0 +1,24 @@
+// Package blob stores uploaded files outside the database, on the local
+// filesystem or in an S3-compatible bucket.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when no blob is stored under a key.
+var ErrNotFound = errors.New("blob not found")
+
+// Store saves and serves blobs by key. Keys are chosen by the caller, may
+// contain slashes, and are never reused.
+type Store interface {
+	// Put stores size bytes read from r under key.
+	Put(ctx context.Context, key, contentType string, r io.Reader, size int64) error
+	// Open returns the blob for reading. Seeking is cheap, so the result
+	// can be used to serve range requests.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes the blob. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,41 @@
+//go:build integration
+
+package blob
+
+import (
+	"os"
+	"testing"
+)
+
+// TestS3StoreMinIO runs against a real S3-compatible server, for example
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//
+// with S3_TEST_ENDPOINT=http://localhost:9000 and an existing bucket named
+// by S3_TEST_BUCKET. The credentials default to MinIO's.
+func TestS3StoreMinIO(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT not set")
+	}
+
+	s, err := NewS3Store(S3Config{
+		Endpoint:  endpoint,
+		Region:    os.Getenv("S3_TEST_REGION"),
+		Bucket:    envOr("S3_TEST_BUCKET", "lofam-test"),
+		AccessKey: envOr("S3_TEST_ACCESS_KEY", "minioadmin"),
+		SecretKey: envOr("S3_TEST_SECRET_KEY", "minioadmin"),
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,51 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxBodyLength = 5000
+
+// Comment is a message in the discussion thread of a task.
+type Comment struct {
+	ID     int64 `json:"id"`
+	TaskID int64 `json:"taskId"`
+	// AuthorID is nil once the author has been removed from the household.
+	AuthorID *int64 `json:"authorId"`
+	Body     string `json:"body"`
+	// Mentions are the members named with @ in the body.
+	Mentions  []int64   `json:"mentions"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CreateRequest struct {
+	Body string `json:"body"`
+}
+
+func (r *CreateRequest) Validate() error {
+	r.Body = strings.TrimSpace(r.Body)
+	return validateBody(r.Body)
+}
+
+type UpdateRequest struct {
+	Body string `json:"body"`
+}
+
+func (r *UpdateRequest) Validate() error {
+	r.Body = strings.TrimSpace(r.Body)
+	return validateBody(r.Body)
+}
+
+func validateBody(body string) error {
+	if body == "" {
+		return ErrValidation("body is required")
+	}
+	if utf8.RuneCountInString(body) > maxBodyLength {
+		return ErrValidation(fmt.Sprintf("body must be at most %d characters", maxBodyLength))
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package comment
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/stadtaev/lofam/backend/internal/member"
+)
+
+// ParseMentions returns the members named with an @ in body, in the order
+// they first appear. Names match case-insensitively and may contain
+// spaces; where names overlap, as with "@Anna" and "@Anna Lena", the
+// longest one wins. A mention must not run into further letters or
+// digits, so "@Annabel" does not mention Anna.
+func ParseMentions(body string, members []member.Member) []int64 {
+	byLength := append([]member.Member(nil), members...)
+	sort.SliceStable(byLength, func(i, j int) bool {
+		return len(byLength[i].Name) > len(byLength[j].Name)
+	})
+
+	lower := strings.ToLower(body)
+	seen := map[int64]bool{}
+	mentions := []int64{}
+	for i := 0; i < len(lower); i++ {
+		if lower[i] != '@' {
+			continue
+		}
+		if prev, _ := utf8.DecodeLastRuneInString(lower[:i]); i > 0 && isNameRune(prev) {
+			continue
+		}
+
+		rest := lower[i+1:]
+		for _, m := range byLength {
+			name := strings.ToLower(m.Name)
+			if name == "" || !strings.HasPrefix(rest, name) {
+				continue
+			}
+			if next, _ := utf8.DecodeRuneInString(rest[len(name):]); len(rest) > len(name) && isNameRune(next) {
+				continue
+			}
+			if !seen[m.ID] {
+				seen[m.ID] = true
+				mentions = append(mentions, m.ID)
+			}
+			i += len(name)
+			break
+		}
+	}
+	return mentions
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,39 @@
+package comment
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/member"
+)
+
+func TestParseMentions(t *testing.T) {
+	members := []member.Member{
+		{ID: 1, Name: "Anna"},
+		{ID: 2, Name: "Anna Lena"},
+		{ID: 3, Name: "Dad"},
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want []int64
+	}{
+		{"none", "no mentions here", []int64{}},
+		{"single", "@Dad can you take this?", []int64{3}},
+		{"case insensitive", "ping @dad and @ANNA", []int64{3, 1}},
+		{"longest name wins", "@Anna Lena, not @Anna", []int64{2, 1}},
+		{"repeated", "@Dad @Dad @dad", []int64{3}},
+		{"runs into letters", "@Annabel and @Dads", []int64{}},
+		{"inside a word", "mail dad@dad.example", []int64{}},
+		{"unknown", "@Grandma", []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMentions(tt.body, members); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMentions(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
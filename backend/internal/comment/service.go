@@ -0,0 +1,194 @@
+package comment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/member"
+	"github.com/stadtaev/lofam/backend/internal/notification"
+	"github.com/stadtaev/lofam/backend/internal/task"
+)
+
+// Tasks looks up the tasks being discussed.
+type Tasks interface {
+	GetByID(ctx context.Context, id int64) (*task.Task, error)
+}
+
+// Members looks up comment authors and the members that can be mentioned.
+type Members interface {
+	GetByID(ctx context.Context, id int64) (*member.Member, error)
+	List(ctx context.Context) ([]member.Member, error)
+}
+
+// Notifier tells members they were mentioned.
+type Notifier interface {
+	Notify(ctx context.Context, n *notification.Notification) error
+}
+
+type Service struct {
+	store    Store
+	tasks    Tasks
+	members  Members
+	notifier Notifier
+}
+
+func NewService(store Store, tasks Tasks, members Members, notifier Notifier) *Service {
+	return &Service{store: store, tasks: tasks, members: members, notifier: notifier}
+}
+
+// Create adds a comment by the requesting member and notifies the members
+// it mentions.
+func (s *Service) Create(ctx context.Context, taskID int64, req CreateRequest) (*Comment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	author, err := s.author(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.tasks.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.members.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c := &Comment{
+		TaskID:    taskID,
+		AuthorID:  &author.ID,
+		Body:      req.Body,
+		Mentions:  ParseMentions(req.Body, members),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.store.Create(ctx, c); err != nil {
+		return nil, err
+	}
+	if err := s.notify(ctx, author, t, c, c.Mentions); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// List returns the comments on a task, oldest first.
+func (s *Service) List(ctx context.Context, taskID int64) ([]Comment, error) {
+	if _, err := s.tasks.GetByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.store.List(ctx, taskID)
+}
+
+// Update changes the body of a comment. Only its author may edit it, and
+// only members not mentioned before are notified.
+func (s *Service) Update(ctx context.Context, taskID, id int64, req UpdateRequest) (*Comment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	author, err := s.author(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.ownComment(ctx, author.ID, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.tasks.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.members.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mentionedBefore := make(map[int64]bool, len(c.Mentions))
+	for _, memberID := range c.Mentions {
+		mentionedBefore[memberID] = true
+	}
+	c.Body = req.Body
+	c.Mentions = ParseMentions(req.Body, members)
+	c.UpdatedAt = time.Now()
+
+	if err := s.store.Update(ctx, c); err != nil {
+		return nil, err
+	}
+
+	var added []int64
+	for _, memberID := range c.Mentions {
+		if !mentionedBefore[memberID] {
+			added = append(added, memberID)
+		}
+	}
+	if err := s.notify(ctx, author, t, c, added); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Delete removes a comment. Only its author may delete it.
+func (s *Service) Delete(ctx context.Context, taskID, id int64) error {
+	author, err := s.author(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := s.ownComment(ctx, author.ID, taskID, id); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, id)
+}
+
+// author returns the requesting member.
+func (s *Service) author(ctx context.Context) (*member.Member, error) {
+	memberID, ok := member.IDFromContext(ctx)
+	if !ok {
+		return nil, ErrForbidden("commenting requires a household member")
+	}
+	return s.members.GetByID(ctx, memberID)
+}
+
+// ownComment loads a comment on the task and checks that the member wrote
+// it.
+func (s *Service) ownComment(ctx context.Context, authorID, taskID, id int64) (*Comment, error) {
+	c, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.TaskID != taskID {
+		return nil, ErrNotFound(id)
+	}
+	if c.AuthorID == nil || *c.AuthorID != authorID {
+		return nil, ErrForbidden("only the author of a comment can change it")
+	}
+	return c, nil
+}
+
+// notify tells the given members they were mentioned. Authors are not
+// notified of their own mentions.
+func (s *Service) notify(ctx context.Context, author *member.Member, t *task.Task, c *Comment, memberIDs []int64) error {
+	for _, memberID := range memberIDs {
+		if memberID == author.ID {
+			continue
+		}
+		err := s.notifier.Notify(ctx, &notification.Notification{
+			MemberID:  memberID,
+			Kind:      notification.KindMention,
+			ActorID:   &author.ID,
+			TaskID:    &t.ID,
+			CommentID: &c.ID,
+			Message:   fmt.Sprintf("%s mentioned you on %q", author.Name, t.Title),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package comment
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, c *Comment) error
+	GetByID(ctx context.Context, id int64) (*Comment, error)
+	// List returns the comments on a task, oldest first.
+	List(ctx context.Context, taskID int64) ([]Comment, error)
+	Update(ctx context.Context, c *Comment) error
+	Delete(ctx context.Context, id int64) error
+}
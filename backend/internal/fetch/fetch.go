@@ -0,0 +1,149 @@
+// Package fetch downloads web pages for importers and link previews.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultTimeout  = 10 * time.Second
+	DefaultMaxBytes = 5 << 20
+	userAgent       = "lofam/1.0 (+https://github.com/stadtaev/lofam)"
+)
+
+var (
+	// ErrTooLarge is returned when a response exceeds the size limit.
+	ErrTooLarge = errors.New("response body too large")
+	// ErrBlockedAddress is returned when a URL resolves to an address on a
+	// private, loopback or otherwise internal network.
+	ErrBlockedAddress = errors.New("address is not publicly routable")
+)
+
+// HTTPFetcher fetches pages over HTTP with a timeout and a size limit. By
+// default it refuses to connect to internal addresses, so that URLs pasted
+// by users cannot be used to reach services on the server's network.
+type HTTPFetcher struct {
+	client       *http.Client
+	timeout      time.Duration
+	maxBytes     int64
+	allowPrivate bool
+}
+
+type Option func(*HTTPFetcher)
+
+// WithTimeout limits how long a whole fetch may take, including redirects.
+func WithTimeout(d time.Duration) Option {
+	return func(f *HTTPFetcher) { f.timeout = d }
+}
+
+// WithMaxBytes limits the size of a response body.
+func WithMaxBytes(n int64) Option {
+	return func(f *HTTPFetcher) { f.maxBytes = n }
+}
+
+// AllowPrivateNetworks turns off the internal address check. It is meant
+// for tests that fetch from an httptest server on the loopback interface.
+func AllowPrivateNetworks() Option {
+	return func(f *HTTPFetcher) { f.allowPrivate = true }
+}
+
+func NewHTTPFetcher(opts ...Option) *HTTPFetcher {
+	f := &HTTPFetcher{
+		timeout:  DefaultTimeout,
+		maxBytes: DefaultMaxBytes,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	dialer := &net.Dialer{Timeout: f.timeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !f.allowPrivate {
+		// Checking the address at dial time covers every redirect and
+		// defeats DNS rebinding, since it sees the IP actually connected to.
+		// A proxy would hide that address, so none is used.
+		dialer.Control = refusePrivate
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	f.client = &http.Client{Timeout: f.timeout, Transport: transport}
+	return f
+}
+
+// Fetch returns the body of the page at rawURL. Only http and https URLs are
+// allowed.
+func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid url %q", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrBlockedAddress) {
+			return nil, fmt.Errorf("fetch %s: %w", u.Host, ErrBlockedAddress)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: unexpected status %d", u.Host, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > f.maxBytes {
+		return nil, ErrTooLarge
+	}
+	return body, nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which IsPrivate does not
+// cover.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !IsPublic(addr) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// IsPublic reports whether addr is a globally routable unicast address.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(addr) &&
+		!(addr.Is4() && addr.As4()[0] == 0)
+}
@@ -0,0 +1,118 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchRefusesPrivateAddresses(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer ts.Close()
+
+	_, err := NewHTTPFetcher().Fetch(context.Background(), ts.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Fetch() error = %v, want ErrBlockedAddress", err)
+	}
+}
+
+func TestFetchRefusesRedirectToPrivateAddress(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer ts.Close()
+
+	// The first hop is allowed so that only the redirect target is checked.
+	f := NewHTTPFetcher()
+	f.client.Transport = redirectTo(ts.URL)
+
+	_, err := f.Fetch(context.Background(), "http://example.com/")
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Fetch() error = %v, want ErrBlockedAddress", err)
+	}
+}
+
+// redirectTo answers every request with a redirect to target and hands
+// requests for target to the real, protected transport.
+func redirectTo(target string) http.RoundTripper {
+	protected := NewHTTPFetcher().client.Transport
+	return roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(target, "http://"+r.URL.Host) {
+			return protected.RoundTrip(r)
+		}
+		return &http.Response{
+			StatusCode: http.StatusFound,
+			Header:     http.Header{"Location": {target}},
+			Body:       http.NoBody,
+			Request:    r,
+		}, nil
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestFetchLimits(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer ts.Close()
+
+	f := NewHTTPFetcher(AllowPrivateNetworks(), WithMaxBytes(100), WithTimeout(50*time.Millisecond))
+
+	body, err := f.Fetch(context.Background(), ts.URL)
+	if err != nil || len(body) != 100 {
+		t.Errorf("Fetch() = %d bytes, %v; want 100 bytes", len(body), err)
+	}
+
+	small := NewHTTPFetcher(AllowPrivateNetworks(), WithMaxBytes(99))
+	if _, err := small.Fetch(context.Background(), ts.URL); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Fetch() error = %v, want ErrTooLarge", err)
+	}
+
+	if _, err := f.Fetch(context.Background(), ts.URL+"/slow"); err == nil {
+		t.Error("Fetch() of slow page succeeded, want timeout")
+	}
+
+	if _, err := f.Fetch(context.Background(), "file:///etc/passwd"); err == nil {
+		t.Error("Fetch() of file URL succeeded, want error")
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fc00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsPublic(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("IsPublic(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/stadtaev/lofam/backend/internal/agenda"
+)
+
+func (s *Server) getAgenda(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := agenda.Request{Date: query.Get("date")}
+
+	if v := query.Get("days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			handleError(w, agenda.ErrValidation("days must be a number"))
+			return
+		}
+		req.Days = &days
+	}
+
+	a, err := s.agendaService.Get(r.Context(), req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, a)
+}
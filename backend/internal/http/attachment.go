@@ -0,0 +1,192 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/stadtaev/lofam/backend/internal/attachment"
+)
+
+// multipartOverhead allows for the part headers and boundaries around an
+// uploaded file when limiting the request body.
+const multipartOverhead = 64 << 10
+
+func (s *Server) listAttachments(owner attachment.OwnerType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		attachments, err := s.attachmentService.List(r.Context(), owner, id)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, attachments)
+	}
+}
+
+// uploadAttachment attaches the file sent in the "file" field of a
+// multipart form. The file is streamed to the attachment service rather
+// than parsed into memory.
+func (s *Server) uploadAttachment(owner attachment.OwnerType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		// Refuse uploads for a missing owner before reading the body.
+		if err := s.attachmentService.CheckOwner(r.Context(), owner, id); err != nil {
+			handleError(w, err)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, s.attachmentService.MaxSize()+multipartOverhead)
+		mr, err := r.MultipartReader()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "request must be multipart/form-data")
+			return
+		}
+
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				writeError(w, http.StatusBadRequest, "file is required")
+				return
+			}
+			if err != nil {
+				handleUploadError(w, s.attachmentService.MaxSize(), err)
+				return
+			}
+			if part.FormName() != "file" {
+				part.Close()
+				continue
+			}
+
+			req := attachment.UploadRequest{OwnerType: owner, OwnerID: id, Filename: part.FileName()}
+			a, err := s.attachmentService.Upload(r.Context(), req, part)
+			part.Close()
+			if err != nil {
+				handleUploadError(w, s.attachmentService.MaxSize(), err)
+				return
+			}
+
+			writeJSON(w, http.StatusCreated, a)
+			return
+		}
+	}
+}
+
+// handleUploadError reports a request body cut off by MaxBytesReader as
+// a file over the size limit.
+func handleUploadError(w http.ResponseWriter, limit int64, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		err = attachment.ErrTooLarge(limit)
+	}
+	handleError(w, err)
+}
+
+func (s *Server) getAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	a, err := s.attachmentService.GetByID(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, a)
+}
+
+// downloadAttachment streams the file, honouring Range and conditional
+// request headers. Only images and PDFs are shown inline; the stored type
+// was detected from the content, and browsers are told not to guess.
+func (s *Server) downloadAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	a, content, err := s.attachmentService.Open(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	defer content.Close()
+
+	disposition := "attachment"
+	if a.Inline() {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", a.ContentType)
+	if value := mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename}); value != "" {
+		disposition = value
+	}
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+
+	http.ServeContent(w, r, a.Filename, a.CreatedAt, content)
+}
+
+// downloadThumbnail serves the thumbnail of an image attachment. Neither
+// the file nor its thumbnail ever changes, so browsers may cache it for a
+// year.
+func (s *Server) downloadThumbnail(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	a, content, err := s.attachmentService.OpenThumbnail(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", a.ThumbnailType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+
+	http.ServeContent(w, r, "", a.CreatedAt, content)
+}
+
+func (s *Server) deleteAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := s.attachmentService.Delete(r.Context(), id); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteOwnedAttachments removes the files of a deleted task or note.
+// Failures are only logged: the owner is already gone, and the purge at
+// the next startup retries.
+func (s *Server) deleteOwnedAttachments(ctx context.Context, owner attachment.OwnerType, ownerID int64) {
+	if err := s.attachmentService.DeleteOwned(ctx, owner, ownerID); err != nil {
+		log.Printf("delete attachments of %s %d: %v", owner, ownerID, err)
+	}
+}
@@ -0,0 +1,326 @@
+//go:build integration
+
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/attachment"
+	"github.com/stadtaev/lofam/backend/internal/note"
+)
+
+var pngFile = encodeImage(png.Encode, 4, 4)
+
+// encodeImage makes a small grey image in the format of encode.
+func encodeImage(encode func(io.Writer, image.Image) error, w, h int) []byte {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 128
+	}
+	var buf bytes.Buffer
+	encode(&buf, img)
+	return buf.Bytes()
+}
+
+// photoWithLocation is a JPEG whose EXIF data holds a GPS marker.
+func photoWithLocation(w, h int) []byte {
+	plain := encodeImage(func(w io.Writer, img image.Image) error { return jpeg.Encode(w, img, nil) }, w, h)
+	exif := []byte("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08\x00\x00GPSLatitude=52.52")
+	segment := append([]byte{0xFF, 0xE1, 0, byte(len(exif) + 2)}, exif...)
+	return append(append([]byte{0xFF, 0xD8}, segment...), plain[2:]...)
+}
+
+func uploadFile(t *testing.T, url, filename string, content []byte, out any) int {
+	t.Helper()
+	return uploadFileWithKey(t, url, "", filename, content, out)
+}
+
+// uploadFileWithKey is uploadFile with an Idempotency-Key header, if key is
+// set.
+func uploadFileWithKey(t *testing.T, url, key, filename string, content []byte, out any) int {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("comment", "ignored")
+	fw, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(content)
+	mw.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, url, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("decode upload response: %v", err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestTaskAttachments(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	tk := createTestTask(t, ts.URL, "Return permission slip")
+	uploadURL := fmt.Sprintf("%s/api/tasks/%d/attachments", ts.URL, tk.ID)
+
+	var a attachment.Attachment
+	if status := uploadFile(t, uploadURL, `C:\fakepath\slip.png`, pngFile, &a); status != http.StatusCreated {
+		t.Fatalf("upload status = %d", status)
+	}
+	if a.Filename != "slip.png" || a.ContentType != "image/png" || a.Size != int64(len(pngFile)) ||
+		a.OwnerType != attachment.OwnerTask || a.OwnerID != tk.ID {
+		t.Errorf("attachment = %+v", a)
+	}
+
+	var listed []attachment.Attachment
+	sendJSON(t, http.MethodGet, uploadURL, nil, &listed)
+	if len(listed) != 1 || listed[0].ID != a.ID || listed[0].ThumbnailType != "image/png" {
+		t.Errorf("attachments = %+v", listed)
+	}
+
+	contentURL := fmt.Sprintf("%s/api/attachments/%d/content", ts.URL, a.ID)
+
+	t.Run("download", func(t *testing.T) {
+		resp, err := http.Get(contentURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+
+		if resp.StatusCode != http.StatusOK || !bytes.Equal(body, pngFile) {
+			t.Errorf("status = %d, %d bytes", resp.StatusCode, len(body))
+		}
+		if got := resp.Header.Get("Content-Type"); got != "image/png" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if got := resp.Header.Get("Content-Disposition"); got != `inline; filename=slip.png` {
+			t.Errorf("Content-Disposition = %q", got)
+		}
+		if got := resp.Header.Get("X-Content-Type-Options"); got != "nosniff" {
+			t.Errorf("X-Content-Type-Options = %q", got)
+		}
+	})
+
+	t.Run("range", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, contentURL, nil)
+		req.Header.Set("Range", "bytes=1-3")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+
+		if resp.StatusCode != http.StatusPartialContent || string(body) != "PNG" {
+			t.Errorf("status = %d, body = %q", resp.StatusCode, body)
+		}
+		if got := resp.Header.Get("Content-Range"); got != fmt.Sprintf("bytes 1-3/%d", len(pngFile)) {
+			t.Errorf("Content-Range = %q", got)
+		}
+	})
+
+	t.Run("thumbnail", func(t *testing.T) {
+		if a.ThumbnailType != "image/png" {
+			t.Fatalf("thumbnail type = %q, want image/png", a.ThumbnailType)
+		}
+		resp, err := http.Get(fmt.Sprintf("%s/api/attachments/%d/thumbnail", ts.URL, a.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if _, err := png.Decode(resp.Body); resp.StatusCode != http.StatusOK || err != nil {
+			t.Errorf("status = %d, decode error = %v", resp.StatusCode, err)
+		}
+		if got := resp.Header.Get("Cache-Control"); got != "private, max-age=31536000, immutable" {
+			t.Errorf("Cache-Control = %q", got)
+		}
+	})
+
+	t.Run("photo", func(t *testing.T) {
+		var photo attachment.Attachment
+		if status := uploadFile(t, uploadURL, "IMG_0001.jpg", photoWithLocation(1200, 900), &photo); status != http.StatusCreated {
+			t.Fatalf("upload status = %d", status)
+		}
+
+		resp, err := http.Get(fmt.Sprintf("%s/api/attachments/%d/content", ts.URL, photo.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if bytes.Contains(stored, []byte("GPS")) || int64(len(stored)) != photo.Size {
+			t.Errorf("stored photo: %d bytes, size %d, location kept: %v",
+				len(stored), photo.Size, bytes.Contains(stored, []byte("GPS")))
+		}
+
+		resp, err = http.Get(fmt.Sprintf("%s/api/attachments/%d/thumbnail", ts.URL, photo.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		cfg, err := jpeg.DecodeConfig(resp.Body)
+		if err != nil {
+			t.Fatalf("thumbnail: %v", err)
+		}
+		if cfg.Width != attachment.ThumbnailSize || cfg.Height != 300 {
+			t.Errorf("thumbnail size = %dx%d", cfg.Width, cfg.Height)
+		}
+	})
+
+	t.Run("corrupt image", func(t *testing.T) {
+		status := uploadFile(t, uploadURL, "broken.png", pngFile[:40], nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("disguised html", func(t *testing.T) {
+		status := uploadFile(t, uploadURL, "photo.png", []byte("<html><script>alert(1)</script></html>"), nil)
+		if status != http.StatusUnsupportedMediaType {
+			t.Errorf("status = %d, want %d", status, http.StatusUnsupportedMediaType)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		status := uploadFile(t, uploadURL, "big.png", append(pngFile, make([]byte, testAttachmentMaxSize)...), nil)
+		if status != http.StatusRequestEntityTooLarge {
+			t.Errorf("status = %d, want %d", status, http.StatusRequestEntityTooLarge)
+		}
+	})
+
+	t.Run("too large with idempotency key", func(t *testing.T) {
+		status := uploadFileWithKey(t, uploadURL, "upload-big", "big.png", append(pngFile, make([]byte, testAttachmentMaxSize)...), nil)
+		if status != http.StatusRequestEntityTooLarge {
+			t.Errorf("status = %d, want %d", status, http.StatusRequestEntityTooLarge)
+		}
+	})
+
+	t.Run("retry with idempotency key", func(t *testing.T) {
+		var first, second attachment.Attachment
+		if status := uploadFileWithKey(t, uploadURL, "upload-1", "retry.png", pngFile, &first); status != http.StatusCreated {
+			t.Fatalf("status = %d, want %d", status, http.StatusCreated)
+		}
+		if status := uploadFileWithKey(t, uploadURL, "upload-1", "retry.png", pngFile, &second); status != http.StatusCreated {
+			t.Fatalf("replay status = %d, want %d", status, http.StatusCreated)
+		}
+		if second.ID != first.ID {
+			t.Errorf("replayed id = %d, want %d", second.ID, first.ID)
+		}
+	})
+
+	t.Run("idempotency key reused for another file", func(t *testing.T) {
+		if status := uploadFileWithKey(t, uploadURL, "upload-2", "a.png", pngFile, nil); status != http.StatusCreated {
+			t.Fatalf("status = %d, want %d", status, http.StatusCreated)
+		}
+		other := append(append([]byte{}, pngFile...), 0)
+		if status := uploadFileWithKey(t, uploadURL, "upload-2", "a.png", other, nil); status != http.StatusUnprocessableEntity {
+			t.Errorf("status = %d, want %d", status, http.StatusUnprocessableEntity)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		resp, err := http.Post(uploadURL, "application/json", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("unknown task", func(t *testing.T) {
+		status := uploadFile(t, ts.URL+"/api/tasks/99999/attachments", "slip.png", pngFile, nil)
+		if status != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("deleted with task", func(t *testing.T) {
+		if status := sendJSON(t, http.MethodDelete, fmt.Sprintf("%s/api/tasks/%d", ts.URL, tk.ID), nil, nil); status != http.StatusNoContent {
+			t.Fatalf("delete task status = %d", status)
+		}
+		status := sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/attachments/%d", ts.URL, a.ID), nil, nil)
+		if status != http.StatusNotFound {
+			t.Errorf("attachment status after deleting task = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+}
+
+func TestNoteAttachments(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var n note.Note
+	sendJSON(t, http.MethodPost, ts.URL+"/api/notes", map[string]any{"title": "Wi-Fi", "color": "yellow"}, &n)
+
+	var a attachment.Attachment
+	status := uploadFile(t, fmt.Sprintf("%s/api/notes/%d/attachments", ts.URL, n.ID), "router.txt", []byte("SSID: home\n"), &a)
+	if status != http.StatusCreated {
+		t.Fatalf("upload status = %d", status)
+	}
+	if a.ContentType != "text/plain; charset=utf-8" || a.ThumbnailType != "" {
+		t.Errorf("attachment = %+v", a)
+	}
+	status = sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/attachments/%d/thumbnail", ts.URL, a.ID), nil, nil)
+	if status != http.StatusNotFound {
+		t.Errorf("thumbnail status = %d, want %d", status, http.StatusNotFound)
+	}
+
+	resp, err := http.Get(fmt.Sprintf("%s/api/attachments/%d/content", ts.URL, a.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get("Content-Disposition"); got != "attachment; filename=router.txt" {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	if status := sendJSON(t, http.MethodDelete, fmt.Sprintf("%s/api/attachments/%d", ts.URL, a.ID), nil, nil); status != http.StatusNoContent {
+		t.Fatalf("delete status = %d", status)
+	}
+	status = sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/attachments/%d/content", ts.URL, a.ID), nil, nil)
+	if status != http.StatusNotFound {
+		t.Errorf("content status after delete = %d, want %d", status, http.StatusNotFound)
+	}
+
+	t.Run("deleted by batch", func(t *testing.T) {
+		var kept attachment.Attachment
+		uploadFile(t, fmt.Sprintf("%s/api/notes/%d/attachments", ts.URL, n.ID), "router.txt", []byte("SSID: home\n"), &kept)
+
+		status := sendJSON(t, http.MethodPost, ts.URL+"/api/batch", map[string]any{
+			"operations": []map[string]any{{"op": "delete", "entity": "note", "id": n.ID}},
+		}, nil)
+		if status != http.StatusOK {
+			t.Fatalf("batch status = %d", status)
+		}
+		status = sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/attachments/%d", ts.URL, kept.ID), nil, nil)
+		if status != http.StatusNotFound {
+			t.Errorf("attachment status after batch delete = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+}
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/stadtaev/lofam/backend/internal/attachment"
+	"github.com/stadtaev/lofam/backend/internal/note"
+	"github.com/stadtaev/lofam/backend/internal/shopping"
+	"github.com/stadtaev/lofam/backend/internal/task"
+	"github.com/stadtaev/lofam/backend/internal/wishlist"
+)
+
+const maxBatchOperations = 100
+
+type batchMode string
+
+const (
+	// batchModeAtomic applies every operation or none of them.
+	batchModeAtomic batchMode = "atomic"
+	// batchModeBestEffort applies the operations that succeed and reports
+	// the ones that fail.
+	batchModeBestEffort batchMode = "best_effort"
+)
+
+type batchRequest struct {
+	Mode       batchMode        `json:"mode"`
+	Operations []batchOperation `json:"operations"`
+}
+
+type batchOperation struct {
+	Op     string          `json:"op"`
+	Entity string          `json:"entity"`
+	ID     int64           `json:"id,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+type batchResult struct {
+	Index  int    `json:"index"`
+	Status int    `json:"status"`
+	Data   any    `json:"data,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type batchResponse struct {
+	Committed bool          `json:"committed"`
+	Results   []batchResult `json:"results"`
+}
+
+// errBatchAborted rolls back an atomic batch after a failed operation.
+var errBatchAborted = errors.New("batch aborted")
+
+func (s *Server) batch(w http.ResponseWriter, r *http.Request) {
+	var req batchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = batchModeAtomic
+	}
+	if req.Mode != batchModeAtomic && req.Mode != batchModeBestEffort {
+		writeError(w, http.StatusBadRequest, "invalid mode: must be atomic or best_effort")
+		return
+	}
+	if len(req.Operations) == 0 {
+		writeError(w, http.StatusBadRequest, "operations are required")
+		return
+	}
+	if len(req.Operations) > maxBatchOperations {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %d operations are allowed", maxBatchOperations))
+		return
+	}
+
+	results := make([]batchResult, 0, len(req.Operations))
+	failedStatus := 0
+
+	err := s.tx.WithinTx(r.Context(), func(ctx context.Context) error {
+		for i, op := range req.Operations {
+			var (
+				status int
+				data   any
+			)
+			err := s.tx.WithinSavepoint(ctx, func(ctx context.Context) error {
+				var err error
+				status, data, err = s.applyBatchOperation(ctx, op)
+				return err
+			})
+
+			result := batchResult{Index: i, Status: status, Data: data}
+			if err != nil {
+				result.Status, result.Error = errorStatus(err)
+				result.Data = nil
+			}
+			results = append(results, result)
+
+			if err != nil && req.Mode == batchModeAtomic {
+				failedStatus = result.Status
+				return errBatchAborted
+			}
+		}
+		return nil
+	})
+
+	if errors.Is(err, errBatchAborted) {
+		writeJSON(w, failedStatus, batchResponse{Committed: false, Results: results})
+		return
+	}
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	// Deleted tasks and notes may leave attachments behind.
+	for i, result := range results {
+		op := req.Operations[i]
+		if op.Op != "delete" || result.Error != "" {
+			continue
+		}
+		switch op.Entity {
+		case "task":
+			s.deleteOwnedAttachments(r.Context(), attachment.OwnerTask, op.ID)
+		case "note":
+			s.deleteOwnedAttachments(r.Context(), attachment.OwnerNote, op.ID)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, batchResponse{Committed: true, Results: results})
+}
+
+// applyBatchOperation runs a single operation through the owning service and
+// returns the status code and body the equivalent REST call would produce.
+func (s *Server) applyBatchOperation(ctx context.Context, op batchOperation) (int, any, error) {
+	switch op.Entity {
+	case "task":
+		switch op.Op {
+		case "create":
+			var req task.CreateRequest
+			if err := decodeBatchData(op, &req); err != nil {
+				return 0, nil, err
+			}
+			t, err := s.taskService.Create(ctx, req)
+			return http.StatusCreated, t, err
+		case "update":
+			var req task.UpdateRequest
+			if err := decodeBatchData(op, &req); err != nil {
+				return 0, nil, err
+			}
+			t, err := s.taskService.Update(ctx, op.ID, req)
+			return http.StatusOK, t, err
+		case "delete":
+			return http.StatusNoContent, nil, s.taskService.Delete(ctx, op.ID)
+		}
+	case "note":
+		switch op.Op {
+		case "create":
+			var req note.CreateRequest
+			if err := decodeBatchData(op, &req); err != nil {
+				return 0, nil, err
+			}
+			n, err := s.noteService.Create(ctx, req)
+			return http.StatusCreated, n, err
+		case "update":
+			var req note.UpdateRequest
+			if err := decodeBatchData(op, &req); err != nil {
+				return 0, nil, err
+			}
+			n, err := s.noteService.Update(ctx, op.ID, req)
+			return http.StatusOK, n, err
+		case "delete":
+			return http.StatusNoContent, nil, s.noteService.Delete(ctx, op.ID)
+		}
+	case "wishlist":
+		switch op.Op {
+		case "create":
+			var req wishlist.CreateRequest
+			if err := decodeBatchData(op, &req); err != nil {
+				return 0, nil, err
+			}
+			item, err := s.wishlistService.Create(ctx, req)
+			return http.StatusCreated, item, err
+		case "update":
+			var req wishlist.UpdateRequest
+			if err := decodeBatchData(op, &req); err != nil {
+				return 0, nil, err
+			}
+			item, err := s.wishlistService.Update(ctx, op.ID, req)
+			return http.StatusOK, item, err
+		case "delete":
+			return http.StatusNoContent, nil, s.wishlistService.Delete(ctx, op.ID)
+		}
+	case "shopping":
+		switch op.Op {
+		case "create":
+			var req shopping.CreateRequest
+			if err := decodeBatchData(op, &req); err != nil {
+				return 0, nil, err
+			}
+			item, err := s.shoppingService.Create(ctx, req)
+			return http.StatusCreated, item, err
+		case "update":
+			var req shopping.UpdateRequest
+			if err := decodeBatchData(op, &req); err != nil {
+				return 0, nil, err
+			}
+			item, err := s.shoppingService.Update(ctx, op.ID, req)
+			return http.StatusOK, item, err
+		case "delete":
+			return http.StatusNoContent, nil, s.shoppingService.Delete(ctx, op.ID)
+		}
+	default:
+		return 0, nil, batchError(fmt.Sprintf("unknown entity %q", op.Entity))
+	}
+
+	return 0, nil, batchError(fmt.Sprintf("unsupported op %q for entity %q", op.Op, op.Entity))
+}
+
+func decodeBatchData(op batchOperation, v any) error {
+	if len(op.Data) == 0 {
+		return batchError("data is required")
+	}
+	if err := json.Unmarshal(op.Data, v); err != nil {
+		return batchError("invalid data")
+	}
+	return nil
+}
+
+// batchOperationError reports a malformed operation in a batch, as opposed
+// to one the target service rejected.
+type batchOperationError struct {
+	Message string
+}
+
+func (e batchOperationError) Error() string {
+	return e.Message
+}
+
+func batchError(msg string) error {
+	return batchOperationError{Message: msg}
+}
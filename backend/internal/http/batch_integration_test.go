@@ -0,0 +1,119 @@
+//go:build integration
+
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/task"
+)
+
+type batchResponse struct {
+	Committed bool `json:"committed"`
+	Results   []struct {
+		Index  int    `json:"index"`
+		Status int    `json:"status"`
+		Error  string `json:"error"`
+	} `json:"results"`
+}
+
+func postBatch(t *testing.T, baseURL string, body map[string]any) (int, batchResponse) {
+	t.Helper()
+	b, _ := json.Marshal(body)
+	resp, err := http.Post(baseURL+"/api/batch", "application/json", bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+	var got batchResponse
+	json.NewDecoder(resp.Body).Decode(&got)
+	return resp.StatusCode, got
+}
+
+func listTasks(t *testing.T, baseURL string) []task.Task {
+	t.Helper()
+	resp, err := http.Get(baseURL + "/api/tasks")
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	defer resp.Body.Close()
+	var tasks []task.Task
+	json.NewDecoder(resp.Body).Decode(&tasks)
+	return tasks
+}
+
+func TestBatch(t *testing.T) {
+	t.Run("atomic rolls back on failure", func(t *testing.T) {
+		ts := setupTestServer(t)
+		defer ts.Close()
+
+		status, got := postBatch(t, ts.URL, map[string]any{
+			"operations": []map[string]any{
+				{"op": "create", "entity": "task", "data": map[string]any{"title": "Paint fence"}},
+				{"op": "delete", "entity": "shopping", "id": 99999},
+			},
+		})
+		if status != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", status, http.StatusNotFound)
+		}
+		if got.Committed {
+			t.Error("expected batch not to be committed")
+		}
+		if n := len(listTasks(t, ts.URL)); n != 0 {
+			t.Errorf("len(tasks) = %d, want 0", n)
+		}
+	})
+
+	t.Run("best effort keeps successful operations", func(t *testing.T) {
+		ts := setupTestServer(t)
+		defer ts.Close()
+
+		created := createTestTask(t, ts.URL, "Mow lawn")
+		status, got := postBatch(t, ts.URL, map[string]any{
+			"mode": "best_effort",
+			"operations": []map[string]any{
+				{"op": "update", "entity": "task", "id": created.ID, "data": map[string]any{"status": "done"}},
+				{"op": "create", "entity": "task", "data": map[string]any{}},
+				{"op": "create", "entity": "note", "data": map[string]any{"title": "Wi-Fi", "color": "yellow"}},
+			},
+		})
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want %d", status, http.StatusOK)
+		}
+		wantStatuses := []int{http.StatusOK, http.StatusBadRequest, http.StatusCreated}
+		for i, want := range wantStatuses {
+			if got.Results[i].Status != want {
+				t.Errorf("results[%d].status = %d, want %d", i, got.Results[i].Status, want)
+			}
+		}
+		tasks := listTasks(t, ts.URL)
+		if len(tasks) != 1 || tasks[0].Status != task.StatusDone {
+			t.Errorf("tasks = %+v, want one done task", tasks)
+		}
+	})
+
+	t.Run("malformed operations", func(t *testing.T) {
+		ts := setupTestServer(t)
+		defer ts.Close()
+
+		status, got := postBatch(t, ts.URL, map[string]any{
+			"mode": "best_effort",
+			"operations": []map[string]any{
+				{"op": "create", "entity": "spaceship", "data": map[string]any{}},
+				{"op": "archive", "entity": "task", "id": 1},
+				{"op": "create", "entity": "task"},
+			},
+		})
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want %d", status, http.StatusOK)
+		}
+		for i, r := range got.Results {
+			if r.Status != http.StatusBadRequest || r.Error == "" {
+				t.Errorf("results[%d] = %+v, want a 400 with a message", i, r)
+			}
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/stadtaev/lofam/backend/internal/comment"
+)
+
+func (s *Server) listComments(w http.ResponseWriter, r *http.Request) {
+	taskID, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	comments, err := s.commentService.List(r.Context(), taskID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, comments)
+}
+
+// createComment stores the comment and its mention notifications together.
+func (s *Server) createComment(w http.ResponseWriter, r *http.Request) {
+	taskID, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req comment.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var c *comment.Comment
+	err = s.tx.WithinTx(r.Context(), func(ctx context.Context) error {
+		var err error
+		c, err = s.commentService.Create(ctx, taskID, req)
+		return err
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, c)
+}
+
+func (s *Server) updateComment(w http.ResponseWriter, r *http.Request) {
+	taskID, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	id, err := parseIDParam(r, "commentId")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req comment.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var c *comment.Comment
+	err = s.tx.WithinTx(r.Context(), func(ctx context.Context) error {
+		var err error
+		c, err = s.commentService.Update(ctx, taskID, id, req)
+		return err
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) deleteComment(w http.ResponseWriter, r *http.Request) {
+	taskID, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	id, err := parseIDParam(r, "commentId")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := s.commentService.Delete(r.Context(), taskID, id); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
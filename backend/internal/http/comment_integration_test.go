@@ -0,0 +1,151 @@
+//go:build integration
+
+package http_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/comment"
+	"github.com/stadtaev/lofam/backend/internal/member"
+	"github.com/stadtaev/lofam/backend/internal/notification"
+	"github.com/stadtaev/lofam/backend/internal/task"
+)
+
+func TestTaskComments(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var mum, dad, kid member.Member
+	sendJSON(t, http.MethodPost, ts.URL+"/api/members", map[string]any{"name": "Mum"}, &mum)
+	sendJSON(t, http.MethodPost, ts.URL+"/api/members", map[string]any{"name": "Dad"}, &dad)
+	sendJSON(t, http.MethodPost, ts.URL+"/api/members", map[string]any{"name": "Kid"}, &kid)
+
+	tk := createTestTask(t, ts.URL, "Fix the bike")
+	commentsURL := fmt.Sprintf("%s/api/tasks/%d/comments", ts.URL, tk.ID)
+
+	var c comment.Comment
+	status := sendJSONAs(t, mum.ID, http.MethodPost, commentsURL, map[string]any{"body": "  @Dad the chain is off again, @mum will buy oil  "}, &c)
+	if status != http.StatusCreated {
+		t.Fatalf("create status = %d", status)
+	}
+	if c.AuthorID == nil || *c.AuthorID != mum.ID || c.Body != "@Dad the chain is off again, @mum will buy oil" {
+		t.Errorf("comment = %+v", c)
+	}
+	if len(c.Mentions) != 2 || c.Mentions[0] != dad.ID || c.Mentions[1] != mum.ID {
+		t.Errorf("mentions = %v", c.Mentions)
+	}
+
+	notificationsURL := ts.URL + "/api/notifications"
+
+	t.Run("mentioned member is notified", func(t *testing.T) {
+		var notifications []notification.Notification
+		sendJSONAs(t, dad.ID, http.MethodGet, notificationsURL+"?unread=true", nil, &notifications)
+		if len(notifications) != 1 {
+			t.Fatalf("notifications = %+v", notifications)
+		}
+		n := notifications[0]
+		if n.Kind != notification.KindMention || n.TaskID == nil || *n.TaskID != tk.ID ||
+			n.CommentID == nil || *n.CommentID != c.ID || n.Message != `Mum mentioned you on "Fix the bike"` {
+			t.Errorf("notification = %+v", n)
+		}
+
+		var own []notification.Notification
+		sendJSONAs(t, mum.ID, http.MethodGet, notificationsURL, nil, &own)
+		if len(own) != 0 {
+			t.Errorf("author notified of own mention: %+v", own)
+		}
+
+		if status := sendJSONAs(t, kid.ID, http.MethodPost, fmt.Sprintf("%s/%d/read", notificationsURL, n.ID), nil, nil); status != http.StatusNotFound {
+			t.Errorf("marking someone else's notification: status = %d, want %d", status, http.StatusNotFound)
+		}
+		var read notification.Notification
+		if status := sendJSONAs(t, dad.ID, http.MethodPost, fmt.Sprintf("%s/%d/read", notificationsURL, n.ID), nil, &read); status != http.StatusOK || read.ReadAt == nil {
+			t.Errorf("mark read: status = %d, notification = %+v", status, read)
+		}
+		sendJSONAs(t, dad.ID, http.MethodGet, notificationsURL+"?unread=true", nil, &notifications)
+		if len(notifications) != 0 {
+			t.Errorf("unread after marking read = %+v", notifications)
+		}
+	})
+
+	t.Run("edit notifies only new mentions", func(t *testing.T) {
+		var updated comment.Comment
+		url := fmt.Sprintf("%s/%d", commentsURL, c.ID)
+		status := sendJSONAs(t, mum.ID, http.MethodPut, url, map[string]any{"body": "@Dad @Kid the chain is off again"}, &updated)
+		if status != http.StatusOK || len(updated.Mentions) != 2 {
+			t.Fatalf("update: status = %d, comment = %+v", status, updated)
+		}
+
+		var dadUnread, kidUnread []notification.Notification
+		sendJSONAs(t, dad.ID, http.MethodGet, notificationsURL+"?unread=true", nil, &dadUnread)
+		sendJSONAs(t, kid.ID, http.MethodGet, notificationsURL+"?unread=true", nil, &kidUnread)
+		if len(dadUnread) != 0 || len(kidUnread) != 1 {
+			t.Errorf("unread: dad %d, kid %d; want 0 and 1", len(dadUnread), len(kidUnread))
+		}
+
+		if status := sendJSONAs(t, kid.ID, http.MethodPost, notificationsURL+"/read", nil, nil); status != http.StatusNoContent {
+			t.Errorf("mark all read status = %d", status)
+		}
+		sendJSONAs(t, kid.ID, http.MethodGet, notificationsURL+"?unread=true", nil, &kidUnread)
+		if len(kidUnread) != 0 {
+			t.Errorf("kid unread after marking all read = %+v", kidUnread)
+		}
+	})
+
+	t.Run("only the author may change a comment", func(t *testing.T) {
+		url := fmt.Sprintf("%s/%d", commentsURL, c.ID)
+		if status := sendJSONAs(t, dad.ID, http.MethodPut, url, map[string]any{"body": "hijacked"}, nil); status != http.StatusForbidden {
+			t.Errorf("update by another member: status = %d, want %d", status, http.StatusForbidden)
+		}
+		if status := sendJSONAs(t, dad.ID, http.MethodDelete, url, nil, nil); status != http.StatusForbidden {
+			t.Errorf("delete by another member: status = %d, want %d", status, http.StatusForbidden)
+		}
+		if status := sendJSON(t, http.MethodPost, commentsURL, map[string]any{"body": "anonymous"}, nil); status != http.StatusForbidden {
+			t.Errorf("anonymous comment: status = %d, want %d", status, http.StatusForbidden)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		if status := sendJSONAs(t, dad.ID, http.MethodPost, commentsURL, map[string]any{"body": "   "}, nil); status != http.StatusBadRequest {
+			t.Errorf("blank body: status = %d, want %d", status, http.StatusBadRequest)
+		}
+		if status := sendJSONAs(t, dad.ID, http.MethodPost, ts.URL+"/api/tasks/99999/comments", map[string]any{"body": "hi"}, nil); status != http.StatusNotFound {
+			t.Errorf("unknown task: status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("comment count", func(t *testing.T) {
+		sendJSONAs(t, dad.ID, http.MethodPost, commentsURL, map[string]any{"body": "On it"}, nil)
+
+		var comments []comment.Comment
+		sendJSON(t, http.MethodGet, commentsURL, nil, &comments)
+		if len(comments) != 2 || comments[0].ID != c.ID {
+			t.Errorf("comments = %+v", comments)
+		}
+
+		var tasks []task.Task
+		sendJSON(t, http.MethodGet, ts.URL+"/api/tasks", nil, &tasks)
+		if len(tasks) != 1 || tasks[0].CommentCount != 2 {
+			t.Errorf("tasks = %+v", tasks)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		url := fmt.Sprintf("%s/%d", commentsURL, c.ID)
+		if status := sendJSONAs(t, mum.ID, http.MethodDelete, url, nil, nil); status != http.StatusNoContent {
+			t.Fatalf("delete status = %d", status)
+		}
+		var notifications []notification.Notification
+		sendJSONAs(t, kid.ID, http.MethodGet, notificationsURL, nil, &notifications)
+		if len(notifications) != 0 {
+			t.Errorf("notifications for a deleted comment = %+v", notifications)
+		}
+
+		sendJSON(t, http.MethodDelete, fmt.Sprintf("%s/api/tasks/%d", ts.URL, tk.ID), nil, nil)
+		if status := sendJSON(t, http.MethodGet, commentsURL, nil, nil); status != http.StatusNotFound {
+			t.Errorf("comments of a deleted task: status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+//go:build integration
+
+package http_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/task"
+)
+
+func TestTaskDependencies(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	paint := createTestTask(t, ts.URL, "Buy paint")
+	fence := createTestTask(t, ts.URL, "Paint fence")
+	brushes := createTestTask(t, ts.URL, "Clean brushes")
+
+	blockersURL := func(id int64) string { return fmt.Sprintf("%s/api/tasks/%d/blockers", ts.URL, id) }
+	taskURL := func(id int64) string { return fmt.Sprintf("%s/api/tasks/%d", ts.URL, id) }
+
+	var got task.Task
+	if status := sendJSON(t, http.MethodPost, blockersURL(fence.ID), map[string]any{"blockerId": paint.ID}, &got); status != http.StatusOK {
+		t.Fatalf("add blocker status = %d", status)
+	}
+	if len(got.BlockedBy) != 1 || got.BlockedBy[0] != (task.Ref{ID: paint.ID, Title: "Buy paint", Status: task.StatusTodo}) {
+		t.Errorf("blockedBy = %+v", got.BlockedBy)
+	}
+	sendJSON(t, http.MethodPost, blockersURL(brushes.ID), map[string]any{"blockerId": fence.ID}, nil)
+
+	t.Run("blocks listed on the blocker", func(t *testing.T) {
+		var blocker task.Task
+		sendJSON(t, http.MethodGet, taskURL(paint.ID), nil, &blocker)
+		if len(blocker.Blocks) != 1 || blocker.Blocks[0].ID != fence.ID || len(blocker.BlockedBy) != 0 {
+			t.Errorf("blocker = %+v", blocker)
+		}
+
+		var tasks []task.Task
+		sendJSON(t, http.MethodGet, ts.URL+"/api/tasks", nil, &tasks)
+		for _, tk := range tasks {
+			if tk.ID == fence.ID && (len(tk.BlockedBy) != 1 || len(tk.Blocks) != 1) {
+				t.Errorf("listed fence task = %+v", tk)
+			}
+		}
+	})
+
+	t.Run("cycles are rejected", func(t *testing.T) {
+		for _, tt := range []struct {
+			name          string
+			id, blockerID int64
+		}{
+			{"direct", paint.ID, fence.ID},
+			{"transitive", paint.ID, brushes.ID},
+			{"self", paint.ID, paint.ID},
+		} {
+			status := sendJSON(t, http.MethodPost, blockersURL(tt.id), map[string]any{"blockerId": tt.blockerID}, nil)
+			if status != http.StatusBadRequest {
+				t.Errorf("%s: status = %d, want %d", tt.name, status, http.StatusBadRequest)
+			}
+		}
+		if status := sendJSON(t, http.MethodPost, blockersURL(paint.ID), map[string]any{"blockerId": 99999}, nil); status != http.StatusNotFound {
+			t.Errorf("unknown blocker: status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("open blockers stop progress", func(t *testing.T) {
+		if status := sendJSON(t, http.MethodPut, taskURL(fence.ID), map[string]any{"status": "in_progress"}, nil); status != http.StatusConflict {
+			t.Errorf("start blocked task: status = %d, want %d", status, http.StatusConflict)
+		}
+		if status := sendJSON(t, http.MethodPut, taskURL(fence.ID), map[string]any{"title": "Paint the fence"}, nil); status != http.StatusOK {
+			t.Errorf("rename blocked task: status = %d, want %d", status, http.StatusOK)
+		}
+
+		var forced task.Task
+		status := sendJSON(t, http.MethodPut, taskURL(brushes.ID), map[string]any{"status": "done", "force": true}, &forced)
+		if status != http.StatusOK || forced.Status != task.StatusDone {
+			t.Errorf("forced update: status = %d, task = %+v", status, forced)
+		}
+
+		sendJSON(t, http.MethodPut, taskURL(paint.ID), map[string]any{"status": "done"}, nil)
+		if status := sendJSON(t, http.MethodPut, taskURL(fence.ID), map[string]any{"status": "in_progress"}, nil); status != http.StatusOK {
+			t.Errorf("start unblocked task: status = %d, want %d", status, http.StatusOK)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		if status := sendJSON(t, http.MethodDelete, fmt.Sprintf("%s/%d", blockersURL(brushes.ID), fence.ID), nil, nil); status != http.StatusNoContent {
+			t.Fatalf("remove blocker status = %d", status)
+		}
+		sendJSON(t, http.MethodDelete, taskURL(paint.ID), nil, nil)
+
+		var remaining task.Task
+		sendJSON(t, http.MethodGet, taskURL(fence.ID), nil, &remaining)
+		if len(remaining.BlockedBy) != 0 || len(remaining.Blocks) != 0 {
+			t.Errorf("dependencies after removal = %+v, %+v", remaining.BlockedBy, remaining.Blocks)
+		}
+	})
+}
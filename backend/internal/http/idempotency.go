@@ -0,0 +1,166 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+
+	"github.com/stadtaev/lofam/backend/internal/idempotency"
+	"github.com/stadtaev/lofam/backend/internal/member"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotentBody caps the request bodies buffered for fingerprinting. It
+// is above every handler's own limit, such as maxImportBytes, so that those
+// still decide what is too large.
+const maxIdempotentBody = 8 << 20
+
+// idempotent makes POST requests carrying an Idempotency-Key header safe to
+// retry: the first response is stored and replayed for later requests from
+// the same member with the same key and body. Multipart uploads are spooled
+// to a temporary file and fingerprinted by their parts, since clients pick
+// a new boundary on every attempt.
+func (s *Server) idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var body []byte
+		if boundary, ok := multipartBoundary(r); ok {
+			spool, digest, err := s.spoolMultipart(w, r.Body, boundary)
+			if spool != nil {
+				defer os.Remove(spool.Name())
+				defer spool.Close()
+			}
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				handleUploadError(w, s.attachmentService.MaxSize(), err)
+				return
+			}
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+			r.Body, body = spool, digest
+		} else {
+			var err error
+			body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+				return
+			}
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		memberID, _ := member.IDFromContext(r.Context())
+		hash := idempotency.HashRequest(r.Method, r.URL.Path, memberID, body)
+		rec, err := s.idempotencyService.Begin(r.Context(), key, hash)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		if rec != nil {
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(rec.StatusCode)
+			w.Write(rec.Body)
+			return
+		}
+
+		rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			var rec *idempotency.Record
+			if rw.wroteHeader {
+				rec = &idempotency.Record{RequestHash: hash, StatusCode: rw.status, Body: rw.body.Bytes()}
+			}
+			if err := s.idempotencyService.Finish(r.Context(), key, rec); err != nil {
+				log.Printf("failed to store idempotent response: %v", err)
+			}
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+func multipartBoundary(r *http.Request) (string, bool) {
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return params["boundary"], mediaType == "multipart/form-data" && params["boundary"] != ""
+}
+
+// spoolMultipart copies a multipart body to a temporary file, up to the
+// upload limit, and returns the file rewound for the handler along with a
+// digest of each part's name, filename and content.
+func (s *Server) spoolMultipart(w http.ResponseWriter, body io.Reader, boundary string) (*os.File, []byte, error) {
+	spool, err := os.CreateTemp("", "lofam-upload-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	limited := http.MaxBytesReader(w, io.NopCloser(body), s.attachmentService.MaxSize()+multipartOverhead)
+	if _, err := io.Copy(spool, limited); err != nil {
+		return spool, nil, err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return spool, nil, err
+	}
+
+	h := sha256.New()
+	mr := multipart.NewReader(spool, boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return spool, nil, err
+		}
+		fmt.Fprintf(h, "%s\x00%s\x00", part.FormName(), part.FileName())
+		n, err := io.Copy(h, part)
+		part.Close()
+		if err != nil {
+			return spool, nil, err
+		}
+		fmt.Fprintf(h, "\x00%d\x00", n)
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return spool, nil, err
+	}
+	return spool, h.Sum(nil), nil
+}
+
+// recordingWriter passes the response through while keeping a copy of it.
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
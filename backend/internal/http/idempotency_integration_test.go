@@ -0,0 +1,110 @@
+//go:build integration
+
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/shopping"
+)
+
+func postWithKey(t *testing.T, url, key string, body map[string]any) (*http.Response, []byte) {
+	t.Helper()
+	b, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp, data
+}
+
+func TestIdempotentCreate(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	first, firstBody := postWithKey(t, ts.URL+"/api/shopping", "key-1", map[string]any{"title": "Milk"})
+	if first.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", first.StatusCode, http.StatusCreated)
+	}
+
+	t.Run("retry replays response", func(t *testing.T) {
+		resp, body := postWithKey(t, ts.URL+"/api/shopping", "key-1", map[string]any{"title": "Milk"})
+		if resp.StatusCode != http.StatusCreated {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusCreated)
+		}
+		if resp.Header.Get("Idempotent-Replayed") != "true" {
+			t.Error("expected Idempotent-Replayed header")
+		}
+		if !bytes.Equal(body, firstBody) {
+			t.Errorf("body = %s, want %s", body, firstBody)
+		}
+
+		listResp, err := http.Get(ts.URL + "/api/shopping")
+		if err != nil {
+			t.Fatalf("failed to list items: %v", err)
+		}
+		defer listResp.Body.Close()
+		var items []shopping.Item
+		json.NewDecoder(listResp.Body).Decode(&items)
+		if len(items) != 1 {
+			t.Errorf("len(items) = %d, want 1", len(items))
+		}
+	})
+
+	t.Run("reuse with different body", func(t *testing.T) {
+		resp, _ := postWithKey(t, ts.URL+"/api/shopping", "key-1", map[string]any{"title": "Bread"})
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnprocessableEntity)
+		}
+	})
+
+	t.Run("errors are replayed too", func(t *testing.T) {
+		resp, _ := postWithKey(t, ts.URL+"/api/tasks", "key-2", map[string]any{})
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+		}
+		resp, _ = postWithKey(t, ts.URL+"/api/tasks", "key-2", map[string]any{})
+		if resp.Header.Get("Idempotent-Replayed") != "true" {
+			t.Error("expected Idempotent-Replayed header")
+		}
+	})
+	t.Run("key reused by another member", func(t *testing.T) {
+		b, _ := json.Marshal(map[string]any{"title": "Milk"})
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/api/shopping", bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "key-1")
+		req.Header.Set("X-Member-ID", "7")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnprocessableEntity)
+		}
+	})
+	t.Run("large recipe import", func(t *testing.T) {
+		page := `<html><head><script type="application/ld+json">{"@context":"https://schema.org","@type":"Recipe","name":"Soup","recipeIngredient":["1 l water"]}</script></head><body>` +
+			strings.Repeat("once upon a time ", 150_000) + `</body></html>`
+		resp, _ := postWithKey(t, ts.URL+"/api/recipes/import", "key-4", map[string]any{"html": page})
+		if resp.StatusCode != http.StatusCreated {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusCreated)
+		}
+	})
+	t.Run("oversized body", func(t *testing.T) {
+		resp, _ := postWithKey(t, ts.URL+"/api/shopping", "key-3", map[string]any{"title": strings.Repeat("a", 9<<20)})
+		if resp.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusRequestEntityTooLarge)
+		}
+	})
+}
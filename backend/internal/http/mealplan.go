@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/stadtaev/lofam/backend/internal/mealplan"
+)
+
+func (s *Server) listMealPlan(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	entries, err := s.mealplanService.List(r.Context(), query.Get("from"), query.Get("to"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (s *Server) createMealPlanEntry(w http.ResponseWriter, r *http.Request) {
+	var req mealplan.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	e, err := s.mealplanService.Create(r.Context(), req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, e)
+}
+
+func (s *Server) getMealPlanEntry(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	e, err := s.mealplanService.GetByID(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, e)
+}
+
+func (s *Server) updateMealPlanEntry(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req mealplan.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	e, err := s.mealplanService.Update(r.Context(), id, req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, e)
+}
+
+func (s *Server) deleteMealPlanEntry(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := s.mealplanService.Delete(r.Context(), id); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) generateMealPlanShopping(w http.ResponseWriter, r *http.Request) {
+	var req mealplan.ShoppingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var result *mealplan.ShoppingResult
+	err := s.tx.WithinTx(r.Context(), func(ctx context.Context) error {
+		var err error
+		result, err = s.mealplanService.GenerateShopping(ctx, req)
+		return err
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
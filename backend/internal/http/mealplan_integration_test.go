@@ -0,0 +1,165 @@
+//go:build integration
+
+package http_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/mealplan"
+	"github.com/stadtaev/lofam/backend/internal/recipe"
+	"github.com/stadtaev/lofam/backend/internal/shopping"
+)
+
+func TestRecipes(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var pancakes recipe.Recipe
+	status := sendJSON(t, http.MethodPost, ts.URL+"/api/recipes", map[string]any{
+		"name": "Pancakes", "servings": 2,
+		"ingredients": []map[string]any{
+			{"quantity": 2, "unit": "cups", "name": "Flour"},
+			{"quantity": 50, "unit": "Grams", "name": "Butter"},
+			{"name": "Salt", "note": "a pinch"},
+		},
+		"steps": []string{"Mix", "Fry"},
+	}, &pancakes)
+	if status != http.StatusCreated {
+		t.Fatalf("create status = %d", status)
+	}
+	if pancakes.Ingredients[0].Unit != "cup" || pancakes.Ingredients[1].Unit != "g" {
+		t.Errorf("ingredients = %+v, want normalised units", pancakes.Ingredients)
+	}
+	recipeURL := fmt.Sprintf("%s/api/recipes/%d", ts.URL, pancakes.ID)
+
+	t.Run("validation", func(t *testing.T) {
+		for name, body := range map[string]map[string]any{
+			"no name":             {"servings": 2},
+			"negative servings":   {"name": "x", "servings": -1},
+			"unnamed ingredient":  {"name": "x", "ingredients": []map[string]any{{"quantity": 1}}},
+			"negative ingredient": {"name": "x", "ingredients": []map[string]any{{"name": "Egg", "quantity": -1}}},
+		} {
+			if status := sendJSON(t, http.MethodPost, ts.URL+"/api/recipes", body, nil); status != http.StatusBadRequest {
+				t.Errorf("%s: status = %d, want %d", name, status, http.StatusBadRequest)
+			}
+		}
+		if status := sendJSON(t, http.MethodGet, ts.URL+"/api/recipes/99999", nil, nil); status != http.StatusNotFound {
+			t.Errorf("unknown recipe: status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("partial update", func(t *testing.T) {
+		var updated recipe.Recipe
+		if status := sendJSON(t, http.MethodPut, recipeURL, map[string]any{"description": "Sunday breakfast"}, &updated); status != http.StatusOK {
+			t.Fatalf("status = %d", status)
+		}
+		if updated.Description != "Sunday breakfast" || updated.Servings != 2 || len(updated.Ingredients) != 3 || len(updated.Steps) != 2 {
+			t.Errorf("updated = %+v, want only the description changed", updated)
+		}
+
+		if status := sendJSON(t, http.MethodPut, recipeURL, map[string]any{"servings": 0}, nil); status != http.StatusBadRequest {
+			t.Errorf("zero servings: status = %d, want %d", status, http.StatusBadRequest)
+		}
+		if status := sendJSON(t, http.MethodPut, recipeURL, map[string]any{"steps": []string{}}, &updated); status != http.StatusOK || len(updated.Steps) != 0 {
+			t.Errorf("clearing steps: status = %d, steps = %v", status, updated.Steps)
+		}
+	})
+}
+
+func TestMealPlanShopping(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var pancakes recipe.Recipe
+	sendJSON(t, http.MethodPost, ts.URL+"/api/recipes", map[string]any{
+		"name": "Pancakes", "servings": 2,
+		"ingredients": []map[string]any{
+			{"quantity": 2, "unit": "cups", "name": "Flour"},
+			{"quantity": 50, "unit": "g", "name": "Butter"},
+		},
+	}, &pancakes)
+	var omelette recipe.Recipe
+	sendJSON(t, http.MethodPost, ts.URL+"/api/recipes", map[string]any{
+		"name": "Omelette", "servings": 1,
+		"ingredients": []map[string]any{
+			{"quantity": 3, "name": "Eggs"},
+			{"quantity": 10, "unit": "gram", "name": "butter"},
+			{"name": "Pepper", "note": "to taste"},
+		},
+	}, &omelette)
+
+	plan := func(body map[string]any) mealplan.Entry {
+		t.Helper()
+		var e mealplan.Entry
+		if status := sendJSON(t, http.MethodPost, ts.URL+"/api/mealplan", body, &e); status != http.StatusCreated {
+			t.Fatalf("plan %v: status = %d", body, status)
+		}
+		return e
+	}
+	sunday := plan(map[string]any{"date": "2026-05-03", "meal": "breakfast", "recipeId": pancakes.ID, "servings": 4})
+	monday := plan(map[string]any{"date": "2026-05-04", "recipeId": omelette.ID})
+	plan(map[string]any{"date": "2026-05-11", "recipeId": pancakes.ID})
+	if monday.Meal != mealplan.MealDinner || monday.Servings != 1 || monday.RecipeName != "Omelette" {
+		t.Errorf("monday = %+v, want dinner with the recipe's servings", monday)
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		for name, body := range map[string]map[string]any{
+			"bad date":     {"date": "03/05/2026", "recipeId": pancakes.ID},
+			"no recipe":    {"date": "2026-05-03"},
+			"unknown meal": {"date": "2026-05-03", "meal": "brunch", "recipeId": pancakes.ID},
+		} {
+			if status := sendJSON(t, http.MethodPost, ts.URL+"/api/mealplan", body, nil); status != http.StatusBadRequest {
+				t.Errorf("%s: status = %d, want %d", name, status, http.StatusBadRequest)
+			}
+		}
+		status := sendJSON(t, http.MethodPost, ts.URL+"/api/mealplan", map[string]any{"date": "2026-05-03", "recipeId": 99999}, nil)
+		if status != http.StatusNotFound {
+			t.Errorf("unknown recipe: status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("list range", func(t *testing.T) {
+		var entries []mealplan.Entry
+		sendJSON(t, http.MethodGet, ts.URL+"/api/mealplan?from=2026-05-01&to=2026-05-07", nil, &entries)
+		if len(entries) != 2 || entries[0].ID != sunday.ID || entries[1].ID != monday.ID {
+			t.Errorf("entries = %+v, want Sunday and Monday", entries)
+		}
+	})
+
+	t.Run("generate shopping", func(t *testing.T) {
+		// Already on the list, with the unit spelled out.
+		var butter shopping.Item
+		sendJSON(t, http.MethodPost, ts.URL+"/api/shopping", map[string]any{"title": "Butter", "quantity": 100, "unit": "grams"}, &butter)
+
+		var result mealplan.ShoppingResult
+		status := sendJSON(t, http.MethodPost, ts.URL+"/api/mealplan/shopping", map[string]any{"from": "2026-05-01", "to": "2026-05-07"}, &result)
+		if status != http.StatusOK {
+			t.Fatalf("status = %d", status)
+		}
+		if result.Created != 2 || result.Merged != 1 {
+			t.Errorf("created %d, merged %d, want 2 and 1", result.Created, result.Merged)
+		}
+
+		var items []shopping.Item
+		sendJSON(t, http.MethodGet, ts.URL+"/api/shopping", nil, &items)
+		got := make(map[string]string)
+		for _, item := range items {
+			got[item.Title] = fmt.Sprintf("%g %s", item.Quantity, item.Unit)
+		}
+		// Pancakes are doubled to four servings; the omelette's butter joins
+		// the pancakes' and the item already on the list. Pepper to taste is
+		// not bought.
+		want := map[string]string{"Flour": "4 cup", "Butter": "210 grams", "Eggs": "3 "}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("items = %v, want %v", got, want)
+		}
+
+		status = sendJSON(t, http.MethodPost, ts.URL+"/api/mealplan/shopping", map[string]any{"from": "2026-05-07", "to": "2026-05-01"}, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("inverted range: status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/stadtaev/lofam/backend/internal/member"
+)
+
+const memberIDHeader = "X-Member-ID"
+
+// identifyMember records the household member named by the X-Member-ID
+// header in the request context. Requests without the header are served
+// anonymously.
+func (s *Server) identifyMember(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		value := r.Header.Get(memberIDHeader)
+		if value == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			handleError(w, member.ErrValidation("invalid "+memberIDHeader+" header"))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(member.WithID(r.Context(), id)))
+	})
+}
+
+func (s *Server) listMembers(w http.ResponseWriter, r *http.Request) {
+	members, err := s.memberService.List(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, members)
+}
+
+func (s *Server) createMember(w http.ResponseWriter, r *http.Request) {
+	var req member.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	m, err := s.memberService.Create(r.Context(), req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, m)
+}
+
+func (s *Server) getMember(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	m, err := s.memberService.GetByID(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) updateMember(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req member.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	m, err := s.memberService.Update(r.Context(), id, req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) deleteMember(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := s.memberService.Delete(r.Context(), id); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,195 @@
+//go:build integration
+
+package http_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/project"
+	"github.com/stadtaev/lofam/backend/internal/rank"
+	"github.com/stadtaev/lofam/backend/internal/task"
+)
+
+// listedTitles returns the titles of the tasks at url in list order.
+func listedTitles(t *testing.T, url string) []string {
+	t.Helper()
+	var tasks []task.Task
+	sendJSON(t, http.MethodGet, url, nil, &tasks)
+	titles := make([]string, len(tasks))
+	for i, tk := range tasks {
+		titles[i] = tk.Title
+	}
+	return titles
+}
+
+func TestMoveTask(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	c := createTestTask(t, ts.URL, "C")
+	b := createTestTask(t, ts.URL, "B")
+	a := createTestTask(t, ts.URL, "A")
+	if got := fmt.Sprint(listedTitles(t, ts.URL+"/api/tasks")); got != "[A B C]" {
+		t.Fatalf("new tasks listed as %s, want newest first", got)
+	}
+
+	moveURL := func(id int64) string { return fmt.Sprintf("%s/api/tasks/%d/move", ts.URL, id) }
+
+	tests := []struct {
+		name string
+		id   int64
+		body map[string]any
+		want string
+	}{
+		{"between", a.ID, map[string]any{"afterId": b.ID, "beforeId": c.ID}, "[B A C]"},
+		{"after only", b.ID, map[string]any{"afterId": c.ID}, "[A C B]"},
+		{"before only", b.ID, map[string]any{"beforeId": c.ID}, "[A B C]"},
+		{"to the top", c.ID, map[string]any{}, "[C A B]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := sendJSON(t, http.MethodPost, moveURL(tt.id), tt.body, nil); status != http.StatusOK {
+				t.Fatalf("move status = %d", status)
+			}
+			if got := fmt.Sprint(listedTitles(t, ts.URL+"/api/tasks")); got != tt.want {
+				t.Errorf("order = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		if status := sendJSON(t, http.MethodPost, moveURL(a.ID), map[string]any{"afterId": a.ID}, nil); status != http.StatusBadRequest {
+			t.Errorf("next to itself: status = %d, want %d", status, http.StatusBadRequest)
+		}
+		if status := sendJSON(t, http.MethodPost, moveURL(a.ID), map[string]any{"afterId": b.ID, "beforeId": c.ID}, nil); status != http.StatusBadRequest {
+			t.Errorf("neighbours out of order: status = %d, want %d", status, http.StatusBadRequest)
+		}
+		if status := sendJSON(t, http.MethodPost, moveURL(a.ID), map[string]any{"afterId": 99999}, nil); status != http.StatusNotFound {
+			t.Errorf("unknown neighbour: status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("rebalancing", func(t *testing.T) {
+		// Squeezing tasks in right after C over and over shrinks the gap
+		// until ranks have to be spread out again.
+		for i := 0; i < 200; i++ {
+			id := a.ID
+			if i%2 == 1 {
+				id = b.ID
+			}
+			if status := sendJSON(t, http.MethodPost, moveURL(id), map[string]any{"afterId": c.ID}, nil); status != http.StatusOK {
+				t.Fatalf("move %d: status = %d", i, status)
+			}
+		}
+
+		var tasks []task.Task
+		sendJSON(t, http.MethodGet, ts.URL+"/api/tasks", nil, &tasks)
+		for _, tk := range tasks {
+			if len(tk.Rank) > rank.MaxLength {
+				t.Errorf("rank %q of %s is longer than %d", tk.Rank, tk.Title, rank.MaxLength)
+			}
+		}
+		if got := fmt.Sprint(listedTitles(t, ts.URL+"/api/tasks")); got != "[C B A]" {
+			t.Errorf("order = %s, want [C B A]", got)
+		}
+	})
+}
+
+func TestMoveTaskStaysInLane(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	x := createTestTask(t, ts.URL, "X")
+	y := createTestTask(t, ts.URL, "Y")
+	z := createTestTask(t, ts.URL, "Z")
+	var done task.Task
+	sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/tasks/%d", ts.URL, z.ID), map[string]any{"status": "done"}, &done)
+
+	moveURL := func(id int64) string { return fmt.Sprintf("%s/api/tasks/%d/move", ts.URL, id) }
+
+	if status := sendJSON(t, http.MethodPost, moveURL(x.ID), map[string]any{"afterId": z.ID}, nil); status != http.StatusBadRequest {
+		t.Errorf("neighbour with another status: status = %d, want %d", status, http.StatusBadRequest)
+	}
+
+	// Rebalancing the to-do tasks leaves the done ones alone.
+	for i := 0; i < 200; i++ {
+		id, other := x.ID, y.ID
+		if i%2 == 1 {
+			id, other = y.ID, x.ID
+		}
+		if status := sendJSON(t, http.MethodPost, moveURL(id), map[string]any{"beforeId": other}, nil); status != http.StatusOK {
+			t.Fatalf("move %d: status = %d", i, status)
+		}
+	}
+	var got task.Task
+	sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/tasks/%d", ts.URL, z.ID), nil, &got)
+	if got.Rank != done.Rank {
+		t.Errorf("done task rank = %q, want %q unchanged", got.Rank, done.Rank)
+	}
+}
+
+func TestMoveTaskBetweenTasksThatChangedStatus(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	// Each task is the only to-do task when it is created, so without
+	// re-ranking A and B would arrive in done with the same rank.
+	var done []task.Task
+	for _, title := range []string{"A", "B"} {
+		created := createTestTask(t, ts.URL, title)
+		var moved task.Task
+		sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/tasks/%d", ts.URL, created.ID), map[string]any{"status": "done"}, &moved)
+		done = append(done, moved)
+	}
+	if done[0].Rank == done[1].Rank {
+		t.Fatalf("done tasks share rank %q", done[0].Rank)
+	}
+	first, second := done[0], done[1]
+	if second.Rank < first.Rank {
+		first, second = second, first
+	}
+
+	c := createTestTask(t, ts.URL, "C")
+	status := sendJSON(t, http.MethodPost, fmt.Sprintf("%s/api/tasks/%d/move", ts.URL, c.ID),
+		map[string]any{"status": "done", "afterId": first.ID, "beforeId": second.ID}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("move status = %d", status)
+	}
+	want := fmt.Sprint([]string{first.Title, "C", second.Title})
+	if got := fmt.Sprint(listedTitles(t, ts.URL+"/api/tasks")); got != want {
+		t.Errorf("order = %s, want %s", got, want)
+	}
+}
+
+func TestMoveTaskOnBoard(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var p project.Project
+	sendJSON(t, http.MethodPost, ts.URL+"/api/projects", map[string]any{"name": "Holiday"}, &p)
+	todo, doing := p.Columns[0], p.Columns[1]
+
+	var pack, book, visa task.Task
+	sendJSON(t, http.MethodPost, ts.URL+"/api/tasks", map[string]any{"title": "Pack", "projectId": p.ID}, &pack)
+	sendJSON(t, http.MethodPost, ts.URL+"/api/tasks", map[string]any{"title": "Book", "projectId": p.ID, "columnId": doing.ID}, &book)
+	sendJSON(t, http.MethodPost, ts.URL+"/api/tasks", map[string]any{"title": "Visa", "projectId": p.ID, "columnId": doing.ID}, &visa)
+
+	var moved task.Task
+	status := sendJSON(t, http.MethodPost, fmt.Sprintf("%s/api/tasks/%d/move", ts.URL, pack.ID),
+		map[string]any{"columnId": doing.ID, "afterId": visa.ID, "beforeId": book.ID}, &moved)
+	if status != http.StatusOK || moved.Status != task.StatusInProgress || *moved.ColumnID != doing.ID {
+		t.Fatalf("move: status = %d, task = %+v", status, moved)
+	}
+
+	var board project.Board
+	sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/projects/%d/board", ts.URL, p.ID), nil, &board)
+	var titles []string
+	for _, tk := range board.Columns[1].Tasks {
+		titles = append(titles, tk.Title)
+	}
+	if len(board.Columns[0].Tasks) != 0 || fmt.Sprint(titles) != "[Visa Pack Book]" {
+		t.Errorf("board: %s has %d tasks, %s has %v", todo.Name, len(board.Columns[0].Tasks), doing.Name, titles)
+	}
+}
@@ -3,12 +3,22 @@ package http
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
+	"github.com/stadtaev/lofam/backend/internal/attachment"
 	"github.com/stadtaev/lofam/backend/internal/note"
 )
 
+// listNotes returns active notes unless ?archived=true asks for the archive
+// or ?archived=all for everything. ?tag= narrows the result to one tag.
 func (s *Server) listNotes(w http.ResponseWriter, r *http.Request) {
-	notes, err := s.noteService.List(r.Context())
+	filter, err := parseNoteFilter(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	notes, err := s.noteService.List(r.Context(), filter)
 	if err != nil {
 		handleError(w, err)
 		return
@@ -82,6 +92,72 @@ func (s *Server) deleteNote(w http.ResponseWriter, r *http.Request) {
 		handleError(w, err)
 		return
 	}
+	s.deleteOwnedAttachments(r.Context(), attachment.OwnerNote, id)
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// toggleNoteTask ticks or unticks the task-list checkbox numbered by the
+// data-task attribute of the rendered HTML.
+func (s *Server) toggleNoteTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	index, err := parseIDParam(r, "index")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	n, err := s.noteService.ToggleTask(r.Context(), id, int(index))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, n)
+}
+
+func parseNoteFilter(r *http.Request) (note.Filter, error) {
+	filter := note.Filter{Tag: r.URL.Query().Get("tag")}
+
+	switch value := r.URL.Query().Get("archived"); value {
+	case "":
+		archived := false
+		filter.Archived = &archived
+	case "all":
+	default:
+		archived, err := strconv.ParseBool(value)
+		if err != nil {
+			return note.Filter{}, note.ErrValidation("archived must be true, false, or all")
+		}
+		filter.Archived = &archived
+	}
+
+	return filter, nil
+}
+
+// reorderNotes stores a new manual order and returns the active notes in it.
+func (s *Server) reorderNotes(w http.ResponseWriter, r *http.Request) {
+	var req note.ReorderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := s.noteService.Reorder(r.Context(), req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	archived := false
+	notes, err := s.noteService.List(r.Context(), note.Filter{Archived: &archived})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, notes)
+}
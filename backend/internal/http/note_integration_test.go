@@ -0,0 +1,152 @@
+//go:build integration
+
+package http_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/note"
+)
+
+func listNoteTitles(t *testing.T, url string) []string {
+	t.Helper()
+	var notes []note.Note
+	if status := sendJSON(t, http.MethodGet, url, nil, &notes); status != http.StatusOK {
+		t.Fatalf("list notes status = %d", status)
+	}
+	titles := make([]string, len(notes))
+	for i, n := range notes {
+		titles[i] = n.Title
+	}
+	return titles
+}
+
+func TestNoteOrdering(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	notes := map[string]note.Note{}
+	for _, title := range []string{"Groceries", "Wi-Fi", "Recipes", "Old"} {
+		var n note.Note
+		sendJSON(t, http.MethodPost, ts.URL+"/api/notes", map[string]any{"title": title, "color": "yellow"}, &n)
+		notes[title] = n
+	}
+
+	if got := fmt.Sprint(listNoteTitles(t, ts.URL+"/api/notes")); got != "[Old Recipes Wi-Fi Groceries]" {
+		t.Errorf("default order = %s, want newest first", got)
+	}
+
+	sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/notes/%d", ts.URL, notes["Wi-Fi"].ID),
+		map[string]any{"title": "Wi-Fi", "color": "yellow", "pinned": true}, nil)
+	sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/notes/%d", ts.URL, notes["Old"].ID),
+		map[string]any{"title": "Old", "color": "yellow", "archived": true}, nil)
+
+	if got := fmt.Sprint(listNoteTitles(t, ts.URL+"/api/notes")); got != "[Wi-Fi Recipes Groceries]" {
+		t.Errorf("order = %s, want pinned first and archived hidden", got)
+	}
+	if got := fmt.Sprint(listNoteTitles(t, ts.URL+"/api/notes?archived=true")); got != "[Old]" {
+		t.Errorf("archive = %s", got)
+	}
+	if got := len(listNoteTitles(t, ts.URL+"/api/notes?archived=all")); got != 4 {
+		t.Errorf("all notes = %d, want 4", got)
+	}
+
+	var reordered []note.Note
+	status := sendJSON(t, http.MethodPut, ts.URL+"/api/notes/order",
+		map[string]any{"ids": []int64{notes["Groceries"].ID}}, &reordered)
+	if status != http.StatusOK {
+		t.Fatalf("reorder status = %d", status)
+	}
+	if len(reordered) != 3 || reordered[1].Title != "Groceries" {
+		t.Errorf("reorder response = %+v", reordered)
+	}
+	if got := fmt.Sprint(listNoteTitles(t, ts.URL+"/api/notes")); got != "[Wi-Fi Groceries Recipes]" {
+		t.Errorf("order after reorder = %s", got)
+	}
+
+	t.Run("unknown note", func(t *testing.T) {
+		status := sendJSON(t, http.MethodPut, ts.URL+"/api/notes/order", map[string]any{"ids": []int64{99999}}, nil)
+		if status != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("duplicate ids", func(t *testing.T) {
+		id := notes["Recipes"].ID
+		status := sendJSON(t, http.MethodPut, ts.URL+"/api/notes/order", map[string]any{"ids": []int64{id, id}}, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		status := sendJSON(t, http.MethodGet, ts.URL+"/api/notes?archived=maybe", nil, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestNoteMarkdown(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var n note.Note
+	status := sendJSON(t, http.MethodPost, ts.URL+"/api/notes", map[string]any{
+		"title":   "Packing",
+		"content": "**Bags**\n\n- [ ] passport\n- [x] charger\n\n<script>alert(1)</script>",
+		"color":   "yellow",
+	}, &n)
+	if status != http.StatusCreated {
+		t.Fatalf("create status = %d", status)
+	}
+	want := "<p><strong>Bags</strong></p>\n<ul>\n" +
+		"<li class=\"task-list-item\"><input type=\"checkbox\" data-task=\"0\" /> passport</li>\n" +
+		"<li class=\"task-list-item\"><input type=\"checkbox\" data-task=\"1\" checked /> charger</li>\n" +
+		"</ul>\n<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"
+	if n.HTML != want {
+		t.Errorf("html = %q, want %q", n.HTML, want)
+	}
+
+	var toggled note.Note
+	status = sendJSON(t, http.MethodPost, fmt.Sprintf("%s/api/notes/%d/tasks/0/toggle", ts.URL, n.ID), nil, &toggled)
+	if status != http.StatusOK {
+		t.Fatalf("toggle status = %d", status)
+	}
+	if toggled.Content != "**Bags**\n\n- [x] passport\n- [x] charger\n\n<script>alert(1)</script>" {
+		t.Errorf("content after toggle = %q", toggled.Content)
+	}
+
+	var fetched note.Note
+	sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/notes/%d", ts.URL, n.ID), nil, &fetched)
+	if fetched.Content != toggled.Content || fetched.HTML != toggled.HTML {
+		t.Errorf("fetched note = %+v, want the toggled note", fetched)
+	}
+
+	t.Run("no such task", func(t *testing.T) {
+		status := sendJSON(t, http.MethodPost, fmt.Sprintf("%s/api/notes/%d/tasks/2/toggle", ts.URL, n.ID), nil, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("unknown note", func(t *testing.T) {
+		status := sendJSON(t, http.MethodPost, ts.URL+"/api/notes/99999/tasks/0/toggle", nil, nil)
+		if status != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("content too long", func(t *testing.T) {
+		body := map[string]any{"title": "Long", "content": strings.Repeat("a*", 40<<10), "color": "yellow"}
+		if status := sendJSON(t, http.MethodPost, ts.URL+"/api/notes", body, nil); status != http.StatusBadRequest {
+			t.Errorf("create status = %d, want %d", status, http.StatusBadRequest)
+		}
+		if status := sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/notes/%d", ts.URL, n.ID), body, nil); status != http.StatusBadRequest {
+			t.Errorf("update status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/stadtaev/lofam/backend/internal/notification"
+)
+
+// listNotifications returns the requesting member's notifications, only the
+// unread ones with ?unread=true.
+func (s *Server) listNotifications(w http.ResponseWriter, r *http.Request) {
+	var filter notification.Filter
+	if value := r.URL.Query().Get("unread"); value != "" {
+		unread, err := strconv.ParseBool(value)
+		if err != nil {
+			handleError(w, notification.ErrValidation("unread must be true or false"))
+			return
+		}
+		filter.Unread = unread
+	}
+
+	notifications, err := s.notificationService.List(r.Context(), filter)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, notifications)
+}
+
+func (s *Server) markNotificationRead(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	n, err := s.notificationService.MarkRead(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, n)
+}
+
+func (s *Server) markAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	if err := s.notificationService.MarkAllRead(r.Context()); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/stadtaev/lofam/backend/internal/occasion"
+)
+
+func (s *Server) listOccasions(w http.ResponseWriter, r *http.Request) {
+	occasions, err := s.occasionService.List(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, occasions)
+}
+
+func (s *Server) createOccasion(w http.ResponseWriter, r *http.Request) {
+	var req occasion.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	o, err := s.occasionService.Create(r.Context(), req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, o)
+}
+
+func (s *Server) getOccasion(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	o, err := s.occasionService.GetByID(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, o)
+}
+
+func (s *Server) updateOccasion(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req occasion.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	o, err := s.occasionService.Update(r.Context(), id, req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, o)
+}
+
+func (s *Server) deleteOccasion(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := s.occasionService.Delete(r.Context(), id); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getOccasionSummary(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	summary, err := s.occasionService.Summary(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
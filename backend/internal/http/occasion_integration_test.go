@@ -0,0 +1,83 @@
+//go:build integration
+
+package http_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/member"
+	"github.com/stadtaev/lofam/backend/internal/occasion"
+	"github.com/stadtaev/lofam/backend/internal/wishlist"
+)
+
+func TestOccasionSummary(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var anna member.Member
+	sendJSON(t, http.MethodPost, ts.URL+"/api/members", map[string]any{"name": "Anna"}, &anna)
+
+	list := createTestWishlist(t, ts.URL, "Birthday")
+	itemsURL := fmt.Sprintf("%s/api/wishlists/%d/items", ts.URL, list.ID)
+	var book, lamp wishlist.Item
+	sendJSON(t, http.MethodPost, itemsURL, map[string]any{"name": "Book", "priceCents": 2500, "currency": "EUR"}, &book)
+	sendJSON(t, http.MethodPost, itemsURL, map[string]any{"name": "Lamp", "priceCents": 4000, "currency": "EUR"}, &lamp)
+
+	var o occasion.Occasion
+	status := sendJSON(t, http.MethodPost, ts.URL+"/api/occasions", map[string]any{
+		"name": "Leo's birthday", "person": "Leo", "date": "2015-04-02", "yearly": true,
+		"budgetCents": 5000, "currency": "EUR", "wishlistIds": []int64{list.ID},
+	}, &o)
+	if status != http.StatusCreated {
+		t.Fatalf("create occasion status = %d", status)
+	}
+	if len(o.WishlistIDs) != 1 || o.NextDate == "" {
+		t.Errorf("occasion = %+v, want one wishlist and a next date", o)
+	}
+
+	sendJSONAs(t, anna.ID, http.MethodPost, fmt.Sprintf("%s/%d/reserve", itemsURL, book.ID), nil, nil)
+	sendJSONAs(t, anna.ID, http.MethodPost, fmt.Sprintf("%s/%d/bought", itemsURL, book.ID), nil, nil)
+	sendJSONAs(t, anna.ID, http.MethodPost, fmt.Sprintf("%s/%d/reserve", itemsURL, lamp.ID), nil, nil)
+
+	var s occasion.Summary
+	status = sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/occasions/%d/summary", ts.URL, o.ID), nil, &s)
+	if status != http.StatusOK {
+		t.Fatalf("summary status = %d", status)
+	}
+	if s.BoughtCents != 2500 || s.ReservedCents != 4000 || s.TotalCents != 6500 {
+		t.Errorf("summary totals = %+v", s)
+	}
+	if s.RemainingCents == nil || *s.RemainingCents != -1500 {
+		t.Errorf("remaining = %v, want -1500", s.RemainingCents)
+	}
+	if len(s.Givers) != 1 || s.Givers[0].Name != "Anna" || s.Givers[0].Items != 2 {
+		t.Errorf("givers = %+v", s.Givers)
+	}
+
+	t.Run("unknown wishlist", func(t *testing.T) {
+		status := sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/occasions/%d", ts.URL, o.ID),
+			map[string]any{"wishlistIds": []int64{99999}}, nil)
+		if status != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("deleting the wishlist unlinks it", func(t *testing.T) {
+		sendJSON(t, http.MethodDelete, fmt.Sprintf("%s/api/wishlists/%d", ts.URL, list.ID), nil, nil)
+		var got occasion.Occasion
+		sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/occasions/%d", ts.URL, o.ID), nil, &got)
+		if len(got.WishlistIDs) != 0 {
+			t.Errorf("wishlistIds = %v, want none", got.WishlistIDs)
+		}
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		status := sendJSON(t, http.MethodPost, ts.URL+"/api/occasions",
+			map[string]any{"name": "X", "date": "04/02/2015"}, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+//go:build integration
+
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/note"
+	"github.com/stadtaev/lofam/backend/internal/palette"
+	"github.com/stadtaev/lofam/backend/internal/sqlite"
+)
+
+func TestPalette(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var colors []palette.Color
+	sendJSON(t, http.MethodGet, ts.URL+"/api/palette", nil, &colors)
+	if len(colors) != 3 || colors[0].Name != "yellow" || colors[0].DarkHex == "" {
+		t.Fatalf("default palette = %+v", colors)
+	}
+
+	t.Run("notes must use palette colours", func(t *testing.T) {
+		status := sendJSON(t, http.MethodPost, ts.URL+"/api/notes", map[string]any{"title": "X", "color": "teal"}, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", status, http.StatusBadRequest)
+		}
+		status = sendJSON(t, http.MethodPost, ts.URL+"/api/wishlists", map[string]any{"title": "X", "color": "teal"}, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("wishlist status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+
+	var teal palette.Color
+	status := sendJSON(t, http.MethodPost, ts.URL+"/api/palette", map[string]any{"name": "teal", "hex": "#99f6e4"}, &teal)
+	if status != http.StatusCreated {
+		t.Fatalf("create colour status = %d", status)
+	}
+	if teal.DarkHex != "#99f6e4" || teal.Position != 3 {
+		t.Errorf("teal = %+v, want darkHex defaulted and last position", teal)
+	}
+
+	var n note.Note
+	if status := sendJSON(t, http.MethodPost, ts.URL+"/api/notes", map[string]any{"title": "X", "color": "teal"}, &n); status != http.StatusCreated {
+		t.Fatalf("create teal note status = %d", status)
+	}
+
+	t.Run("duplicate name", func(t *testing.T) {
+		status := sendJSON(t, http.MethodPost, ts.URL+"/api/palette", map[string]any{"name": "teal", "hex": "#000000"}, nil)
+		if status != http.StatusConflict {
+			t.Errorf("status = %d, want %d", status, http.StatusConflict)
+		}
+	})
+
+	t.Run("colours in use cannot be deleted", func(t *testing.T) {
+		url := fmt.Sprintf("%s/api/palette/%d", ts.URL, teal.ID)
+		if status := sendJSON(t, http.MethodDelete, url, nil, nil); status != http.StatusConflict {
+			t.Errorf("status = %d, want %d", status, http.StatusConflict)
+		}
+		sendJSON(t, http.MethodDelete, fmt.Sprintf("%s/api/notes/%d", ts.URL, n.ID), nil, nil)
+		if status := sendJSON(t, http.MethodDelete, url, nil, nil); status != http.StatusNoContent {
+			t.Errorf("status after deleting the note = %d, want %d", status, http.StatusNoContent)
+		}
+	})
+
+	t.Run("invalid hex", func(t *testing.T) {
+		status := sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/palette/%d", ts.URL, colors[0].ID),
+			map[string]any{"hex": "yellow"}, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestPaletteMigrationKeepsColoursInUse(t *testing.T) {
+	db, err := sqlite.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	// Stand in for a database from before the palette existed.
+	if _, err := db.Exec(`
+		INSERT INTO notes (title, color) VALUES ('Old', 'orange'), ('Older', 'blue'), ('Oldest', 'pink');
+		DROP TABLE palette_colors;
+	`); err != nil {
+		t.Fatalf("failed to set up legacy data: %v", err)
+	}
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("failed to migrate again: %v", err)
+	}
+
+	store := sqlite.NewPaletteStore(db)
+	colors, err := store.List(context.Background())
+	if err != nil {
+		t.Fatalf("failed to list palette: %v", err)
+	}
+	var names []string
+	positions := map[int]bool{}
+	for _, c := range colors {
+		names = append(names, c.Name)
+		positions[c.Position] = true
+	}
+	if fmt.Sprint(names) != "[yellow pink green blue orange]" {
+		t.Errorf("palette = %v, want the defaults plus blue and orange", names)
+	}
+	if len(positions) != len(colors) {
+		t.Errorf("palette positions are not distinct: %+v", colors)
+	}
+
+	t.Run("deleted defaults stay deleted", func(t *testing.T) {
+		if _, err := db.Exec(`DELETE FROM notes; DELETE FROM palette_colors`); err != nil {
+			t.Fatalf("failed to clear palette: %v", err)
+		}
+		if err := db.Migrate(); err != nil {
+			t.Fatalf("failed to migrate again: %v", err)
+		}
+		colors, err := store.List(context.Background())
+		if err != nil {
+			t.Fatalf("failed to list palette: %v", err)
+		}
+		if len(colors) != 0 {
+			t.Errorf("palette = %+v, want it to stay empty", colors)
+		}
+	})
+}
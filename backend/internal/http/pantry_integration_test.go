@@ -0,0 +1,123 @@
+//go:build integration
+
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/agenda"
+	"github.com/stadtaev/lofam/backend/internal/pantry"
+	"github.com/stadtaev/lofam/backend/internal/shopping"
+)
+
+func sendJSON(t *testing.T, method, url string, body any, out any) int {
+	t.Helper()
+	return sendJSONAs(t, 0, method, url, body, out)
+}
+
+// sendJSONAs sends a request on behalf of a household member; memberID 0
+// sends it anonymously.
+func sendJSONAs(t *testing.T, memberID int64, method, url string, body any, out any) int {
+	t.Helper()
+	b, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, url, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	if memberID != 0 {
+		req.Header.Set("X-Member-ID", fmt.Sprint(memberID))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		json.NewDecoder(resp.Body).Decode(out)
+	}
+	return resp.StatusCode
+}
+
+func listPantry(t *testing.T, baseURL string) []pantry.Item {
+	t.Helper()
+	var items []pantry.Item
+	if status := sendJSON(t, http.MethodGet, baseURL+"/api/pantry", nil, &items); status != http.StatusOK {
+		t.Fatalf("list pantry status = %d", status)
+	}
+	return items
+}
+
+func TestCheckedShoppingItemsMoveToPantry(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var item shopping.Item
+	sendJSON(t, http.MethodPost, ts.URL+"/api/shopping", map[string]any{
+		"title": "Milk", "quantity": 2, "unit": "l",
+	}, &item)
+
+	sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/shopping/%d", ts.URL, item.ID), map[string]any{"checked": true}, nil)
+
+	items := listPantry(t, ts.URL)
+	if len(items) != 1 {
+		t.Fatalf("len(pantry) = %d, want 1", len(items))
+	}
+	got := items[0]
+	if got.Name != "Milk" || got.Quantity != 2 || got.Unit != "l" {
+		t.Errorf("pantry item = %+v", got)
+	}
+	if got.Location != pantry.LocationFridge {
+		t.Errorf("location = %q, want %q", got.Location, pantry.LocationFridge)
+	}
+	if got.ExpiresOn == nil {
+		t.Error("expected dairy to get an expiry date")
+	}
+
+	sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/shopping/%d", ts.URL, item.ID), map[string]any{"checked": false}, nil)
+
+	if n := len(listPantry(t, ts.URL)); n != 0 {
+		t.Errorf("len(pantry) after uncheck = %d, want 0", n)
+	}
+}
+
+func TestAgenda(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	today := time.Now()
+	yesterday := today.AddDate(0, 0, -1)
+	nextWeek := today.AddDate(0, 0, 7)
+
+	sendJSON(t, http.MethodPost, ts.URL+"/api/tasks", map[string]any{"title": "Overdue", "dueDate": yesterday}, nil)
+	sendJSON(t, http.MethodPost, ts.URL+"/api/tasks", map[string]any{"title": "Later", "dueDate": nextWeek}, nil)
+	sendJSON(t, http.MethodPost, ts.URL+"/api/tasks", map[string]any{"title": "Someday"}, nil)
+
+	for name, date := range map[string]time.Time{"Yoghurt": today.AddDate(0, 0, 2), "Rice": nextWeek} {
+		expires := date.Format(pantry.DateLayout)
+		status := sendJSON(t, http.MethodPost, ts.URL+"/api/pantry", pantry.CreateRequest{
+			Name: name, Location: pantry.LocationFridge, ExpiresOn: &expires,
+		}, nil)
+		if status != http.StatusCreated {
+			t.Fatalf("create pantry item status = %d", status)
+		}
+	}
+
+	var got agenda.Agenda
+	if status := sendJSON(t, http.MethodGet, ts.URL+"/api/agenda", nil, &got); status != http.StatusOK {
+		t.Fatalf("status = %d, want %d", status, http.StatusOK)
+	}
+
+	if len(got.Tasks) != 1 || got.Tasks[0].Title != "Overdue" {
+		t.Errorf("tasks = %+v, want only Overdue", got.Tasks)
+	}
+	if len(got.Expiring) != 1 || got.Expiring[0].Name != "Yoghurt" || got.Expiring[0].DaysLeft != 2 {
+		t.Errorf("expiring = %+v, want Yoghurt in 2 days", got.Expiring)
+	}
+
+	if status := sendJSON(t, http.MethodGet, ts.URL+"/api/agenda?days=-1", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("negative days status = %d, want %d", status, http.StatusBadRequest)
+	}
+}
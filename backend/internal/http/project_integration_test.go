@@ -0,0 +1,180 @@
+//go:build integration
+
+package http_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/project"
+	"github.com/stadtaev/lofam/backend/internal/task"
+)
+
+func TestProjects(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var p project.Project
+	status := sendJSON(t, http.MethodPost, ts.URL+"/api/projects", map[string]any{
+		"name": "Renovation",
+		"columns": []map[string]any{
+			{"name": "Ideas", "category": "todo"},
+			{"name": "Buying", "category": "in_progress"},
+			{"name": "Building", "category": "in_progress"},
+			{"name": "Finished", "category": "done"},
+		},
+	}, &p)
+	if status != http.StatusCreated || len(p.Columns) != 4 {
+		t.Fatalf("create: status = %d, project = %+v", status, p)
+	}
+	ideas, buying, building, finished := p.Columns[0], p.Columns[1], p.Columns[2], p.Columns[3]
+
+	projectURL := fmt.Sprintf("%s/api/projects/%d", ts.URL, p.ID)
+	taskURL := func(id int64) string { return fmt.Sprintf("%s/api/tasks/%d", ts.URL, id) }
+
+	var tiles, paint task.Task
+	sendJSON(t, http.MethodPost, ts.URL+"/api/tasks", map[string]any{"title": "Pick tiles", "projectId": p.ID}, &tiles)
+	if tiles.ColumnID == nil || *tiles.ColumnID != ideas.ID || tiles.Status != task.StatusTodo {
+		t.Errorf("new task placed in column %v with status %s", tiles.ColumnID, tiles.Status)
+	}
+	sendJSON(t, http.MethodPost, ts.URL+"/api/tasks", map[string]any{"title": "Buy paint", "projectId": p.ID, "columnId": buying.ID}, &paint)
+	if paint.Status != task.StatusInProgress {
+		t.Errorf("task created in %q has status %s", buying.Name, paint.Status)
+	}
+	createTestTask(t, ts.URL, "Walk the dog")
+
+	t.Run("column sets status", func(t *testing.T) {
+		var moved task.Task
+		sendJSON(t, http.MethodPut, taskURL(tiles.ID), map[string]any{"columnId": building.ID}, &moved)
+		if moved.Status != task.StatusInProgress || *moved.ColumnID != building.ID {
+			t.Errorf("moved task = %+v", moved)
+		}
+
+		sendJSON(t, http.MethodPut, taskURL(tiles.ID), map[string]any{"status": "done"}, &moved)
+		if *moved.ColumnID != finished.ID {
+			t.Errorf("done task in column %d, want %d", *moved.ColumnID, finished.ID)
+		}
+
+		status := sendJSON(t, http.MethodPut, taskURL(tiles.ID), map[string]any{"columnId": buying.ID, "status": "todo"}, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("status contradicting column: status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("status filters still work", func(t *testing.T) {
+		var tasks []task.Task
+		sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/tasks?projectId=%d", ts.URL, p.ID), nil, &tasks)
+		if len(tasks) != 2 {
+			t.Fatalf("project tasks = %+v", tasks)
+		}
+		for _, tk := range tasks {
+			if tk.ID == tiles.ID && tk.Status != task.StatusDone {
+				t.Errorf("task in %q has status %s, want done", finished.Name, tk.Status)
+			}
+		}
+	})
+
+	t.Run("board", func(t *testing.T) {
+		var board project.Board
+		if status := sendJSON(t, http.MethodGet, projectURL+"/board", nil, &board); status != http.StatusOK {
+			t.Fatalf("board status = %d", status)
+		}
+		var counts []int
+		for _, c := range board.Columns {
+			counts = append(counts, len(c.Tasks))
+		}
+		if fmt.Sprint(counts) != "[0 1 0 1]" || board.Columns[1].Tasks[0].ID != paint.ID {
+			t.Errorf("board columns = %+v", board.Columns)
+		}
+	})
+
+	t.Run("removing a column moves its tasks", func(t *testing.T) {
+		var updated project.Project
+		status := sendJSON(t, http.MethodPut, projectURL, map[string]any{
+			"columns": []map[string]any{
+				{"id": ideas.ID, "name": "Backlog", "category": "todo"},
+				{"id": building.ID, "name": "Building", "category": "in_progress"},
+				{"name": "Checked", "category": "done"},
+				{"id": finished.ID, "name": "Finished", "category": "done"},
+			},
+		}, &updated)
+		if status != http.StatusOK || len(updated.Columns) != 4 || updated.Columns[0].Name != "Backlog" {
+			t.Fatalf("update: status = %d, project = %+v", status, updated)
+		}
+
+		var moved task.Task
+		sendJSON(t, http.MethodGet, taskURL(paint.ID), nil, &moved)
+		if moved.ColumnID == nil || *moved.ColumnID != building.ID || moved.Status != task.StatusInProgress {
+			t.Errorf("task from removed column = %+v", moved)
+		}
+	})
+
+	t.Run("recategorising a column with blocked tasks", func(t *testing.T) {
+		var sand task.Task
+		sendJSON(t, http.MethodPost, ts.URL+"/api/tasks", map[string]any{"title": "Sand walls", "projectId": p.ID}, &sand)
+		blocker := createTestTask(t, ts.URL, "Fill cracks")
+		sendJSON(t, http.MethodPost, taskURL(sand.ID)+"/blockers", map[string]any{"blockerId": blocker.ID}, nil)
+
+		var current project.Project
+		sendJSON(t, http.MethodGet, projectURL, nil, &current)
+		columns := []map[string]any{{"name": "New", "category": "todo"}}
+		for _, c := range current.Columns {
+			category := c.Category
+			if c.ID == ideas.ID {
+				category = task.StatusInProgress
+			}
+			columns = append(columns, map[string]any{"id": c.ID, "name": c.Name, "category": category})
+		}
+		if status := sendJSON(t, http.MethodPut, projectURL, map[string]any{"columns": columns}, nil); status != http.StatusConflict {
+			t.Errorf("status = %d, want %d", status, http.StatusConflict)
+		}
+
+		var got task.Task
+		sendJSON(t, http.MethodGet, taskURL(sand.ID), nil, &got)
+		if got.Status != task.StatusTodo {
+			t.Errorf("blocked task status = %s, want todo", got.Status)
+		}
+		sendJSON(t, http.MethodGet, projectURL, nil, &current)
+		if len(current.Columns) != 4 {
+			t.Errorf("columns after refused update = %+v", current.Columns)
+		}
+		sendJSON(t, http.MethodDelete, taskURL(sand.ID), nil, nil)
+	})
+
+	t.Run("invalid columns", func(t *testing.T) {
+		for name, columns := range map[string][]map[string]any{
+			"missing category": {{"name": "Todo", "category": "todo"}, {"name": "Done", "category": "done"}},
+			"unknown category": {{"name": "Todo", "category": "todo"}, {"name": "Doing", "category": "doing"}, {"name": "Done", "category": "done"}},
+			"duplicate name":   {{"name": "Todo", "category": "todo"}, {"name": "todo", "category": "in_progress"}, {"name": "Done", "category": "done"}},
+			"foreign column": {{"id": 99999, "name": "Todo", "category": "todo"},
+				{"name": "Doing", "category": "in_progress"}, {"name": "Done", "category": "done"}},
+		} {
+			if status := sendJSON(t, http.MethodPut, projectURL, map[string]any{"columns": columns}, nil); status != http.StatusBadRequest {
+				t.Errorf("%s: status = %d, want %d", name, status, http.StatusBadRequest)
+			}
+		}
+
+		var other project.Project
+		sendJSON(t, http.MethodPost, ts.URL+"/api/projects", map[string]any{"name": "Holiday"}, &other)
+		if len(other.Columns) != 3 {
+			t.Errorf("default columns = %+v", other.Columns)
+		}
+		if status := sendJSON(t, http.MethodPut, taskURL(paint.ID), map[string]any{"columnId": other.Columns[0].ID}, nil); status != http.StatusBadRequest {
+			t.Errorf("column of another project: status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("delete keeps tasks", func(t *testing.T) {
+		if status := sendJSON(t, http.MethodDelete, projectURL, nil, nil); status != http.StatusNoContent {
+			t.Fatalf("delete status = %d", status)
+		}
+		var kept task.Task
+		if status := sendJSON(t, http.MethodGet, taskURL(paint.ID), nil, &kept); status != http.StatusOK {
+			t.Fatalf("task status = %d", status)
+		}
+		if kept.ProjectID != nil || kept.ColumnID != nil || kept.Status != task.StatusInProgress {
+			t.Errorf("task after deleting project = %+v", kept)
+		}
+	})
+}
@@ -0,0 +1,148 @@
+//go:build integration
+
+package http_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/member"
+	"github.com/stadtaev/lofam/backend/internal/task"
+)
+
+func TestScheduledTasks(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var mum, dad member.Member
+	sendJSON(t, http.MethodPost, ts.URL+"/api/members", map[string]any{"name": "Mum"}, &mum)
+	sendJSON(t, http.MethodPost, ts.URL+"/api/members", map[string]any{"name": "Dad"}, &dad)
+
+	create := func(body map[string]any) task.Task {
+		t.Helper()
+		var created task.Task
+		if status := sendJSON(t, http.MethodPost, ts.URL+"/api/tasks", body, &created); status != http.StatusCreated {
+			t.Fatalf("create %v: status = %d", body["title"], status)
+		}
+		return created
+	}
+
+	dentist := create(map[string]any{"title": "Dentist", "startAt": "2026-05-04T09:30:00+02:00",
+		"durationMinutes": 60, "assigneeId": mum.ID})
+	if got := dentist.StartAt.Format(time.RFC3339); got != "2026-05-04T07:30:00Z" {
+		t.Errorf("startAt = %s, want it in UTC", got)
+	}
+	if dentist.EndAt == nil || dentist.EndAt.Sub(*dentist.StartAt) != time.Hour || dentist.AllDay {
+		t.Errorf("dentist = %+v", dentist)
+	}
+
+	school := create(map[string]any{"title": "Parents' evening", "startAt": "2026-05-04T08:00:00Z",
+		"endAt": "2026-05-04T09:00:00Z", "assigneeId": mum.ID})
+	trip := create(map[string]any{"title": "Camping", "startAt": "2026-05-08T15:00:00Z",
+		"endAt": "2026-05-09T12:00:00Z", "allDay": true, "assigneeId": mum.ID})
+	if trip.StartAt.Format(time.RFC3339) != "2026-05-08T00:00:00Z" || trip.EndAt.Format(time.RFC3339) != "2026-05-10T00:00:00Z" {
+		t.Errorf("all-day task runs %s to %s, want whole days", trip.StartAt, trip.EndAt)
+	}
+	// Local midnight stays on its date, wherever the client is.
+	holiday := create(map[string]any{"title": "Holiday", "startAt": "2026-05-09T00:00:00+02:00",
+		"endAt": "2026-05-10T00:00:00+02:00", "allDay": true, "assigneeId": mum.ID})
+	if holiday.StartAt.Format(time.RFC3339) != "2026-05-09T00:00:00Z" || holiday.EndAt.Format(time.RFC3339) != "2026-05-10T00:00:00Z" {
+		t.Errorf("local all-day task runs %s to %s, want May 9", holiday.StartAt, holiday.EndAt)
+	}
+	create(map[string]any{"title": "Call plumber", "startAt": "2026-05-04T08:30:00Z", "assigneeId": mum.ID})
+	create(map[string]any{"title": "Football", "startAt": "2026-05-04T08:00:00Z", "durationMinutes": 90, "assigneeId": dad.ID})
+	create(map[string]any{"title": "Unscheduled"})
+
+	t.Run("validation", func(t *testing.T) {
+		for name, body := range map[string]map[string]any{
+			"end before start":    {"title": "x", "startAt": "2026-05-04T10:00:00Z", "endAt": "2026-05-04T09:00:00Z"},
+			"end without start":   {"title": "x", "endAt": "2026-05-04T09:00:00Z"},
+			"all day, no start":   {"title": "x", "allDay": true},
+			"end and duration":    {"title": "x", "startAt": "2026-05-04T10:00:00Z", "endAt": "2026-05-04T11:00:00Z", "durationMinutes": 60},
+			"negative duration":   {"title": "x", "startAt": "2026-05-04T10:00:00Z", "durationMinutes": -5},
+			"huge duration":       {"title": "x", "startAt": "2026-05-04T10:00:00Z", "durationMinutes": 200000000},
+			"all-day duration":    {"title": "x", "startAt": "2026-05-04T00:00:00Z", "allDay": true, "durationMinutes": 60},
+			"empty all-day range": {"title": "x", "startAt": "2026-05-04T00:00:00Z", "endAt": "2026-05-04T00:00:00Z", "allDay": true},
+		} {
+			if status := sendJSON(t, http.MethodPost, ts.URL+"/api/tasks", body, nil); status != http.StatusBadRequest {
+				t.Errorf("%s: status = %d, want %d", name, status, http.StatusBadRequest)
+			}
+		}
+		if status := sendJSON(t, http.MethodPost, ts.URL+"/api/tasks", map[string]any{"title": "x", "assigneeId": 99999}, nil); status != http.StatusNotFound {
+			t.Errorf("unknown assignee: status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	rangeURL := func(path, from, to string, extra ...string) string {
+		q := url.Values{"from": {from}, "to": {to}}
+		for i := 0; i+1 < len(extra); i += 2 {
+			q.Set(extra[i], extra[i+1])
+		}
+		return ts.URL + path + "?" + q.Encode()
+	}
+
+	t.Run("range", func(t *testing.T) {
+		got := fmt.Sprint(listedTitles(t, rangeURL("/api/tasks", "2026-05-04T08:15:00Z", "2026-05-04T09:00:00Z")))
+		if got != "[Dentist Football Parents' evening Call plumber]" {
+			t.Errorf("morning of May 4 = %s", got)
+		}
+		got = fmt.Sprint(listedTitles(t, rangeURL("/api/tasks", "2026-05-09T00:00:00Z", "2026-05-10T00:00:00Z")))
+		if got != "[Camping Holiday]" {
+			t.Errorf("May 9 = %s", got)
+		}
+		got = fmt.Sprint(listedTitles(t, rangeURL("/api/tasks", "2026-05-04T00:00:00Z", "2026-05-05T00:00:00Z", "assigneeId", fmt.Sprint(dad.ID))))
+		if got != "[Football]" {
+			t.Errorf("Dad's May 4 = %s", got)
+		}
+		if status := sendJSON(t, http.MethodGet, rangeURL("/api/tasks", "2026-05-05T00:00:00Z", "2026-05-04T00:00:00Z"), nil, nil); status != http.StatusBadRequest {
+			t.Errorf("inverted range: status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		var conflicts []task.Conflict
+		sendJSON(t, http.MethodGet, rangeURL("/api/tasks/conflicts", "2026-05-01T00:00:00Z", "2026-06-01T00:00:00Z"), nil, &conflicts)
+		if len(conflicts) != 2 {
+			t.Fatalf("conflicts = %+v", conflicts)
+		}
+		if c := conflicts[0]; c.MemberID != mum.ID || c.Tasks[0].ID != dentist.ID || c.Tasks[1].ID != school.ID ||
+			c.Start.Format(time.RFC3339) != "2026-05-04T08:00:00Z" || c.End.Format(time.RFC3339) != "2026-05-04T08:30:00Z" {
+			t.Errorf("timed conflict = %+v", c)
+		}
+		if c := conflicts[1]; c.Tasks[0].ID != trip.ID || c.Tasks[1].Title != "Holiday" {
+			t.Errorf("all-day conflict = %+v", c)
+		}
+	})
+
+	t.Run("reschedule", func(t *testing.T) {
+		var moved task.Task
+		sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/tasks/%d", ts.URL, dentist.ID), map[string]any{"startAt": "2026-05-04T13:00:00Z"}, &moved)
+		if moved.EndAt == nil || moved.EndAt.Format(time.RFC3339) != "2026-05-04T14:00:00Z" {
+			t.Errorf("moved dentist ends at %v, want the same length", moved.EndAt)
+		}
+
+		var conflicts []task.Conflict
+		sendJSON(t, http.MethodGet, rangeURL("/api/tasks/conflicts", "2026-05-04T00:00:00Z", "2026-05-05T00:00:00Z"), nil, &conflicts)
+		if len(conflicts) != 0 {
+			t.Errorf("conflicts after moving = %+v", conflicts)
+		}
+
+		var cleared task.Task
+		sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/tasks/%d", ts.URL, dentist.ID), map[string]any{"unschedule": true, "assigneeId": 0}, &cleared)
+		if cleared.StartAt != nil || cleared.EndAt != nil || cleared.AssigneeID != nil {
+			t.Errorf("unscheduled task = %+v", cleared)
+		}
+	})
+
+	t.Run("deleting a member unassigns", func(t *testing.T) {
+		sendJSON(t, http.MethodDelete, fmt.Sprintf("%s/api/members/%d", ts.URL, dad.ID), nil, nil)
+		var tasks []task.Task
+		sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/tasks?assigneeId=%d", ts.URL, dad.ID), nil, &tasks)
+		if len(tasks) != 0 {
+			t.Errorf("tasks still assigned to a deleted member: %+v", tasks)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io/fs"
@@ -15,22 +16,97 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 
+	"github.com/stadtaev/lofam/backend/internal/agenda"
+	"github.com/stadtaev/lofam/backend/internal/attachment"
+	"github.com/stadtaev/lofam/backend/internal/blob"
+	"github.com/stadtaev/lofam/backend/internal/comment"
+	"github.com/stadtaev/lofam/backend/internal/idempotency"
+	"github.com/stadtaev/lofam/backend/internal/mealplan"
+	"github.com/stadtaev/lofam/backend/internal/member"
 	"github.com/stadtaev/lofam/backend/internal/note"
+	"github.com/stadtaev/lofam/backend/internal/notification"
+	"github.com/stadtaev/lofam/backend/internal/occasion"
+	"github.com/stadtaev/lofam/backend/internal/palette"
+	"github.com/stadtaev/lofam/backend/internal/pantry"
+	"github.com/stadtaev/lofam/backend/internal/project"
+	"github.com/stadtaev/lofam/backend/internal/recipe"
 	"github.com/stadtaev/lofam/backend/internal/shopping"
+	"github.com/stadtaev/lofam/backend/internal/tag"
 	"github.com/stadtaev/lofam/backend/internal/task"
 	"github.com/stadtaev/lofam/backend/internal/wishlist"
 )
 
 type Server struct {
-	taskService     *task.Service
-	noteService     *note.Service
-	wishlistService *wishlist.Service
-	shoppingService *shopping.Service
-	staticDir       string
+	taskService         *task.Service
+	noteService         *note.Service
+	wishlistService     *wishlist.Service
+	memberService       *member.Service
+	occasionService     *occasion.Service
+	shoppingService     *shopping.Service
+	recipeService       *recipe.Service
+	mealplanService     *mealplan.Service
+	pantryService       *pantry.Service
+	agendaService       *agenda.Service
+	tagService          *tag.Service
+	paletteService      *palette.Service
+	attachmentService   *attachment.Service
+	commentService      *comment.Service
+	notificationService *notification.Service
+	projectService      *project.Service
+	idempotencyService  *idempotency.Service
+	tx                  Transactor
+	staticDir           string
 }
 
-func NewServer(taskService *task.Service, noteService *note.Service, wishlistService *wishlist.Service, shoppingService *shopping.Service, staticDir string) *Server {
-	return &Server{taskService: taskService, noteService: noteService, wishlistService: wishlistService, shoppingService: shoppingService, staticDir: staticDir}
+// Transactor runs a function inside a database transaction whose handle is
+// carried by the context passed to fn.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	WithinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+func NewServer(
+	taskService *task.Service,
+	noteService *note.Service,
+	wishlistService *wishlist.Service,
+	memberService *member.Service,
+	occasionService *occasion.Service,
+	shoppingService *shopping.Service,
+	recipeService *recipe.Service,
+	mealplanService *mealplan.Service,
+	pantryService *pantry.Service,
+	agendaService *agenda.Service,
+	tagService *tag.Service,
+	paletteService *palette.Service,
+	attachmentService *attachment.Service,
+	commentService *comment.Service,
+	notificationService *notification.Service,
+	projectService *project.Service,
+	idempotencyService *idempotency.Service,
+	tx Transactor,
+	staticDir string,
+) *Server {
+	return &Server{
+		taskService:         taskService,
+		noteService:         noteService,
+		wishlistService:     wishlistService,
+		memberService:       memberService,
+		occasionService:     occasionService,
+		shoppingService:     shoppingService,
+		recipeService:       recipeService,
+		mealplanService:     mealplanService,
+		pantryService:       pantryService,
+		agendaService:       agendaService,
+		tagService:          tagService,
+		paletteService:      paletteService,
+		attachmentService:   attachmentService,
+		commentService:      commentService,
+		notificationService: notificationService,
+		projectService:      projectService,
+		idempotencyService:  idempotencyService,
+		tx:                  tx,
+		staticDir:           staticDir,
+	}
 }
 
 func (s *Server) Router() chi.Router {
@@ -48,26 +124,46 @@ func (s *Server) Router() chi.Router {
 		r.Use(cors.Handler(cors.Options{
 			AllowedOrigins:   []string{"http://localhost:3000"},
 			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowedHeaders:   []string{"Accept", "Content-Type"},
+			AllowedHeaders:   []string{"Accept", "Content-Type", idempotencyKeyHeader, memberIDHeader},
 			AllowCredentials: false,
 			MaxAge:           300,
 		}))
+		r.Use(s.identifyMember)
+		r.Use(s.idempotent)
 		r.Route("/tasks", func(r chi.Router) {
 			r.Get("/", s.listTasks)
 			r.Post("/", s.createTask)
+			r.Get("/conflicts", s.listTaskConflicts)
 			r.Route("/{id}", func(r chi.Router) {
 				r.Get("/", s.getTask)
 				r.Put("/", s.updateTask)
 				r.Delete("/", s.deleteTask)
+				r.Put("/tags", s.setTags(tag.KindTask, s.taskExists))
+				r.Get("/attachments", s.listAttachments(attachment.OwnerTask))
+				r.Post("/attachments", s.uploadAttachment(attachment.OwnerTask))
+				r.Post("/move", s.moveTask)
+				r.Post("/blockers", s.addTaskBlocker)
+				r.Delete("/blockers/{blockerId}", s.removeTaskBlocker)
+				r.Get("/comments", s.listComments)
+				r.Post("/comments", s.createComment)
+				r.Route("/comments/{commentId}", func(r chi.Router) {
+					r.Put("/", s.updateComment)
+					r.Delete("/", s.deleteComment)
+				})
 			})
 		})
 		r.Route("/notes", func(r chi.Router) {
 			r.Get("/", s.listNotes)
 			r.Post("/", s.createNote)
+			r.Put("/order", s.reorderNotes)
 			r.Route("/{id}", func(r chi.Router) {
 				r.Get("/", s.getNote)
 				r.Put("/", s.updateNote)
 				r.Delete("/", s.deleteNote)
+				r.Put("/tags", s.setTags(tag.KindNote, s.noteExists))
+				r.Post("/tasks/{index}/toggle", s.toggleNoteTask)
+				r.Get("/attachments", s.listAttachments(attachment.OwnerNote))
+				r.Post("/attachments", s.uploadAttachment(attachment.OwnerNote))
 			})
 		})
 		r.Route("/wishlists", func(r chi.Router) {
@@ -77,15 +173,147 @@ func (s *Server) Router() chi.Router {
 				r.Get("/", s.getWishlist)
 				r.Put("/", s.updateWishlist)
 				r.Delete("/", s.deleteWishlist)
+				r.Put("/tags", s.setTags(tag.KindWishlist, s.wishlistExists))
+				r.Get("/items", s.listWishlistItems)
+				r.Post("/items", s.createWishlistItem)
+				r.Route("/items/{itemId}", func(r chi.Router) {
+					r.Get("/", s.getWishlistItem)
+					r.Put("/", s.updateWishlistItem)
+					r.Delete("/", s.deleteWishlistItem)
+					r.Post("/reserve", s.reserveWishlistItem)
+					r.Delete("/reserve", s.unreserveWishlistItem)
+					r.Post("/bought", s.markWishlistItemBought)
+				})
+				r.Get("/shares", s.listWishlistShares)
+				r.Post("/shares", s.createWishlistShare)
+				r.Delete("/shares/{shareId}", s.revokeWishlistShare)
+			})
+		})
+		r.Route("/members", func(r chi.Router) {
+			r.Get("/", s.listMembers)
+			r.Post("/", s.createMember)
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", s.getMember)
+				r.Put("/", s.updateMember)
+				r.Delete("/", s.deleteMember)
+			})
+		})
+		r.Route("/occasions", func(r chi.Router) {
+			r.Get("/", s.listOccasions)
+			r.Post("/", s.createOccasion)
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", s.getOccasion)
+				r.Put("/", s.updateOccasion)
+				r.Delete("/", s.deleteOccasion)
+				r.Get("/summary", s.getOccasionSummary)
 			})
 		})
+		r.Post("/batch", s.batch)
 		r.Route("/shopping", func(r chi.Router) {
 			r.Get("/", s.listShoppingItems)
 			r.Post("/", s.createShoppingItem)
-			r.Delete("/{id}", s.deleteShoppingItem)
+			r.Post("/clear-checked", s.clearCheckedShoppingItems)
+			r.Get("/categories", s.listShoppingCategories)
+			r.Get("/suggestions", s.listShoppingSuggestions)
+			r.Get("/suggestions/staples", s.listShoppingStaples)
+			r.Route("/lists", func(r chi.Router) {
+				r.Get("/", s.listShoppingLists)
+				r.Post("/", s.createShoppingList)
+				r.Route("/{id}", func(r chi.Router) {
+					r.Get("/", s.getShoppingList)
+					r.Put("/", s.updateShoppingList)
+					r.Delete("/", s.deleteShoppingList)
+					r.Get("/items", s.listShoppingListItems)
+					r.Post("/items", s.createShoppingListItem)
+					r.Post("/clear-checked", s.clearCheckedShoppingListItems)
+				})
+			})
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", s.getShoppingItem)
+				r.Put("/", s.updateShoppingItem)
+				r.Delete("/", s.deleteShoppingItem)
+				r.Put("/tags", s.setTags(tag.KindShoppingItem, s.shoppingItemExists))
+			})
+		})
+		r.Route("/recipes", func(r chi.Router) {
+			r.Get("/", s.listRecipes)
+			r.Post("/", s.createRecipe)
+			r.Post("/import", s.importRecipe)
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", s.getRecipe)
+				r.Put("/", s.updateRecipe)
+				r.Delete("/", s.deleteRecipe)
+			})
+		})
+		r.Route("/mealplan", func(r chi.Router) {
+			r.Get("/", s.listMealPlan)
+			r.Post("/", s.createMealPlanEntry)
+			r.Post("/shopping", s.generateMealPlanShopping)
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", s.getMealPlanEntry)
+				r.Put("/", s.updateMealPlanEntry)
+				r.Delete("/", s.deleteMealPlanEntry)
+			})
+		})
+		r.Route("/pantry", func(r chi.Router) {
+			r.Get("/", s.listPantryItems)
+			r.Post("/", s.createPantryItem)
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", s.getPantryItem)
+				r.Put("/", s.updatePantryItem)
+				r.Delete("/", s.deletePantryItem)
+			})
+		})
+		r.Get("/agenda", s.getAgenda)
+		r.Route("/palette", func(r chi.Router) {
+			r.Get("/", s.listPalette)
+			r.Post("/", s.createPaletteColor)
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", s.getPaletteColor)
+				r.Put("/", s.updatePaletteColor)
+				r.Delete("/", s.deletePaletteColor)
+			})
+		})
+		r.Route("/attachments/{id}", func(r chi.Router) {
+			r.Get("/", s.getAttachment)
+			r.Get("/content", s.downloadAttachment)
+			r.Get("/thumbnail", s.downloadThumbnail)
+			r.Delete("/", s.deleteAttachment)
+		})
+		r.Route("/projects", func(r chi.Router) {
+			r.Get("/", s.listProjects)
+			r.Post("/", s.createProject)
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", s.getProject)
+				r.Put("/", s.updateProject)
+				r.Delete("/", s.deleteProject)
+				r.Get("/board", s.getProjectBoard)
+			})
+		})
+		r.Route("/notifications", func(r chi.Router) {
+			r.Get("/", s.listNotifications)
+			r.Post("/read", s.markAllNotificationsRead)
+			r.Post("/{id}/read", s.markNotificationRead)
+		})
+		r.Route("/tags", func(r chi.Router) {
+			r.Get("/", s.listTags)
+			r.Post("/", s.createTag)
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", s.getTag)
+				r.Put("/", s.updateTag)
+				r.Delete("/", s.deleteTag)
+				r.Post("/merge", s.mergeTag)
+			})
 		})
 	})
 
+	// Public wishlist share links; the token is the only credential.
+	r.Route("/share/wishlists/{token}", func(r chi.Router) {
+		r.Use(sharePrivacy)
+		r.Get("/", s.getSharedWishlist)
+		r.Post("/items/{itemId}/reserve", s.reserveSharedWishlistItem)
+	})
+
 	// Static files (SPA)
 	r.Get("/*", s.serveStatic)
 
@@ -136,67 +364,294 @@ func writeError(w http.ResponseWriter, status int, message string) {
 }
 
 func handleError(w http.ResponseWriter, err error) {
+	status, message := errorStatus(err)
+	writeError(w, status, message)
+}
+
+// errorStatus maps a service error to an HTTP status code and client-facing
+// message. Unknown errors are logged and reported as internal errors.
+func errorStatus(err error) (int, string) {
 	// Task errors
 	var taskValidationErr task.ValidationError
 	if errors.As(err, &taskValidationErr) {
-		writeError(w, http.StatusBadRequest, taskValidationErr.Message)
-		return
+		return http.StatusBadRequest, taskValidationErr.Message
 	}
 
 	var taskNotFoundErr task.NotFoundError
 	if errors.As(err, &taskNotFoundErr) {
-		writeError(w, http.StatusNotFound, taskNotFoundErr.Error())
-		return
+		return http.StatusNotFound, taskNotFoundErr.Error()
+	}
+
+	var taskBlockedErr task.BlockedError
+	if errors.As(err, &taskBlockedErr) {
+		return http.StatusConflict, taskBlockedErr.Error()
 	}
 
 	// Note errors
 	var noteValidationErr note.ValidationError
 	if errors.As(err, &noteValidationErr) {
-		writeError(w, http.StatusBadRequest, noteValidationErr.Message)
-		return
+		return http.StatusBadRequest, noteValidationErr.Message
 	}
 
 	var noteNotFoundErr note.NotFoundError
 	if errors.As(err, &noteNotFoundErr) {
-		writeError(w, http.StatusNotFound, noteNotFoundErr.Error())
-		return
+		return http.StatusNotFound, noteNotFoundErr.Error()
 	}
 
 	// Wishlist errors
 	var wishlistValidationErr wishlist.ValidationError
 	if errors.As(err, &wishlistValidationErr) {
-		writeError(w, http.StatusBadRequest, wishlistValidationErr.Message)
-		return
+		return http.StatusBadRequest, wishlistValidationErr.Message
 	}
 
 	var wishlistNotFoundErr wishlist.NotFoundError
 	if errors.As(err, &wishlistNotFoundErr) {
-		writeError(w, http.StatusNotFound, wishlistNotFoundErr.Error())
-		return
+		return http.StatusNotFound, wishlistNotFoundErr.Error()
+	}
+
+	var wishlistItemNotFoundErr wishlist.ItemNotFoundError
+	if errors.As(err, &wishlistItemNotFoundErr) {
+		return http.StatusNotFound, wishlistItemNotFoundErr.Error()
+	}
+
+	var wishlistForbiddenErr wishlist.ForbiddenError
+	if errors.As(err, &wishlistForbiddenErr) {
+		return http.StatusForbidden, wishlistForbiddenErr.Message
+	}
+
+	var wishlistAlreadyReservedErr wishlist.AlreadyReservedError
+	if errors.As(err, &wishlistAlreadyReservedErr) {
+		return http.StatusConflict, wishlistAlreadyReservedErr.Error()
+	}
+
+	var wishlistShareNotFoundErr wishlist.ShareNotFoundError
+	if errors.As(err, &wishlistShareNotFoundErr) {
+		return http.StatusNotFound, wishlistShareNotFoundErr.Error()
+	}
+
+	// Member errors
+	var memberValidationErr member.ValidationError
+	if errors.As(err, &memberValidationErr) {
+		return http.StatusBadRequest, memberValidationErr.Message
+	}
+
+	var memberNotFoundErr member.NotFoundError
+	if errors.As(err, &memberNotFoundErr) {
+		return http.StatusNotFound, memberNotFoundErr.Error()
+	}
+
+	// Occasion errors
+	var occasionValidationErr occasion.ValidationError
+	if errors.As(err, &occasionValidationErr) {
+		return http.StatusBadRequest, occasionValidationErr.Message
+	}
+
+	var occasionNotFoundErr occasion.NotFoundError
+	if errors.As(err, &occasionNotFoundErr) {
+		return http.StatusNotFound, occasionNotFoundErr.Error()
 	}
 
 	// Shopping errors
 	var shoppingValidationErr shopping.ValidationError
 	if errors.As(err, &shoppingValidationErr) {
-		writeError(w, http.StatusBadRequest, shoppingValidationErr.Message)
-		return
+		return http.StatusBadRequest, shoppingValidationErr.Message
 	}
 
 	var shoppingNotFoundErr shopping.NotFoundError
 	if errors.As(err, &shoppingNotFoundErr) {
-		writeError(w, http.StatusNotFound, shoppingNotFoundErr.Error())
-		return
+		return http.StatusNotFound, shoppingNotFoundErr.Error()
+	}
+
+	var shoppingListNotFoundErr shopping.ListNotFoundError
+	if errors.As(err, &shoppingListNotFoundErr) {
+		return http.StatusNotFound, shoppingListNotFoundErr.Error()
+	}
+
+	// Recipe errors
+	var recipeValidationErr recipe.ValidationError
+	if errors.As(err, &recipeValidationErr) {
+		return http.StatusBadRequest, recipeValidationErr.Message
+	}
+
+	var recipeNotFoundErr recipe.NotFoundError
+	if errors.As(err, &recipeNotFoundErr) {
+		return http.StatusNotFound, recipeNotFoundErr.Error()
+	}
+
+	// Meal plan errors
+	var mealplanValidationErr mealplan.ValidationError
+	if errors.As(err, &mealplanValidationErr) {
+		return http.StatusBadRequest, mealplanValidationErr.Message
+	}
+
+	var mealplanNotFoundErr mealplan.NotFoundError
+	if errors.As(err, &mealplanNotFoundErr) {
+		return http.StatusNotFound, mealplanNotFoundErr.Error()
+	}
+
+	// Pantry errors
+	var pantryValidationErr pantry.ValidationError
+	if errors.As(err, &pantryValidationErr) {
+		return http.StatusBadRequest, pantryValidationErr.Message
+	}
+
+	var pantryNotFoundErr pantry.NotFoundError
+	if errors.As(err, &pantryNotFoundErr) {
+		return http.StatusNotFound, pantryNotFoundErr.Error()
+	}
+
+	// Agenda errors
+	var agendaValidationErr agenda.ValidationError
+	if errors.As(err, &agendaValidationErr) {
+		return http.StatusBadRequest, agendaValidationErr.Message
+	}
+
+	// Tag errors
+	var tagValidationErr tag.ValidationError
+	if errors.As(err, &tagValidationErr) {
+		return http.StatusBadRequest, tagValidationErr.Message
+	}
+
+	var tagNotFoundErr tag.NotFoundError
+	if errors.As(err, &tagNotFoundErr) {
+		return http.StatusNotFound, tagNotFoundErr.Error()
+	}
+
+	var tagConflictErr tag.ConflictError
+	if errors.As(err, &tagConflictErr) {
+		return http.StatusConflict, tagConflictErr.Error()
+	}
+
+	// Palette errors
+	var paletteValidationErr palette.ValidationError
+	if errors.As(err, &paletteValidationErr) {
+		return http.StatusBadRequest, paletteValidationErr.Message
+	}
+
+	var paletteNotFoundErr palette.NotFoundError
+	if errors.As(err, &paletteNotFoundErr) {
+		return http.StatusNotFound, paletteNotFoundErr.Error()
+	}
+
+	var paletteConflictErr palette.ConflictError
+	if errors.As(err, &paletteConflictErr) {
+		return http.StatusConflict, paletteConflictErr.Message
+	}
+
+	// Attachment errors
+	var attachmentValidationErr attachment.ValidationError
+	if errors.As(err, &attachmentValidationErr) {
+		return http.StatusBadRequest, attachmentValidationErr.Message
+	}
+
+	var attachmentNotFoundErr attachment.NotFoundError
+	if errors.As(err, &attachmentNotFoundErr) {
+		return http.StatusNotFound, attachmentNotFoundErr.Error()
+	}
+
+	var attachmentNoThumbnailErr attachment.NoThumbnailError
+	if errors.As(err, &attachmentNoThumbnailErr) {
+		return http.StatusNotFound, attachmentNoThumbnailErr.Error()
+	}
+
+	var attachmentTooLargeErr attachment.TooLargeError
+	if errors.As(err, &attachmentTooLargeErr) {
+		return http.StatusRequestEntityTooLarge, attachmentTooLargeErr.Error()
+	}
+
+	var attachmentTypeErr attachment.UnsupportedTypeError
+	if errors.As(err, &attachmentTypeErr) {
+		return http.StatusUnsupportedMediaType, attachmentTypeErr.Error()
+	}
+
+	if errors.Is(err, blob.ErrNotFound) {
+		return http.StatusNotFound, "attachment content not found"
+	}
+
+	// Comment errors
+	var commentValidationErr comment.ValidationError
+	if errors.As(err, &commentValidationErr) {
+		return http.StatusBadRequest, commentValidationErr.Message
+	}
+
+	var commentNotFoundErr comment.NotFoundError
+	if errors.As(err, &commentNotFoundErr) {
+		return http.StatusNotFound, commentNotFoundErr.Error()
+	}
+
+	var commentForbiddenErr comment.ForbiddenError
+	if errors.As(err, &commentForbiddenErr) {
+		return http.StatusForbidden, commentForbiddenErr.Message
+	}
+
+	// Notification errors
+	var notificationValidationErr notification.ValidationError
+	if errors.As(err, &notificationValidationErr) {
+		return http.StatusBadRequest, notificationValidationErr.Message
+	}
+
+	var notificationNotFoundErr notification.NotFoundError
+	if errors.As(err, &notificationNotFoundErr) {
+		return http.StatusNotFound, notificationNotFoundErr.Error()
+	}
+
+	var notificationForbiddenErr notification.ForbiddenError
+	if errors.As(err, &notificationForbiddenErr) {
+		return http.StatusForbidden, notificationForbiddenErr.Message
+	}
+
+	// Project errors
+	var projectValidationErr project.ValidationError
+	if errors.As(err, &projectValidationErr) {
+		return http.StatusBadRequest, projectValidationErr.Message
+	}
+
+	var projectBlockedErr project.BlockedError
+	if errors.As(err, &projectBlockedErr) {
+		return http.StatusConflict, projectBlockedErr.Error()
+	}
+
+	var projectNotFoundErr project.NotFoundError
+	if errors.As(err, &projectNotFoundErr) {
+		return http.StatusNotFound, projectNotFoundErr.Error()
+	}
+
+	// Idempotency errors
+	var idempotencyValidationErr idempotency.ValidationError
+	if errors.As(err, &idempotencyValidationErr) {
+		return http.StatusBadRequest, idempotencyValidationErr.Message
+	}
+
+	var idempotencyConflictErr idempotency.ConflictError
+	if errors.As(err, &idempotencyConflictErr) {
+		return http.StatusUnprocessableEntity, idempotencyConflictErr.Error()
+	}
+
+	var idempotencyInProgressErr idempotency.InProgressError
+	if errors.As(err, &idempotencyInProgressErr) {
+		return http.StatusConflict, idempotencyInProgressErr.Error()
+	}
+
+	// Batch errors
+	var batchOperationErr batchOperationError
+	if errors.As(err, &batchOperationErr) {
+		return http.StatusBadRequest, batchOperationErr.Message
 	}
 
 	log.Printf("internal error: %v", err)
-	writeError(w, http.StatusInternalServerError, "internal server error")
+	return http.StatusInternalServerError, "internal server error"
 }
 
 func parseID(r *http.Request) (int64, error) {
-	idStr := chi.URLParam(r, "id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	return parseIDParam(r, "id")
+}
+
+// parseIDParam parses a numeric URL parameter other than the usual "id",
+// such as the item of a nested resource.
+func parseIDParam(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
 	if err != nil {
-		return 0, task.ErrValidation("invalid id")
+		return 0, task.ErrValidation("invalid " + name)
 	}
 	return id, nil
 }
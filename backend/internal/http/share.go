@@ -0,0 +1,220 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/stadtaev/lofam/backend/internal/wishlist"
+)
+
+func (s *Server) listWishlistShares(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	shares, err := s.wishlistService.ListShares(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, shares)
+}
+
+func (s *Server) createWishlistShare(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req wishlist.CreateShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	share, err := s.wishlistService.CreateShare(r.Context(), id, req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, share)
+}
+
+func (s *Server) revokeWishlistShare(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	shareID, err := parseIDParam(r, "shareId")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := s.wishlistService.RevokeShare(r.Context(), id, shareID); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sharePrivacy keeps share pages out of search engines and caches, and stops
+// browsers from sending the token to shops through the Referer header.
+func sharePrivacy(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+		w.Header().Set("Cache-Control", "no-store")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// getSharedWishlist serves the public view of a shared wishlist as HTML to
+// browsers and as JSON to everything else.
+func (s *Server) getSharedWishlist(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	shared, err := s.wishlistService.GetShared(r.Context(), token)
+
+	if wantsHTML(r) {
+		status := http.StatusOK
+		page := sharedPage{Token: token, Wishlist: shared}
+		if err != nil {
+			status, page.Error = errorStatus(err)
+		}
+		renderSharedPage(w, status, page)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, shared)
+}
+
+// reserveSharedWishlistItem accepts a JSON body or, from the HTML view, a
+// form post that is redirected back to the list.
+func (s *Server) reserveSharedWishlistItem(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	isForm := strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded")
+
+	var item *wishlist.SharedItem
+	itemID, err := parseIDParam(r, "itemId")
+	if err == nil {
+		var req wishlist.GuestReservationRequest
+		if isForm {
+			req.Name = r.PostFormValue("name")
+		} else if json.NewDecoder(r.Body).Decode(&req) != nil {
+			err = wishlist.ErrValidation("invalid request body")
+		}
+		if err == nil {
+			item, err = s.wishlistService.ReserveShared(r.Context(), token, itemID, req)
+		}
+	}
+
+	if isForm {
+		if err != nil {
+			page := sharedPage{Token: token}
+			status, message := errorStatus(err)
+			page.Error = message
+			page.Wishlist, _ = s.wishlistService.GetShared(r.Context(), token)
+			renderSharedPage(w, status, page)
+			return
+		}
+		http.Redirect(w, r, "/share/wishlists/"+token, http.StatusSeeOther)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+func wantsHTML(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+type sharedPage struct {
+	Token    string
+	Wishlist *wishlist.SharedWishlist
+	Error    string
+}
+
+func renderSharedPage(w http.ResponseWriter, status int, page sharedPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := sharedPageTemplate.Execute(w, page); err != nil {
+		log.Printf("failed to render shared wishlist: %v", err)
+	}
+}
+
+// formatPrice renders a price in minor units, assuming two decimal places.
+func formatPrice(cents *int64, currency string) string {
+	if cents == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%d.%02d %s", *cents/100, *cents%100, currency))
+}
+
+var sharedPageTemplate = template.Must(template.New("shared").Funcs(template.FuncMap{
+	"price": formatPrice,
+}).Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Wishlist}}{{.Wishlist.Title}}{{else}}Wishlist{{end}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+li { list-style: none; border-bottom: 1px solid #ddd; padding: 0.75rem 0; }
+img { max-width: 6rem; float: right; }
+.reserved { color: #888; }
+.error { color: #b00020; }
+</style>
+</head>
+<body>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{with .Wishlist}}
+<h1>{{.Title}}</h1>
+{{if .Content}}<p>{{.Content}}</p>{{end}}
+<ul>
+{{range .Items}}
+<li>
+{{if .ImageURL}}<img src="{{.ImageURL}}" alt="" referrerpolicy="no-referrer">{{end}}
+<strong>{{if .URL}}<a href="{{.URL}}" rel="noopener noreferrer">{{.Name}}</a>{{else}}{{.Name}}{{end}}</strong>
+{{if gt .Quantity 1}}&times; {{.Quantity}}{{end}}
+{{with price .PriceCents .Currency}}<div>{{.}}</div>{{end}}
+{{if .Reserved}}
+<div class="reserved">Already reserved</div>
+{{else}}
+<form method="post" action="/share/wishlists/{{$.Token}}/items/{{.ID}}/reserve">
+<input name="name" placeholder="Your name" required maxlength="100">
+<button type="submit">Reserve</button>
+</form>
+{{end}}
+</li>
+{{else}}
+<li>Nothing on this list yet.</li>
+{{end}}
+</ul>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,128 @@
+//go:build integration
+
+package http_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/member"
+	"github.com/stadtaev/lofam/backend/internal/wishlist"
+)
+
+func TestWishlistShareLinks(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var kid, dad member.Member
+	sendJSON(t, http.MethodPost, ts.URL+"/api/members", map[string]any{"name": "Kid"}, &kid)
+	sendJSON(t, http.MethodPost, ts.URL+"/api/members", map[string]any{"name": "Dad"}, &dad)
+
+	var list wishlist.Wishlist
+	sendJSON(t, http.MethodPost, ts.URL+"/api/wishlists", map[string]any{
+		"title": "Birthday <3", "color": "yellow", "ownerId": kid.ID,
+	}, &list)
+	var kite, ball wishlist.Item
+	itemsURL := fmt.Sprintf("%s/api/wishlists/%d/items", ts.URL, list.ID)
+	sendJSON(t, http.MethodPost, itemsURL, map[string]any{"name": "Kite"}, &kite)
+	sendJSON(t, http.MethodPost, itemsURL, map[string]any{"name": "Ball"}, &ball)
+
+	sharesURL := fmt.Sprintf("%s/api/wishlists/%d/shares", ts.URL, list.ID)
+	past := time.Now().Add(-time.Hour)
+	if status := sendJSON(t, http.MethodPost, sharesURL, map[string]any{"expiresAt": past}, nil); status != http.StatusBadRequest {
+		t.Errorf("past expiry status = %d, want %d", status, http.StatusBadRequest)
+	}
+
+	var share wishlist.Share
+	if status := sendJSON(t, http.MethodPost, sharesURL, map[string]any{}, &share); status != http.StatusCreated {
+		t.Fatalf("create share status = %d", status)
+	}
+	shareURL := ts.URL + "/share/wishlists/" + share.Token
+
+	t.Run("json view", func(t *testing.T) {
+		var got wishlist.SharedWishlist
+		if status := sendJSON(t, http.MethodGet, shareURL, nil, &got); status != http.StatusOK {
+			t.Fatalf("status = %d, want %d", status, http.StatusOK)
+		}
+		if got.Title != list.Title || len(got.Items) != 2 {
+			t.Errorf("shared = %+v", got)
+		}
+	})
+
+	t.Run("guest reservation", func(t *testing.T) {
+		var got wishlist.SharedItem
+		status := sendJSON(t, http.MethodPost, fmt.Sprintf("%s/items/%d/reserve", shareURL, kite.ID), map[string]any{"name": "Grandma"}, &got)
+		if status != http.StatusOK || !got.Reserved {
+			t.Fatalf("reserve status = %d, item = %+v", status, got)
+		}
+
+		status = sendJSON(t, http.MethodPost, fmt.Sprintf("%s/items/%d/reserve", shareURL, kite.ID), map[string]any{"name": "Aunt"}, nil)
+		if status != http.StatusConflict {
+			t.Errorf("second reserve status = %d, want %d", status, http.StatusConflict)
+		}
+		status = sendJSON(t, http.MethodPost, fmt.Sprintf("%s/items/%d/reserve", shareURL, ball.ID), map[string]any{"name": " "}, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("blank name status = %d, want %d", status, http.StatusBadRequest)
+		}
+
+		var seenByDad, seenByKid wishlist.Item
+		sendJSONAs(t, dad.ID, http.MethodGet, fmt.Sprintf("%s/%d", itemsURL, kite.ID), nil, &seenByDad)
+		sendJSONAs(t, kid.ID, http.MethodGet, fmt.Sprintf("%s/%d", itemsURL, kite.ID), nil, &seenByKid)
+		if seenByDad.Reservation == nil || seenByDad.Reservation.GuestName != "Grandma" {
+			t.Errorf("dad sees reservation %+v, want Grandma's", seenByDad.Reservation)
+		}
+		if seenByKid.Reservation != nil {
+			t.Errorf("owner sees reservation %+v", seenByKid.Reservation)
+		}
+	})
+
+	t.Run("html view", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, shareURL, nil)
+		req.Header.Set("Accept", "text/html")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+			t.Errorf("content type = %q", ct)
+		}
+		if rp := resp.Header.Get("Referrer-Policy"); rp != "no-referrer" {
+			t.Errorf("Referrer-Policy = %q, want no-referrer", rp)
+		}
+		if !strings.Contains(string(body), "Birthday &lt;3") || !strings.Contains(string(body), "Already reserved") {
+			t.Errorf("unexpected page:\n%s", body)
+		}
+	})
+
+	t.Run("form reservation redirects", func(t *testing.T) {
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}}
+		resp, err := client.PostForm(fmt.Sprintf("%s/items/%d/reserve", shareURL, ball.ID), url.Values{"name": {"Uncle"}})
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusSeeOther {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusSeeOther)
+		}
+	})
+
+	t.Run("revoked link", func(t *testing.T) {
+		status := sendJSON(t, http.MethodDelete, fmt.Sprintf("%s/%d", sharesURL, share.ID), nil, nil)
+		if status != http.StatusNoContent {
+			t.Fatalf("revoke status = %d", status)
+		}
+		if status := sendJSON(t, http.MethodGet, shareURL, nil, nil); status != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+}
@@ -1,14 +1,40 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
 	"github.com/stadtaev/lofam/backend/internal/shopping"
 )
 
+type clearCheckedResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
+// shoppingFilter reads the ?tag= item filter.
+func shoppingFilter(r *http.Request) shopping.Filter {
+	return shopping.Filter{Tag: r.URL.Query().Get("tag")}
+}
+
+// groupByAisle reports whether the client asked for items grouped by category
+// with ?groupBy=category.
+func groupByAisle(r *http.Request) bool {
+	return r.URL.Query().Get("groupBy") == "category"
+}
+
 func (s *Server) listShoppingItems(w http.ResponseWriter, r *http.Request) {
-	items, err := s.shoppingService.List(r.Context())
+	if groupByAisle(r) {
+		l, err := s.shoppingService.DefaultList(r.Context())
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		s.writeShoppingAisles(w, r, l.ID)
+		return
+	}
+
+	items, err := s.shoppingService.List(r.Context(), shoppingFilter(r))
 	if err != nil {
 		handleError(w, err)
 		return
@@ -33,6 +59,50 @@ func (s *Server) createShoppingItem(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, item)
 }
 
+func (s *Server) getShoppingItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	item, err := s.shoppingService.GetByID(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, item)
+}
+
+func (s *Server) updateShoppingItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req shopping.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	// Checking an item off also records the purchase and stocks the pantry.
+	var item *shopping.Item
+	err = s.tx.WithinTx(r.Context(), func(ctx context.Context) error {
+		var err error
+		item, err = s.shoppingService.Update(ctx, id, req)
+		return err
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, item)
+}
+
 func (s *Server) deleteShoppingItem(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r)
 	if err != nil {
@@ -47,3 +117,186 @@ func (s *Server) deleteShoppingItem(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (s *Server) clearCheckedShoppingItems(w http.ResponseWriter, r *http.Request) {
+	deleted, err := s.shoppingService.ClearChecked(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, clearCheckedResponse{Deleted: deleted})
+}
+
+func (s *Server) listShoppingLists(w http.ResponseWriter, r *http.Request) {
+	lists, err := s.shoppingService.Lists(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, lists)
+}
+
+func (s *Server) createShoppingList(w http.ResponseWriter, r *http.Request) {
+	var req shopping.CreateListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	l, err := s.shoppingService.CreateList(r.Context(), req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, l)
+}
+
+func (s *Server) getShoppingList(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	l, err := s.shoppingService.GetList(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, l)
+}
+
+func (s *Server) updateShoppingList(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req shopping.UpdateListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	l, err := s.shoppingService.UpdateList(r.Context(), id, req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, l)
+}
+
+func (s *Server) deleteShoppingList(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := s.shoppingService.DeleteList(r.Context(), id); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listShoppingListItems(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if groupByAisle(r) {
+		s.writeShoppingAisles(w, r, id)
+		return
+	}
+
+	items, err := s.shoppingService.ListItems(r.Context(), id, shoppingFilter(r))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (s *Server) writeShoppingAisles(w http.ResponseWriter, r *http.Request, listID int64) {
+	groups, err := s.shoppingService.Aisles(r.Context(), listID, shoppingFilter(r))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, groups)
+}
+
+func (s *Server) createShoppingListItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req shopping.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.ListID = &id
+
+	item, err := s.shoppingService.Create(r.Context(), req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, item)
+}
+
+func (s *Server) clearCheckedShoppingListItems(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	deleted, err := s.shoppingService.ClearCheckedList(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, clearCheckedResponse{Deleted: deleted})
+}
+
+func (s *Server) listShoppingCategories(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, shopping.DefaultAisleOrder)
+}
+
+func (s *Server) listShoppingSuggestions(w http.ResponseWriter, r *http.Request) {
+	suggestions, err := s.shoppingService.Suggestions(r.Context(), r.URL.Query().Get("prefix"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, suggestions)
+}
+
+func (s *Server) listShoppingStaples(w http.ResponseWriter, r *http.Request) {
+	staples, err := s.shoppingService.Staples(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, staples)
+}
@@ -0,0 +1,346 @@
+//go:build integration
+
+package http_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/shopping"
+	"github.com/stadtaev/lofam/backend/internal/sqlite"
+)
+
+func TestShoppingItems(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var milk shopping.Item
+	status := sendJSON(t, http.MethodPost, ts.URL+"/api/shopping", map[string]any{
+		"title": "Milk", "quantity": 2, "unit": "l", "note": "semi-skimmed",
+	}, &milk)
+	if status != http.StatusCreated {
+		t.Fatalf("create status = %d", status)
+	}
+	if milk.Quantity != 2 || milk.Unit != "l" || milk.Note != "semi-skimmed" || milk.Checked || milk.CheckedAt != nil {
+		t.Errorf("milk = %+v", milk)
+	}
+
+	var bread shopping.Item
+	sendJSON(t, http.MethodPost, ts.URL+"/api/shopping", map[string]any{"title": "Bread"}, &bread)
+	if bread.Quantity != 1 {
+		t.Errorf("bread quantity = %v, want the default of 1", bread.Quantity)
+	}
+	milkURL := fmt.Sprintf("%s/api/shopping/%d", ts.URL, milk.ID)
+
+	t.Run("validation", func(t *testing.T) {
+		if status := sendJSON(t, http.MethodPost, ts.URL+"/api/shopping", map[string]any{"title": ""}, nil); status != http.StatusBadRequest {
+			t.Errorf("empty title: status = %d, want %d", status, http.StatusBadRequest)
+		}
+		if status := sendJSON(t, http.MethodPost, ts.URL+"/api/shopping", map[string]any{"title": "x", "quantity": -1}, nil); status != http.StatusBadRequest {
+			t.Errorf("negative quantity: status = %d, want %d", status, http.StatusBadRequest)
+		}
+		if status := sendJSON(t, http.MethodPut, milkURL, map[string]any{"quantity": 0}, nil); status != http.StatusBadRequest {
+			t.Errorf("zero quantity update: status = %d, want %d", status, http.StatusBadRequest)
+		}
+		if status := sendJSON(t, http.MethodPut, ts.URL+"/api/shopping/99999", map[string]any{"checked": true}, nil); status != http.StatusNotFound {
+			t.Errorf("unknown item: status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("partial update", func(t *testing.T) {
+		var updated shopping.Item
+		if status := sendJSON(t, http.MethodPut, milkURL, map[string]any{"note": "oat if none"}, &updated); status != http.StatusOK {
+			t.Fatalf("status = %d", status)
+		}
+		if updated.Note != "oat if none" || updated.Quantity != 2 || updated.Unit != "l" || updated.Title != "Milk" {
+			t.Errorf("updated = %+v, want only the note changed", updated)
+		}
+	})
+
+	t.Run("check and uncheck", func(t *testing.T) {
+		var checked shopping.Item
+		sendJSON(t, http.MethodPut, milkURL, map[string]any{"checked": true}, &checked)
+		if !checked.Checked || checked.CheckedAt == nil {
+			t.Errorf("checked = %+v", checked)
+		}
+
+		var items []shopping.Item
+		sendJSON(t, http.MethodGet, ts.URL+"/api/shopping", nil, &items)
+		if len(items) != 2 || items[1].ID != milk.ID {
+			t.Errorf("items = %+v, want checked items last", items)
+		}
+
+		var unchecked shopping.Item
+		sendJSON(t, http.MethodPut, milkURL, map[string]any{"checked": false}, &unchecked)
+		if unchecked.Checked || unchecked.CheckedAt != nil {
+			t.Errorf("unchecked = %+v", unchecked)
+		}
+	})
+
+	t.Run("clear checked", func(t *testing.T) {
+		sendJSON(t, http.MethodPut, milkURL, map[string]any{"checked": true}, nil)
+
+		var cleared struct {
+			Deleted int64 `json:"deleted"`
+		}
+		if status := sendJSON(t, http.MethodPost, ts.URL+"/api/shopping/clear-checked", nil, &cleared); status != http.StatusOK {
+			t.Fatalf("status = %d", status)
+		}
+		if cleared.Deleted != 1 {
+			t.Errorf("deleted = %d, want 1", cleared.Deleted)
+		}
+
+		var items []shopping.Item
+		sendJSON(t, http.MethodGet, ts.URL+"/api/shopping", nil, &items)
+		if len(items) != 1 || items[0].ID != bread.ID {
+			t.Errorf("items = %+v, want only the unchecked bread", items)
+		}
+	})
+}
+
+func TestShoppingLists(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var lists []shopping.List
+	sendJSON(t, http.MethodGet, ts.URL+"/api/shopping/lists", nil, &lists)
+	if len(lists) != 1 || !lists[0].IsDefault {
+		t.Fatalf("lists = %+v, want just the default list", lists)
+	}
+	defaultList := lists[0]
+
+	var pharmacy shopping.List
+	status := sendJSON(t, http.MethodPost, ts.URL+"/api/shopping/lists", map[string]any{
+		"name": "Pharmacy", "icon": "pill", "sortOrder": 1,
+	}, &pharmacy)
+	if status != http.StatusCreated {
+		t.Fatalf("create list status = %d", status)
+	}
+	if pharmacy.IsDefault || pharmacy.Icon != "pill" {
+		t.Errorf("pharmacy = %+v", pharmacy)
+	}
+	pharmacyURL := fmt.Sprintf("%s/api/shopping/lists/%d", ts.URL, pharmacy.ID)
+
+	var plasters, bread shopping.Item
+	if status := sendJSON(t, http.MethodPost, pharmacyURL+"/items", map[string]any{"title": "Plasters"}, &plasters); status != http.StatusCreated {
+		t.Fatalf("create list item status = %d", status)
+	}
+	sendJSON(t, http.MethodPost, ts.URL+"/api/shopping", map[string]any{"title": "Bread"}, &bread)
+	if plasters.ListID != pharmacy.ID || bread.ListID != defaultList.ID {
+		t.Errorf("plasters in list %d, bread in list %d", plasters.ListID, bread.ListID)
+	}
+
+	t.Run("default list aliases /api/shopping", func(t *testing.T) {
+		var plain, listed []shopping.Item
+		sendJSON(t, http.MethodGet, ts.URL+"/api/shopping", nil, &plain)
+		sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/shopping/lists/%d/items", ts.URL, defaultList.ID), nil, &listed)
+		if len(plain) != 1 || len(listed) != 1 || plain[0].ID != bread.ID || listed[0].ID != bread.ID {
+			t.Errorf("/api/shopping = %+v, default list = %+v, want only bread in both", plain, listed)
+		}
+
+		var items []shopping.Item
+		sendJSON(t, http.MethodGet, pharmacyURL+"/items", nil, &items)
+		if len(items) != 1 || items[0].ID != plasters.ID {
+			t.Errorf("pharmacy items = %+v", items)
+		}
+	})
+
+	t.Run("move item between lists", func(t *testing.T) {
+		var moved shopping.Item
+		sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/shopping/%d", ts.URL, bread.ID), map[string]any{"listId": pharmacy.ID}, &moved)
+		if moved.ListID != pharmacy.ID {
+			t.Errorf("moved = %+v", moved)
+		}
+
+		var got shopping.List
+		sendJSON(t, http.MethodGet, pharmacyURL, nil, &got)
+		if got.PendingCount != 2 {
+			t.Errorf("pending count = %d, want 2", got.PendingCount)
+		}
+
+		status := sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/shopping/%d", ts.URL, bread.ID), map[string]any{"listId": 99999}, nil)
+		if status != http.StatusNotFound {
+			t.Errorf("move to unknown list: status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("update list", func(t *testing.T) {
+		var updated shopping.List
+		sendJSON(t, http.MethodPut, pharmacyURL, map[string]any{"name": "Chemist"}, &updated)
+		if updated.Name != "Chemist" || updated.Icon != "pill" {
+			t.Errorf("updated = %+v, want only the name changed", updated)
+		}
+		if status := sendJSON(t, http.MethodPut, pharmacyURL, map[string]any{"name": ""}, nil); status != http.StatusBadRequest {
+			t.Errorf("empty name: status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		if status := sendJSON(t, http.MethodPost, ts.URL+"/api/shopping/lists", map[string]any{"name": ""}, nil); status != http.StatusBadRequest {
+			t.Errorf("empty name: status = %d, want %d", status, http.StatusBadRequest)
+		}
+		if status := sendJSON(t, http.MethodPost, ts.URL+"/api/shopping", map[string]any{"title": "x", "listId": 99999}, nil); status != http.StatusNotFound {
+			t.Errorf("item in unknown list: status = %d, want %d", status, http.StatusNotFound)
+		}
+		if status := sendJSON(t, http.MethodGet, ts.URL+"/api/shopping/lists/99999/items", nil, nil); status != http.StatusNotFound {
+			t.Errorf("items of unknown list: status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		status := sendJSON(t, http.MethodDelete, fmt.Sprintf("%s/api/shopping/lists/%d", ts.URL, defaultList.ID), nil, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("delete default list: status = %d, want %d", status, http.StatusBadRequest)
+		}
+		if status := sendJSON(t, http.MethodDelete, pharmacyURL, nil, nil); status != http.StatusNoContent {
+			t.Fatalf("delete list status = %d", status)
+		}
+		if status := sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/shopping/%d", ts.URL, plasters.ID), nil, nil); status != http.StatusNotFound {
+			t.Errorf("item of deleted list: status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+}
+
+func TestShoppingAisles(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	create := func(body map[string]any) shopping.Item {
+		t.Helper()
+		var item shopping.Item
+		if status := sendJSON(t, http.MethodPost, ts.URL+"/api/shopping", body, &item); status != http.StatusCreated {
+			t.Fatalf("create %v: status = %d", body["title"], status)
+		}
+		return item
+	}
+
+	bananas := create(map[string]any{"title": "Bananas"})
+	milk := create(map[string]any{"title": "Milk"})
+	rolls := create(map[string]any{"title": "Toilet roll"})
+	gift := create(map[string]any{"title": "Birthday card", "category": "household"})
+	for item, want := range map[*shopping.Item]shopping.Category{
+		&bananas: shopping.CategoryProduce,
+		&milk:    shopping.CategoryDairy,
+		&rolls:   shopping.CategoryHousehold,
+		&gift:    shopping.CategoryHousehold,
+	} {
+		if item.Category != want {
+			t.Errorf("%s category = %s, want %s", item.Title, item.Category, want)
+		}
+	}
+
+	t.Run("categories", func(t *testing.T) {
+		var categories []shopping.Category
+		sendJSON(t, http.MethodGet, ts.URL+"/api/shopping/categories", nil, &categories)
+		if len(categories) != len(shopping.DefaultAisleOrder) || categories[0] != shopping.CategoryProduce {
+			t.Errorf("categories = %v", categories)
+		}
+		if status := sendJSON(t, http.MethodPost, ts.URL+"/api/shopping", map[string]any{"title": "x", "category": "toys"}, nil); status != http.StatusBadRequest {
+			t.Errorf("unknown category: status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("learns from re-categorising", func(t *testing.T) {
+		sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/shopping/%d", ts.URL, milk.ID), map[string]any{"category": "frozen"}, nil)
+		again := create(map[string]any{"title": "  MILK "})
+		if again.Category != shopping.CategoryFrozen {
+			t.Errorf("category = %s, want the learned %s", again.Category, shopping.CategoryFrozen)
+		}
+		sendJSON(t, http.MethodDelete, fmt.Sprintf("%s/api/shopping/%d", ts.URL, again.ID), nil, nil)
+	})
+
+	aisles := func() []string {
+		t.Helper()
+		var groups []shopping.AisleGroup
+		if status := sendJSON(t, http.MethodGet, ts.URL+"/api/shopping?groupBy=category", nil, &groups); status != http.StatusOK {
+			t.Fatalf("grouped list status = %d", status)
+		}
+		var got []string
+		for _, g := range groups {
+			got = append(got, fmt.Sprintf("%s:%d", g.Category, len(g.Items)))
+		}
+		return got
+	}
+
+	t.Run("default aisle order", func(t *testing.T) {
+		if got := fmt.Sprint(aisles()); got != "[produce:1 frozen:1 household:2]" {
+			t.Errorf("aisles = %s", got)
+		}
+	})
+
+	t.Run("store aisle order", func(t *testing.T) {
+		var lists []shopping.List
+		sendJSON(t, http.MethodGet, ts.URL+"/api/shopping/lists", nil, &lists)
+		listURL := fmt.Sprintf("%s/api/shopping/lists/%d", ts.URL, lists[0].ID)
+
+		status := sendJSON(t, http.MethodPut, listURL, map[string]any{"aisleOrder": []string{"household", "frozen"}}, nil)
+		if status != http.StatusOK {
+			t.Fatalf("update aisle order status = %d", status)
+		}
+		if got := fmt.Sprint(aisles()); got != "[household:2 frozen:1 produce:1]" {
+			t.Errorf("aisles = %s", got)
+		}
+
+		for name, order := range map[string][]string{
+			"unknown category":  {"toys"},
+			"repeated category": {"frozen", "frozen"},
+		} {
+			if status := sendJSON(t, http.MethodPut, listURL, map[string]any{"aisleOrder": order}, nil); status != http.StatusBadRequest {
+				t.Errorf("%s: status = %d, want %d", name, status, http.StatusBadRequest)
+			}
+		}
+	})
+}
+
+func TestShoppingMigrationCategorizesOther(t *testing.T) {
+	db, err := sqlite.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	// Stand in for a database from before the backfill, with items saved
+	// before their words were in the dictionary and one the household
+	// filed under other by hand.
+	if _, err := db.Exec(`
+		INSERT INTO shopping_items (title, category) VALUES ('Toilet roll', 'other'), ('Pens', 'other'), ('Bread', 'other');
+		INSERT INTO shopping_learned_categories (term, category) VALUES ('bread', 'other');
+		PRAGMA user_version = 0;
+	`); err != nil {
+		t.Fatalf("failed to set up legacy data: %v", err)
+	}
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("failed to migrate again: %v", err)
+	}
+
+	want := map[string]string{"Toilet roll": "household", "Pens": "other", "Bread": "other"}
+	for title, category := range want {
+		var got string
+		if err := db.QueryRow(`SELECT category FROM shopping_items WHERE title = ?`, title).Scan(&got); err != nil {
+			t.Fatalf("failed to read %s: %v", title, err)
+		}
+		if got != category {
+			t.Errorf("%s category = %s, want %s", title, got, category)
+		}
+	}
+
+	t.Run("runs once", func(t *testing.T) {
+		if _, err := db.Exec(`INSERT INTO shopping_items (title, category) VALUES ('Kitchen roll', 'other')`); err != nil {
+			t.Fatalf("failed to add item: %v", err)
+		}
+		if err := db.Migrate(); err != nil {
+			t.Fatalf("failed to migrate again: %v", err)
+		}
+		var got string
+		if err := db.QueryRow(`SELECT category FROM shopping_items WHERE title = 'Kitchen roll'`).Scan(&got); err != nil {
+			t.Fatalf("failed to read item: %v", err)
+		}
+		if got != "other" {
+			t.Errorf("category = %s, want the backfill not to run again", got)
+		}
+	})
+}
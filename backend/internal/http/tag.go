@@ -0,0 +1,165 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/stadtaev/lofam/backend/internal/tag"
+)
+
+type tagsResponse struct {
+	Tags []string `json:"tags"`
+}
+
+func (s *Server) listTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := s.tagService.List(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tags)
+}
+
+func (s *Server) createTag(w http.ResponseWriter, r *http.Request) {
+	var req tag.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	t, err := s.tagService.Create(r.Context(), req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, t)
+}
+
+func (s *Server) getTag(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	t, err := s.tagService.GetByID(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) updateTag(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req tag.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	t, err := s.tagService.Update(r.Context(), id, req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) deleteTag(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := s.tagService.Delete(r.Context(), id); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) mergeTag(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req tag.MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	t, err := s.tagService.Merge(r.Context(), id, req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, t)
+}
+
+// setTags returns a handler that replaces the tags of the entity in the
+// {id} URL parameter. exists reports the entity's own not-found error.
+func (s *Server) setTags(kind tag.Kind, exists func(ctx context.Context, id int64) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		var req tag.SetRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		if err := exists(r.Context(), id); err != nil {
+			handleError(w, err)
+			return
+		}
+
+		tags, err := s.tagService.SetTags(r.Context(), kind, id, req)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, tagsResponse{Tags: tags})
+	}
+}
+
+func (s *Server) taskExists(ctx context.Context, id int64) error {
+	_, err := s.taskService.GetByID(ctx, id)
+	return err
+}
+
+func (s *Server) noteExists(ctx context.Context, id int64) error {
+	_, err := s.noteService.GetByID(ctx, id)
+	return err
+}
+
+func (s *Server) wishlistExists(ctx context.Context, id int64) error {
+	_, err := s.wishlistService.GetByID(ctx, id)
+	return err
+}
+
+func (s *Server) shoppingItemExists(ctx context.Context, id int64) error {
+	_, err := s.shoppingService.GetByID(ctx, id)
+	return err
+}
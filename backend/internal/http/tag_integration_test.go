@@ -0,0 +1,133 @@
+//go:build integration
+
+package http_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/note"
+	"github.com/stadtaev/lofam/backend/internal/shopping"
+	"github.com/stadtaev/lofam/backend/internal/tag"
+	"github.com/stadtaev/lofam/backend/internal/task"
+	"github.com/stadtaev/lofam/backend/internal/wishlist"
+)
+
+func setTestTags(t *testing.T, url string, tags ...string) []string {
+	t.Helper()
+	var resp struct {
+		Tags []string `json:"tags"`
+	}
+	if status := sendJSON(t, http.MethodPut, url+"/tags", map[string]any{"tags": tags}, &resp); status != http.StatusOK {
+		t.Fatalf("set tags on %s status = %d", url, status)
+	}
+	return resp.Tags
+}
+
+func findTag(t *testing.T, baseURL, name string) tag.Tag {
+	t.Helper()
+	var tags []tag.Tag
+	sendJSON(t, http.MethodGet, baseURL+"/api/tags", nil, &tags)
+	for _, tg := range tags {
+		if tg.Name == name {
+			return tg
+		}
+	}
+	t.Fatalf("tag %q not found in %+v", name, tags)
+	return tag.Tag{}
+}
+
+func TestTags(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	schoolTask := createTestTask(t, ts.URL, "Pack lunch")
+	otherTask := createTestTask(t, ts.URL, "Water plants")
+	var n note.Note
+	sendJSON(t, http.MethodPost, ts.URL+"/api/notes", map[string]any{"title": "Term dates", "color": "yellow"}, &n)
+	list := createTestWishlist(t, ts.URL, "Car accessories")
+	var item shopping.Item
+	sendJSON(t, http.MethodPost, ts.URL+"/api/shopping", map[string]any{"title": "Pencils"}, &item)
+
+	got := setTestTags(t, fmt.Sprintf("%s/api/tasks/%d", ts.URL, schoolTask.ID), "school", " School ", "kids")
+	if fmt.Sprint(got) != "[kids school]" {
+		t.Errorf("task tags = %v, want [kids school]", got)
+	}
+	setTestTags(t, fmt.Sprintf("%s/api/notes/%d", ts.URL, n.ID), "School")
+	setTestTags(t, fmt.Sprintf("%s/api/wishlists/%d", ts.URL, list.ID), "car")
+	setTestTags(t, fmt.Sprintf("%s/api/shopping/%d", ts.URL, item.ID), "school")
+
+	school := findTag(t, ts.URL, "school")
+	if school.Total != 3 || school.Counts[tag.KindTask] != 1 || school.Counts[tag.KindNote] != 1 ||
+		school.Counts[tag.KindShoppingItem] != 1 || school.Counts[tag.KindWishlist] != 0 {
+		t.Errorf("school counts = %v (total %d)", school.Counts, school.Total)
+	}
+
+	t.Run("filters", func(t *testing.T) {
+		var tasks []task.Task
+		sendJSON(t, http.MethodGet, ts.URL+"/api/tasks?tag=SCHOOL", nil, &tasks)
+		if len(tasks) != 1 || tasks[0].ID != schoolTask.ID || fmt.Sprint(tasks[0].Tags) != "[kids school]" {
+			t.Errorf("tagged tasks = %+v", tasks)
+		}
+		var notes []note.Note
+		sendJSON(t, http.MethodGet, ts.URL+"/api/notes?tag=school", nil, &notes)
+		if len(notes) != 1 {
+			t.Errorf("tagged notes = %+v", notes)
+		}
+		var lists []wishlist.Wishlist
+		sendJSON(t, http.MethodGet, ts.URL+"/api/wishlists?tag=school", nil, &lists)
+		if len(lists) != 0 {
+			t.Errorf("wishlists tagged school = %+v", lists)
+		}
+		var items []shopping.Item
+		sendJSON(t, http.MethodGet, ts.URL+"/api/shopping?tag=school", nil, &items)
+		if len(items) != 1 {
+			t.Errorf("tagged shopping items = %+v", items)
+		}
+		sendJSON(t, http.MethodGet, ts.URL+"/api/tasks", nil, &tasks)
+		if len(tasks) != 2 {
+			t.Errorf("unfiltered tasks = %d, want 2", len(tasks))
+		}
+	})
+
+	t.Run("rename conflict", func(t *testing.T) {
+		status := sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/tags/%d", ts.URL, school.ID),
+			map[string]any{"name": "Car"}, nil)
+		if status != http.StatusConflict {
+			t.Errorf("status = %d, want %d", status, http.StatusConflict)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		kids := findTag(t, ts.URL, "kids")
+		setTestTags(t, fmt.Sprintf("%s/api/tasks/%d", ts.URL, otherTask.ID), "kids")
+
+		var merged tag.Tag
+		status := sendJSON(t, http.MethodPost, fmt.Sprintf("%s/api/tags/%d/merge", ts.URL, kids.ID),
+			map[string]any{"intoId": school.ID}, &merged)
+		if status != http.StatusOK {
+			t.Fatalf("merge status = %d", status)
+		}
+		if merged.Counts[tag.KindTask] != 2 || merged.Total != 4 {
+			t.Errorf("merged counts = %v (total %d)", merged.Counts, merged.Total)
+		}
+		if status := sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/tags/%d", ts.URL, kids.ID), nil, nil); status != http.StatusNotFound {
+			t.Errorf("merged-away tag status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("deleting an entity drops its tags", func(t *testing.T) {
+		sendJSON(t, http.MethodDelete, fmt.Sprintf("%s/api/notes/%d", ts.URL, n.ID), nil, nil)
+		if got := findTag(t, ts.URL, "school").Counts[tag.KindNote]; got != 0 {
+			t.Errorf("note count = %d, want 0", got)
+		}
+	})
+
+	t.Run("unknown entity", func(t *testing.T) {
+		status := sendJSON(t, http.MethodPut, ts.URL+"/api/tasks/99999/tags", map[string]any{"tags": []string{"x"}}, nil)
+		if status != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", status, http.StatusNotFound)
+		}
+	})
+}
@@ -1,14 +1,27 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/stadtaev/lofam/backend/internal/attachment"
 	"github.com/stadtaev/lofam/backend/internal/task"
 )
 
+// listTasks returns all tasks, narrowed by ?tag=, ?projectId= and
+// ?assigneeId=. ?from= and ?to= select the tasks scheduled in that range,
+// in order of their start.
 func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
-	tasks, err := s.taskService.List(r.Context())
+	filter, err := parseTaskFilter(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	tasks, err := s.taskService.List(r.Context(), filter)
 	if err != nil {
 		handleError(w, err)
 		return
@@ -17,6 +30,54 @@ func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, tasks)
 }
 
+// listTaskConflicts returns overlapping tasks of the same member, taking
+// the same query parameters as listTasks.
+func (s *Server) listTaskConflicts(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTaskFilter(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	conflicts, err := s.taskService.Conflicts(r.Context(), filter)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, conflicts)
+}
+
+func parseTaskFilter(r *http.Request) (task.Filter, error) {
+	query := r.URL.Query()
+	filter := task.Filter{Tag: query.Get("tag")}
+
+	for name, id := range map[string]*int64{"projectId": &filter.ProjectID, "assigneeId": &filter.AssigneeID} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return task.Filter{}, task.ErrValidation("invalid " + name)
+			}
+			*id = parsed
+		}
+	}
+
+	for name, bound := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return task.Filter{}, task.ErrValidation(name + " must be an RFC 3339 time")
+			}
+			*bound = &parsed
+		}
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return task.Filter{}, task.ErrValidation("to must be after from")
+	}
+
+	return filter, nil
+}
+
 func (s *Server) createTask(w http.ResponseWriter, r *http.Request) {
 	var req task.CreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -82,6 +143,82 @@ func (s *Server) deleteTask(w http.ResponseWriter, r *http.Request) {
 		handleError(w, err)
 		return
 	}
+	s.deleteOwnedAttachments(r.Context(), attachment.OwnerTask, id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// moveTask reorders a task, for drag and drop in lists and on boards.
+func (s *Server) moveTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req task.MoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var t *task.Task
+	err = s.tx.WithinTx(r.Context(), func(ctx context.Context) error {
+		var err error
+		t, err = s.taskService.Move(ctx, id, req)
+		return err
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) addTaskBlocker(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req task.AddBlockerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var t *task.Task
+	err = s.tx.WithinTx(r.Context(), func(ctx context.Context) error {
+		var err error
+		t, err = s.taskService.AddBlocker(ctx, id, req)
+		return err
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) removeTaskBlocker(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	blockerID, err := parseIDParam(r, "blockerId")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := s.taskService.RemoveBlocker(r.Context(), id, blockerID); err != nil {
+		handleError(w, err)
+		return
+	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -11,9 +11,26 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stadtaev/lofam/backend/internal/agenda"
+	"github.com/stadtaev/lofam/backend/internal/attachment"
+	"github.com/stadtaev/lofam/backend/internal/blob"
+	"github.com/stadtaev/lofam/backend/internal/comment"
 	lofamhttp "github.com/stadtaev/lofam/backend/internal/http"
+	"github.com/stadtaev/lofam/backend/internal/idempotency"
+	"github.com/stadtaev/lofam/backend/internal/mealplan"
+	"github.com/stadtaev/lofam/backend/internal/member"
+	"github.com/stadtaev/lofam/backend/internal/note"
+	"github.com/stadtaev/lofam/backend/internal/notification"
+	"github.com/stadtaev/lofam/backend/internal/occasion"
+	"github.com/stadtaev/lofam/backend/internal/palette"
+	"github.com/stadtaev/lofam/backend/internal/pantry"
+	"github.com/stadtaev/lofam/backend/internal/project"
+	"github.com/stadtaev/lofam/backend/internal/recipe"
+	"github.com/stadtaev/lofam/backend/internal/shopping"
 	"github.com/stadtaev/lofam/backend/internal/sqlite"
+	"github.com/stadtaev/lofam/backend/internal/tag"
 	"github.com/stadtaev/lofam/backend/internal/task"
+	"github.com/stadtaev/lofam/backend/internal/wishlist"
 )
 
 type wantTask struct {
@@ -70,8 +87,19 @@ func assertDueDate(t *testing.T, got *time.Time, want string) {
 	}
 }
 
+// testAttachmentMaxSize keeps the upload limit small enough to exceed
+// cheaply in tests.
+const testAttachmentMaxSize = 256 << 10
+
 func setupTestServer(t *testing.T) *httptest.Server {
 	t.Helper()
+	return setupTestServerWithLinks(t, nil)
+}
+
+// setupTestServerWithLinks is setupTestServer with a link previewer for
+// wishlist items.
+func setupTestServerWithLinks(t *testing.T, links wishlist.LinkPreviewer) *httptest.Server {
+	t.Helper()
 
 	db, err := sqlite.New(":memory:")
 	if err != nil {
@@ -86,9 +114,48 @@ func setupTestServer(t *testing.T) *httptest.Server {
 		db.Close()
 	})
 
-	store := sqlite.NewTaskStore(db)
-	service := task.NewService(store)
-	server := lofamhttp.NewServer(service)
+	projectService := project.NewService(sqlite.NewProjectStore(db))
+	memberService := member.NewService(sqlite.NewMemberStore(db))
+	taskService := task.NewService(sqlite.NewTaskStore(db), projectService, memberService)
+	paletteService := palette.NewService(sqlite.NewPaletteStore(db))
+	wishlistService := wishlist.NewService(sqlite.NewWishlistStore(db), memberService, paletteService, links)
+	// Link lookups run in the background; let them finish before the
+	// database closes.
+	t.Cleanup(wishlistService.Wait)
+	pantryService := pantry.NewService(sqlite.NewPantryStore(db))
+	shoppingService := shopping.NewService(sqlite.NewShoppingStore(db), pantryService)
+	recipeService := recipe.NewService(sqlite.NewRecipeStore(db), nil)
+	noteService := note.NewService(sqlite.NewNoteStore(db), paletteService)
+
+	blobs, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create blob store: %v", err)
+	}
+	attachmentService := attachment.NewService(sqlite.NewAttachmentStore(db), blobs, taskService, noteService, testAttachmentMaxSize)
+
+	notificationService := notification.NewService(sqlite.NewNotificationStore(db))
+
+	server := lofamhttp.NewServer(
+		taskService,
+		noteService,
+		wishlistService,
+		memberService,
+		occasion.NewService(sqlite.NewOccasionStore(db), wishlistService, memberService),
+		shoppingService,
+		recipeService,
+		mealplan.NewService(sqlite.NewMealPlanStore(db), recipeService, shoppingService),
+		pantryService,
+		agenda.NewService(taskService, pantryService),
+		tag.NewService(sqlite.NewTagStore(db)),
+		paletteService,
+		attachmentService,
+		comment.NewService(sqlite.NewCommentStore(db), taskService, memberService, notificationService),
+		notificationService,
+		projectService,
+		idempotency.NewService(sqlite.NewIdempotencyStore(db)),
+		db,
+		t.TempDir(),
+	)
 
 	return httptest.NewServer(server.Router())
 }
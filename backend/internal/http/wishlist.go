@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -8,7 +9,8 @@ import (
 )
 
 func (s *Server) listWishlists(w http.ResponseWriter, r *http.Request) {
-	items, err := s.wishlistService.List(r.Context())
+	filter := wishlist.Filter{Tag: r.URL.Query().Get("tag")}
+	items, err := s.wishlistService.List(r.Context(), filter)
 	if err != nil {
 		handleError(w, err)
 		return
@@ -85,3 +87,138 @@ func (s *Server) deleteWishlist(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (s *Server) listWishlistItems(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	items, err := s.wishlistService.ListItems(r.Context(), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (s *Server) createWishlistItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req wishlist.CreateItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	item, err := s.wishlistService.CreateItem(r.Context(), id, req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, item)
+}
+
+func (s *Server) getWishlistItem(w http.ResponseWriter, r *http.Request) {
+	id, itemID, err := parseWishlistItemID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	item, err := s.wishlistService.GetItem(r.Context(), id, itemID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, item)
+}
+
+func (s *Server) updateWishlistItem(w http.ResponseWriter, r *http.Request) {
+	id, itemID, err := parseWishlistItemID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	var req wishlist.UpdateItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	item, err := s.wishlistService.UpdateItem(r.Context(), id, itemID, req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, item)
+}
+
+func (s *Server) deleteWishlistItem(w http.ResponseWriter, r *http.Request) {
+	id, itemID, err := parseWishlistItemID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := s.wishlistService.DeleteItem(r.Context(), id, itemID); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseWishlistItemID(r *http.Request) (int64, int64, error) {
+	id, err := parseID(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	itemID, err := parseIDParam(r, "itemId")
+	if err != nil {
+		return 0, 0, err
+	}
+	return id, itemID, nil
+}
+
+func (s *Server) reserveWishlistItem(w http.ResponseWriter, r *http.Request) {
+	s.changeWishlistReservation(w, r, s.wishlistService.Reserve)
+}
+
+func (s *Server) unreserveWishlistItem(w http.ResponseWriter, r *http.Request) {
+	s.changeWishlistReservation(w, r, s.wishlistService.Unreserve)
+}
+
+func (s *Server) markWishlistItemBought(w http.ResponseWriter, r *http.Request) {
+	s.changeWishlistReservation(w, r, s.wishlistService.MarkBought)
+}
+
+func (s *Server) changeWishlistReservation(
+	w http.ResponseWriter,
+	r *http.Request,
+	change func(ctx context.Context, wishlistID, id int64) (*wishlist.Item, error),
+) {
+	id, itemID, err := parseWishlistItemID(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	item, err := change(r.Context(), id, itemID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, item)
+}
@@ -0,0 +1,302 @@
+//go:build integration
+
+package http_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/fetch"
+	"github.com/stadtaev/lofam/backend/internal/linkmeta"
+	"github.com/stadtaev/lofam/backend/internal/member"
+	"github.com/stadtaev/lofam/backend/internal/wishlist"
+)
+
+func createTestWishlist(t *testing.T, baseURL, title string) wishlist.Wishlist {
+	t.Helper()
+	var w wishlist.Wishlist
+	status := sendJSON(t, http.MethodPost, baseURL+"/api/wishlists", map[string]any{
+		"title": title, "color": "pink",
+	}, &w)
+	if status != http.StatusCreated {
+		t.Fatalf("create wishlist status = %d", status)
+	}
+	return w
+}
+
+func TestWishlistItems(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	birthday := createTestWishlist(t, ts.URL, "Birthday")
+	other := createTestWishlist(t, ts.URL, "Christmas")
+	itemsURL := fmt.Sprintf("%s/api/wishlists/%d/items", ts.URL, birthday.ID)
+
+	var lego wishlist.Item
+	status := sendJSON(t, http.MethodPost, itemsURL, map[string]any{
+		"name": "Lego set", "url": "https://example.com/lego", "priceCents": 4999, "currency": "EUR",
+	}, &lego)
+	if status != http.StatusCreated {
+		t.Fatalf("create item status = %d", status)
+	}
+	if lego.Quantity != 1 || lego.Priority != wishlist.PriorityMedium {
+		t.Errorf("defaults = quantity %d, priority %q", lego.Quantity, lego.Priority)
+	}
+
+	sendJSON(t, http.MethodPost, itemsURL, map[string]any{"name": "Bike", "priority": "high"}, nil)
+
+	tests := []struct {
+		name string
+		body map[string]any
+	}{
+		{"missing name", map[string]any{}},
+		{"bad url", map[string]any{"name": "Book", "url": "ftp://example.com"}},
+		{"bad currency", map[string]any{"name": "Book", "currency": "euro"}},
+		{"negative price", map[string]any{"name": "Book", "priceCents": -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := sendJSON(t, http.MethodPost, itemsURL, tt.body, nil); status != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", status, http.StatusBadRequest)
+			}
+		})
+	}
+
+	var got wishlist.Wishlist
+	sendJSON(t, http.MethodGet, fmt.Sprintf("%s/api/wishlists/%d", ts.URL, birthday.ID), nil, &got)
+	if got.Title != "Birthday" || got.Color != "pink" {
+		t.Errorf("wishlist = %+v", got)
+	}
+	if len(got.Items) != 2 || got.Items[0].Name != "Bike" {
+		t.Errorf("items = %+v, want Bike first", got.Items)
+	}
+
+	var updated wishlist.Item
+	sendJSON(t, http.MethodPut, fmt.Sprintf("%s/%d", itemsURL, lego.ID), map[string]any{
+		"quantity": 2, "priceCents": -1,
+	}, &updated)
+	if updated.Quantity != 2 || updated.PriceCents != nil || updated.Name != "Lego set" {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	otherItemURL := fmt.Sprintf("%s/api/wishlists/%d/items/%d", ts.URL, other.ID, lego.ID)
+	if status := sendJSON(t, http.MethodGet, otherItemURL, nil, nil); status != http.StatusNotFound {
+		t.Errorf("item via other wishlist status = %d, want %d", status, http.StatusNotFound)
+	}
+
+	sendJSON(t, http.MethodDelete, fmt.Sprintf("%s/api/wishlists/%d", ts.URL, birthday.ID), nil, nil)
+	if status := sendJSON(t, http.MethodGet, fmt.Sprintf("%s/%d", itemsURL, lego.ID), nil, nil); status != http.StatusNotFound {
+		t.Errorf("item after wishlist delete status = %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestWishlistReservations(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	var kid, mum, dad member.Member
+	sendJSON(t, http.MethodPost, ts.URL+"/api/members", map[string]any{"name": "Kid"}, &kid)
+	sendJSON(t, http.MethodPost, ts.URL+"/api/members", map[string]any{"name": "Mum"}, &mum)
+	sendJSON(t, http.MethodPost, ts.URL+"/api/members", map[string]any{"name": "Dad"}, &dad)
+
+	var list wishlist.Wishlist
+	status := sendJSON(t, http.MethodPost, ts.URL+"/api/wishlists", map[string]any{
+		"title": "Kid's birthday", "color": "green", "ownerId": kid.ID,
+	}, &list)
+	if status != http.StatusCreated {
+		t.Fatalf("create wishlist status = %d", status)
+	}
+
+	var item wishlist.Item
+	sendJSON(t, http.MethodPost, fmt.Sprintf("%s/api/wishlists/%d/items", ts.URL, list.ID), map[string]any{"name": "Scooter"}, &item)
+	itemURL := fmt.Sprintf("%s/api/wishlists/%d/items/%d", ts.URL, list.ID, item.ID)
+
+	if status := sendJSONAs(t, kid.ID, http.MethodPost, itemURL+"/reserve", nil, nil); status != http.StatusForbidden {
+		t.Errorf("owner reserve status = %d, want %d", status, http.StatusForbidden)
+	}
+	if status := sendJSON(t, http.MethodPost, itemURL+"/reserve", nil, nil); status != http.StatusForbidden {
+		t.Errorf("anonymous reserve status = %d, want %d", status, http.StatusForbidden)
+	}
+
+	var reserved wishlist.Item
+	if status := sendJSONAs(t, mum.ID, http.MethodPost, itemURL+"/reserve", nil, &reserved); status != http.StatusOK {
+		t.Fatalf("reserve status = %d, want %d", status, http.StatusOK)
+	}
+	if reserved.Reservation == nil || reserved.Reservation.MemberID == nil || *reserved.Reservation.MemberID != mum.ID {
+		t.Errorf("reservation = %+v, want reserved by mum", reserved.Reservation)
+	}
+
+	if status := sendJSONAs(t, dad.ID, http.MethodPost, itemURL+"/reserve", nil, nil); status != http.StatusConflict {
+		t.Errorf("second reserve status = %d, want %d", status, http.StatusConflict)
+	}
+	if status := sendJSONAs(t, dad.ID, http.MethodDelete, itemURL+"/reserve", nil, nil); status != http.StatusForbidden {
+		t.Errorf("foreign unreserve status = %d, want %d", status, http.StatusForbidden)
+	}
+
+	var bought wishlist.Item
+	sendJSONAs(t, mum.ID, http.MethodPost, itemURL+"/bought", nil, &bought)
+	if bought.Reservation == nil || bought.Reservation.Status != wishlist.ReservationBought {
+		t.Errorf("reservation = %+v, want bought", bought.Reservation)
+	}
+
+	// The owner and anonymous callers never see reservations, whichever
+	// endpoint they use; everyone else does.
+	views := []struct {
+		name     string
+		memberID int64
+		visible  bool
+	}{
+		{"owner", kid.ID, false},
+		{"anonymous", 0, false},
+		{"other member", dad.ID, true},
+	}
+	for _, v := range views {
+		t.Run(v.name, func(t *testing.T) {
+			var lists []wishlist.Wishlist
+			sendJSONAs(t, v.memberID, http.MethodGet, ts.URL+"/api/wishlists", nil, &lists)
+			var single wishlist.Wishlist
+			sendJSONAs(t, v.memberID, http.MethodGet, fmt.Sprintf("%s/api/wishlists/%d", ts.URL, list.ID), nil, &single)
+			var items []wishlist.Item
+			sendJSONAs(t, v.memberID, http.MethodGet, fmt.Sprintf("%s/api/wishlists/%d/items", ts.URL, list.ID), nil, &items)
+			var one wishlist.Item
+			sendJSONAs(t, v.memberID, http.MethodGet, itemURL, nil, &one)
+
+			for name, got := range map[string]*wishlist.Reservation{
+				"list":  lists[0].Items[0].Reservation,
+				"get":   single.Items[0].Reservation,
+				"items": items[0].Reservation,
+				"item":  one.Reservation,
+			} {
+				if (got != nil) != v.visible {
+					t.Errorf("%s: reservation = %+v, want visible %v", name, got, v.visible)
+				}
+			}
+		})
+	}
+
+	t.Run("rename keeps owner", func(t *testing.T) {
+		listURL := fmt.Sprintf("%s/api/wishlists/%d", ts.URL, list.ID)
+		var renamed wishlist.Wishlist
+		sendJSONAs(t, kid.ID, http.MethodPut, listURL, map[string]any{"title": "Kid's 8th birthday", "color": "green"}, &renamed)
+		if renamed.OwnerID == nil || *renamed.OwnerID != kid.ID {
+			t.Errorf("ownerId = %v, want %d", renamed.OwnerID, kid.ID)
+		}
+		if renamed.Items[0].Reservation != nil {
+			t.Errorf("owner sees reservation %+v after rename", renamed.Items[0].Reservation)
+		}
+	})
+
+	var released wishlist.Item
+	sendJSONAs(t, mum.ID, http.MethodDelete, itemURL+"/reserve", nil, &released)
+	if released.Reservation != nil {
+		t.Errorf("reservation after unreserve = %+v, want nil", released.Reservation)
+	}
+
+	t.Run("release guest reservation", func(t *testing.T) {
+		var share wishlist.Share
+		sendJSON(t, http.MethodPost, fmt.Sprintf("%s/api/wishlists/%d/shares", ts.URL, list.ID), map[string]any{}, &share)
+		guestURL := fmt.Sprintf("%s/share/wishlists/%s/items/%d/reserve", ts.URL, share.Token, item.ID)
+		if status := sendJSON(t, http.MethodPost, guestURL, map[string]any{"name": "Grandma"}, nil); status != http.StatusOK {
+			t.Fatalf("guest reserve status = %d, want %d", status, http.StatusOK)
+		}
+
+		if status := sendJSONAs(t, kid.ID, http.MethodDelete, itemURL+"/reserve", nil, nil); status != http.StatusForbidden {
+			t.Errorf("owner unreserve status = %d, want %d", status, http.StatusForbidden)
+		}
+		var got wishlist.Item
+		if status := sendJSONAs(t, dad.ID, http.MethodDelete, itemURL+"/reserve", nil, &got); status != http.StatusOK {
+			t.Fatalf("unreserve status = %d, want %d", status, http.StatusOK)
+		}
+		if got.Reservation != nil {
+			t.Errorf("reservation after unreserve = %+v, want nil", got.Reservation)
+		}
+	})
+
+	t.Run("clear owner", func(t *testing.T) {
+		var shared wishlist.Wishlist
+		sendJSON(t, http.MethodPut, fmt.Sprintf("%s/api/wishlists/%d", ts.URL, list.ID), map[string]any{
+			"title": "Kid's birthday", "color": "green", "ownerId": 0,
+		}, &shared)
+		if shared.OwnerID != nil {
+			t.Errorf("ownerId = %v, want nil", *shared.OwnerID)
+		}
+	})
+}
+
+func TestWishlistItemLinkMetadata(t *testing.T) {
+	// Lookups of /slow wait until the test has edited the item.
+	release := make(chan struct{})
+	var releaseOnce sync.Once
+	shop := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		w.Write([]byte(`<html><head>
+			<meta property="og:title" content="Telescope">
+			<meta property="og:image" content="https://cdn.example.com/telescope.jpg">
+			<meta property="product:price:amount" content="199.00">
+			<meta property="product:price:currency" content="EUR">
+		</head></html>`))
+	}))
+	defer shop.Close()
+	defer releaseOnce.Do(func() { close(release) })
+
+	links := linkmeta.NewExtractor(fetch.NewHTTPFetcher(fetch.AllowPrivateNetworks()))
+	ts := setupTestServerWithLinks(t, links)
+	defer ts.Close()
+
+	list := createTestWishlist(t, ts.URL, "Birthday")
+	itemsURL := fmt.Sprintf("%s/api/wishlists/%d/items", ts.URL, list.ID)
+
+	var created wishlist.Item
+	status := sendJSON(t, http.MethodPost, itemsURL, map[string]any{"url": shop.URL + "/telescope"}, &created)
+	if status != http.StatusCreated {
+		t.Fatalf("create item status = %d", status)
+	}
+
+	var got wishlist.Item
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		sendJSON(t, http.MethodGet, fmt.Sprintf("%s/%d", itemsURL, created.ID), nil, &got)
+		if got.Name != created.URL {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got.Name != "Telescope" || got.ImageURL != "https://cdn.example.com/telescope.jpg" {
+		t.Errorf("item = %+v, want details from the linked page", got)
+	}
+	if got.PriceCents == nil || *got.PriceCents != 19900 || got.Currency != "EUR" {
+		t.Errorf("price = %v %q, want 19900 EUR", got.PriceCents, got.Currency)
+	}
+
+	t.Run("edit during lookup", func(t *testing.T) {
+		var slow wishlist.Item
+		sendJSON(t, http.MethodPost, itemsURL, map[string]any{"url": shop.URL + "/slow"}, &slow)
+		itemURL := fmt.Sprintf("%s/%d", itemsURL, slow.ID)
+		sendJSON(t, http.MethodPut, itemURL, map[string]any{"name": "Dad's telescope", "priceCents": 15000, "currency": "GBP"}, nil)
+		releaseOnce.Do(func() { close(release) })
+
+		var got wishlist.Item
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			sendJSON(t, http.MethodGet, itemURL, nil, &got)
+			if got.ImageURL != "" {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if got.ImageURL != "https://cdn.example.com/telescope.jpg" {
+			t.Errorf("imageUrl = %q, want it from the linked page", got.ImageURL)
+		}
+		if got.Name != "Dad's telescope" || got.PriceCents == nil || *got.PriceCents != 15000 || got.Currency != "GBP" {
+			t.Errorf("item = %+v, want the edit kept", got)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package idempotency
+
+import "fmt"
+
+type ValidationError struct {
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Message
+}
+
+func ErrValidation(msg string) ValidationError {
+	return ValidationError{Message: msg}
+}
+
+type ConflictError struct {
+	Key string
+}
+
+func (e ConflictError) Error() string {
+	return fmt.Sprintf("idempotency key %q was already used with a different request", e.Key)
+}
+
+func ErrConflict(key string) ConflictError {
+	return ConflictError{Key: key}
+}
+
+type InProgressError struct {
+	Key string
+}
+
+func (e InProgressError) Error() string {
+	return fmt.Sprintf("a request with idempotency key %q is still in progress", e.Key)
+}
+
+func ErrInProgress(key string) InProgressError {
+	return InProgressError{Key: key}
+}
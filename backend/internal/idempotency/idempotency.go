@@ -0,0 +1,34 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// TTL is how long a stored response can be replayed for the same key.
+const TTL = 24 * time.Hour
+
+type Record struct {
+	Key         string
+	RequestHash string
+	StatusCode  int
+	Body        []byte
+	CreatedAt   time.Time
+}
+
+// HashRequest fingerprints a request so that reusing a key with a different
+// method, path, member or body can be detected. memberID is 0 for
+// anonymous requests.
+func HashRequest(method, path string, memberID int64, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatInt(memberID, 10)))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
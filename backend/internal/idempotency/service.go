@@ -0,0 +1,72 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const maxKeyLength = 255
+
+type Service struct {
+	store Store
+
+	mu       sync.Mutex
+	inFlight map[string]struct{}
+}
+
+func NewService(store Store) *Service {
+	return &Service{store: store, inFlight: make(map[string]struct{})}
+}
+
+// Begin looks up a previous response for key. If one exists and was produced
+// by the same request it is returned for replay; if the key was used with a
+// different request a ConflictError is returned. Otherwise the key is marked
+// in flight and the caller must call Finish once the response is known.
+func (s *Service) Begin(ctx context.Context, key, requestHash string) (*Record, error) {
+	if key == "" || len(key) > maxKeyLength {
+		return nil, ErrValidation("Idempotency-Key must be between 1 and 255 characters")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.inFlight[key]; ok {
+		return nil, ErrInProgress(key)
+	}
+
+	rec, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if rec != nil && time.Since(rec.CreatedAt) < TTL {
+		if rec.RequestHash != requestHash {
+			return nil, ErrConflict(key)
+		}
+		return rec, nil
+	}
+
+	s.inFlight[key] = struct{}{}
+	return nil, nil
+}
+
+// Finish stores the response for key and releases it. Server errors are not
+// stored so that the client can retry them. A nil rec only releases the key.
+func (s *Service) Finish(ctx context.Context, key string, rec *Record) error {
+	defer func() {
+		s.mu.Lock()
+		delete(s.inFlight, key)
+		s.mu.Unlock()
+	}()
+
+	if rec == nil || rec.StatusCode >= 500 {
+		return nil
+	}
+
+	rec.Key = key
+	rec.CreatedAt = time.Now()
+	if err := s.store.DeleteBefore(ctx, rec.CreatedAt.Add(-TTL)); err != nil {
+		return err
+	}
+	return s.store.Save(ctx, rec)
+}
@@ -0,0 +1,13 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+type Store interface {
+	// Get returns the record for key, or nil if there is none.
+	Get(ctx context.Context, key string) (*Record, error)
+	Save(ctx context.Context, rec *Record) error
+	DeleteBefore(ctx context.Context, t time.Time) error
+}
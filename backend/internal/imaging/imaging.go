@@ -0,0 +1,162 @@
+// Package imaging prepares uploaded photos: it strips identifying metadata
+// and makes thumbnails, using only the standard library's decoders.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// maxPixels bounds the size of images that are decoded, so that a small
+// file claiming huge dimensions cannot exhaust memory. It allows for 24
+// megapixel phone photos, which decode to 36 to 96 MB; larger images get
+// no thumbnail.
+const maxPixels = 25_000_000
+
+// ErrUnsupported is returned for images that cannot be decoded here, such
+// as WebP and HEIC, or that are too large to decode.
+var ErrUnsupported = errors.New("image format not supported")
+
+// Thumbnail scales an image to fit within size×size pixels and encodes it
+// as a JPEG, or as a PNG when the original may be transparent. The EXIF
+// orientation of a JPEG is applied, since the thumbnail carries none.
+// Images already within the size are re-encoded but not enlarged.
+func Thumbnail(contentType string, data []byte, size int) ([]byte, string, error) {
+	var decode func([]byte) (image.Image, error)
+	switch contentType {
+	case "image/jpeg":
+		decode = func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) }
+	case "image/png":
+		decode = func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }
+	case "image/gif":
+		decode = func(b []byte) (image.Image, error) { return gif.Decode(bytes.NewReader(b)) }
+	default:
+		return nil, "", ErrUnsupported
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, "", ErrUnsupported
+	}
+
+	src, err := decode(data)
+	if err != nil {
+		return nil, "", err
+	}
+	thumb := scale(src, size)
+
+	var buf bytes.Buffer
+	if contentType == "image/jpeg" {
+		thumb = orient(thumb, jpegOrientation(data))
+		if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 80}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := enc.Encode(&buf, thumb); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/png", nil
+}
+
+// scale shrinks src to fit within size×size by averaging the source
+// pixels that fall on each thumbnail pixel. The source is converted one
+// row at a time, so only the thumbnail is held in full beside it.
+func scale(src image.Image, size int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := sw, sh
+	if sw > size || sh > size {
+		if sw >= sh {
+			dw, dh = size, max(1, sh*size/sw)
+		} else {
+			dw, dh = max(1, sw*size/sh), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	if dw == sw && dh == sh {
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+		return dst
+	}
+
+	row := image.NewRGBA(image.Rect(0, 0, sw, 1))
+	sums := make([]uint64, dw*4)
+	counts := make([]uint64, dw)
+	for y := 0; y < dh; y++ {
+		clear(sums)
+		clear(counts)
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for sy := y0; sy < y1; sy++ {
+			draw.Draw(row, row.Bounds(), src, image.Pt(b.Min.X, b.Min.Y+sy), draw.Src)
+			for x := 0; x < dw; x++ {
+				x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+				px := row.Pix[x0*4 : x1*4]
+				for i := 0; i < len(px); i += 4 {
+					sums[x*4] += uint64(px[i])
+					sums[x*4+1] += uint64(px[i+1])
+					sums[x*4+2] += uint64(px[i+2])
+					sums[x*4+3] += uint64(px[i+3])
+				}
+				counts[x] += uint64(len(px) / 4)
+			}
+		}
+
+		out := dst.Pix[y*dst.Stride : y*dst.Stride+dw*4]
+		for x := 0; x < dw; x++ {
+			for c := 0; c < 4; c++ {
+				out[x*4+c] = uint8(sums[x*4+c] / counts[x])
+			}
+		}
+	}
+	return dst
+}
+
+// orient turns an image upright according to its EXIF orientation: 2 to 8
+// are the mirrored and rotated variants of the normal orientation 1.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], src.Pix[y*src.Stride+x*4:y*src.Stride+x*4+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,298 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// gps stands in for location data in the test files.
+const gps = "GPS 52.5200N 13.4050E"
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 10), uint8(y * 10), 100, 255})
+		}
+	}
+	return img
+}
+
+// exifSegment builds an APP1 segment holding an orientation and some
+// location text.
+func exifSegment(orientation int) []byte {
+	seg := orientationSegment(orientation)
+	seg = append(seg, gps...)
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(seg)-2))
+	return seg
+}
+
+func testJPEG(t *testing.T, w, h, orientation int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(w, h), nil); err != nil {
+		t.Fatal(err)
+	}
+	plain := buf.Bytes()
+
+	// SOI, EXIF, a comment, the rest of the image, then a trailing picture.
+	data := append([]byte{0xFF, 0xD8}, exifSegment(orientation)...)
+	data = append(data, 0xFF, 0xFE, 0, byte(2+len(gps)))
+	data = append(data, gps...)
+	data = append(data, plain[2:]...)
+	return append(data, []byte("\xFF\xD8trailing "+gps+"\xFF\xD9")...)
+}
+
+func TestStripJPEG(t *testing.T) {
+	data := testJPEG(t, 8, 4, 6)
+
+	out, err := StripMetadata("image/jpeg", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte(gps)) {
+		t.Error("location data survived")
+	}
+	if got := jpegOrientation(out); got != 6 {
+		t.Errorf("orientation = %d, want 6", got)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("stripped JPEG does not decode: %v", err)
+	}
+	if img.Bounds().Dx() != 8 || img.Bounds().Dy() != 4 {
+		t.Errorf("bounds = %v", img.Bounds())
+	}
+
+	plain, err := StripMetadata("image/jpeg", testJPEG(t, 8, 4, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(plain, []byte("Exif")) {
+		t.Error("an upright JPEG kept its EXIF segment")
+	}
+
+	if _, err := StripMetadata("image/jpeg", data[:40]); err != ErrMalformed {
+		t.Errorf("truncated JPEG: err = %v, want ErrMalformed", err)
+	}
+}
+
+func pngChunk(typ string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, typ...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func TestStripPNG(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, testImage(4, 4))
+	plain := buf.Bytes()
+
+	// Metadata chunks go after IHDR, which is 8+25 bytes in.
+	data := append([]byte(nil), plain[:33]...)
+	data = append(data, pngChunk("tEXt", []byte("Comment\x00"+gps))...)
+	data = append(data, pngChunk("eXIf", []byte("MM\x00\x2a"+gps))...)
+	data = append(data, plain[33:]...)
+
+	out, err := StripMetadata("image/png", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, plain) {
+		t.Errorf("stripped PNG differs from the original without metadata")
+	}
+}
+
+func riffChunk(fourCC string, data []byte) []byte {
+	chunk := append([]byte(fourCC), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func TestStripWebP(t *testing.T) {
+	body := []byte("WEBP")
+	body = append(body, riffChunk("VP8X", []byte{0x0C, 0, 0, 0, 0, 0, 0, 0, 0, 0})...)
+	body = append(body, riffChunk("VP8L", []byte("pixels"))...)
+	body = append(body, riffChunk("EXIF", []byte(gps))...)
+	body = append(body, riffChunk("XMP ", []byte(gps))...)
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	data = append(data, body...)
+
+	out, err := StripMetadata("image/webp", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte(gps)) {
+		t.Error("location data survived")
+	}
+	if got := binary.LittleEndian.Uint32(out[4:]); int(got) != len(out)-8 {
+		t.Errorf("RIFF size = %d, want %d", got, len(out)-8)
+	}
+	if flags := out[20]; flags != 0 {
+		t.Errorf("VP8X flags = %#x, want EXIF and XMP cleared", flags)
+	}
+}
+
+func box(typ string, content ...[]byte) []byte {
+	body := bytes.Join(content, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(b, typ...), body...)
+}
+
+func TestStripHEIC(t *testing.T) {
+	ftyp := box("ftyp", []byte("heic\x00\x00\x00\x00mif1heic"))
+	infe := func(id uint16, itemType string) []byte {
+		return box("infe", []byte{2, 0, 0, 0}, binary.BigEndian.AppendUint16(nil, id), []byte{0, 0}, []byte(itemType), []byte{0})
+	}
+	iinf := box("iinf", []byte{0, 0, 0, 0, 0, 2}, infe(1, "hvc1"), infe(2, "Exif"))
+
+	pixels, exif := []byte("pixel data"), []byte(gps)
+	mdatStart := 0
+	build := func() []byte {
+		// iloc version 0: 4-byte offsets and lengths, no base offset.
+		iloc := []byte{0, 0, 0, 0, 0x44, 0x00, 0, 2}
+		for i, extent := range []struct{ off, n int }{
+			{mdatStart + 8, len(pixels)},
+			{mdatStart + 8 + len(pixels), len(exif)},
+		} {
+			iloc = binary.BigEndian.AppendUint16(iloc, uint16(i+1))
+			iloc = append(iloc, 0, 0, 0, 1)
+			iloc = binary.BigEndian.AppendUint32(iloc, uint32(extent.off))
+			iloc = binary.BigEndian.AppendUint32(iloc, uint32(extent.n))
+		}
+		head := append(ftyp, box("meta", []byte{0, 0, 0, 0}, iinf, box("iloc", iloc))...)
+		mdatStart = len(head)
+		return append(head, box("mdat", pixels, exif)...)
+	}
+	build()
+	data := build()
+
+	out, err := StripMetadata("image/heic", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != len(data) {
+		t.Fatalf("length changed from %d to %d", len(data), len(out))
+	}
+	if bytes.Contains(out, []byte(gps)) {
+		t.Error("location data survived")
+	}
+	if !bytes.Contains(out, pixels) {
+		t.Error("image data was wiped")
+	}
+}
+
+func TestStripHEICItemData(t *testing.T) {
+	ftyp := box("ftyp", []byte("heic\x00\x00\x00\x00mif1heic"))
+	infe := box("infe", []byte{2, 0, 0, 0}, []byte{0, 1}, []byte{0, 0}, []byte("Exif"), []byte{0})
+	iinf := box("iinf", []byte{0, 0, 0, 0, 0, 1}, infe)
+
+	build := func(method byte) []byte {
+		// iloc version 1: 4-byte offsets and lengths, one item with one
+		// extent at the start of its data.
+		iloc := []byte{1, 0, 0, 0, 0x44, 0x00, 0, 1, 0, 1, 0, method, 0, 0, 0, 1}
+		iloc = binary.BigEndian.AppendUint32(iloc, 0)
+		iloc = binary.BigEndian.AppendUint32(iloc, uint32(len(gps)))
+		meta := box("meta", []byte{0, 0, 0, 0}, iinf, box("iloc", iloc), box("idat", []byte(gps)))
+		return append(ftyp, meta...)
+	}
+
+	out, err := StripMetadata("image/heic", build(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte(gps)) {
+		t.Error("location data in idat survived")
+	}
+
+	if _, err := StripMetadata("image/heic", build(2)); err != ErrMalformed {
+		t.Errorf("item offset construction: err = %v, want %v", err, ErrMalformed)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        func(t *testing.T) []byte
+		wantType    string
+		wantW       int
+		wantH       int
+	}{
+		{"landscape jpeg", "image/jpeg", func(t *testing.T) []byte { return testJPEG(t, 40, 20, 1) }, "image/jpeg", 10, 5},
+		{"rotated jpeg", "image/jpeg", func(t *testing.T) []byte { return testJPEG(t, 40, 20, 6) }, "image/jpeg", 5, 10},
+		{"small png", "image/png", func(t *testing.T) []byte {
+			var buf bytes.Buffer
+			png.Encode(&buf, testImage(6, 8))
+			return buf.Bytes()
+		}, "image/png", 6, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb, contentType, err := Thumbnail(tt.contentType, tt.data(t), 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contentType != tt.wantType {
+				t.Errorf("content type = %q, want %q", contentType, tt.wantType)
+			}
+			cfg, _, err := image.DecodeConfig(bytes.NewReader(thumb))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Width != tt.wantW || cfg.Height != tt.wantH {
+				t.Errorf("size = %dx%d, want %dx%d", cfg.Width, cfg.Height, tt.wantW, tt.wantH)
+			}
+		})
+	}
+
+	if _, _, err := Thumbnail("image/webp", []byte("RIFF"), 10); err != ErrUnsupported {
+		t.Errorf("webp: err = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestScale(t *testing.T) {
+	// A 4×2 grey image, offset from the origin, whose left half is black
+	// and right half white.
+	src := image.NewGray(image.Rect(3, 5, 7, 7))
+	for y := 5; y < 7; y++ {
+		src.SetGray(5, y, color.Gray{255})
+		src.SetGray(6, y, color.Gray{255})
+	}
+
+	got := scale(src, 2)
+	if got.Bounds() != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("bounds = %v, want 2×1", got.Bounds())
+	}
+	if left, right := got.RGBAAt(0, 0), got.RGBAAt(1, 0); left != (color.RGBA{0, 0, 0, 255}) || right != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("pixels = %v %v, want black then white", left, right)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 2×1 image with a red left and a blue right pixel.
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+
+	// Rotating clockwise puts the left pixel on top.
+	dst := orient(src, 6)
+	if dst.Bounds().Dx() != 1 || dst.Bounds().Dy() != 2 || dst.RGBAAt(0, 0) != red || dst.RGBAAt(0, 1) != blue {
+		t.Errorf("orientation 6: got %v, %v on a %v image", dst.RGBAAt(0, 0), dst.RGBAAt(0, 1), dst.Bounds())
+	}
+	if dst := orient(src, 2); dst.RGBAAt(0, 0) != blue {
+		t.Errorf("orientation 2: left pixel = %v, want blue", dst.RGBAAt(0, 0))
+	}
+}
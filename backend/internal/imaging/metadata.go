@@ -0,0 +1,450 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrMalformed is returned when an image is too damaged to be rewritten
+// safely.
+var ErrMalformed = errors.New("malformed image")
+
+// StripMetadata removes EXIF, XMP, IPTC and text metadata, which may hold
+// GPS coordinates, camera serial numbers and the like. Pixel data is left
+// untouched. A JPEG keeps its EXIF orientation, since browsers rely on it
+// to display photos upright. Content types without metadata support are
+// returned unchanged.
+func StripMetadata(contentType string, data []byte) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	case "image/heic":
+		return stripHEIC(data)
+	}
+	return data, nil
+}
+
+// stripJPEG keeps the segments needed to decode and colour the image:
+// JFIF (APP0), ICC profiles (APP2) and Adobe colour transforms (APP14).
+// Anything after the end of the image, such as the extra pictures of the
+// multi-picture format, is dropped as well.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrMalformed
+	}
+	orientation := jpegOrientation(data)
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	wroteOrientation := orientation == 1
+
+	i := 2
+	for {
+		if i+2 > len(data) || data[i] != 0xFF {
+			return nil, ErrMalformed
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// Fill byte before a marker.
+			i++
+			continue
+		}
+		if marker == 0xD9 {
+			return append(out, 0xFF, 0xD9), nil
+		}
+		if i+4 > len(data) {
+			return nil, ErrMalformed
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, ErrMalformed
+		}
+		segment := data[i:end]
+		payload := data[i+4 : end]
+
+		// The orientation goes after JFIF, which must come first.
+		if !wroteOrientation && marker != 0xE0 {
+			out = append(out, orientationSegment(orientation)...)
+			wroteOrientation = true
+		}
+
+		keep := true
+		switch {
+		case marker == 0xE0 || marker == 0xEE:
+		case marker == 0xE2:
+			keep = bytes.HasPrefix(payload, []byte("ICC_PROFILE\x00"))
+		case marker >= 0xE1 && marker <= 0xEF, marker == 0xFE:
+			keep = false
+		}
+		if keep {
+			out = append(out, segment...)
+		}
+		i = end
+
+		if marker == 0xDA {
+			// Entropy-coded data runs up to the next marker other than a
+			// stuffed zero byte or a restart marker.
+			j := i
+			for j+1 < len(data) && !(data[j] == 0xFF && data[j+1] != 0 && (data[j+1] < 0xD0 || data[j+1] > 0xD7)) {
+				j++
+			}
+			if j+1 >= len(data) {
+				return nil, ErrMalformed
+			}
+			out = append(out, data[i:j]...)
+			i = j
+		}
+	}
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG, 1 if it has
+// none.
+func jpegOrientation(data []byte) int {
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) {
+			break
+		}
+		if marker == 0xE1 && bytes.HasPrefix(data[i+4:end], []byte("Exif\x00\x00")) {
+			return tiffOrientation(data[i+10 : end])
+		}
+		i = end
+	}
+	return 1
+}
+
+// tiffOrientation reads the Orientation tag from the first directory of
+// EXIF data.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 && order.Uint16(tiff[entry+2:]) == 3 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+		}
+	}
+	return 1
+}
+
+// orientationSegment builds an APP1 segment whose EXIF data holds nothing
+// but the orientation.
+func orientationSegment(orientation int) []byte {
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // header, first directory at 8
+		0, 1, // one entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, byte(orientation), 0, 0, // Orientation, SHORT
+		0, 0, 0, 0, // no next directory
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// stripPNG drops the eXIf chunk and textual chunks, which is where XMP is
+// kept.
+func stripPNG(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, ErrMalformed
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, signature...)
+	for i := len(signature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, ErrMalformed
+		}
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end > len(data) || end < i {
+			return nil, ErrMalformed
+		}
+		switch string(data[i+4 : i+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out = append(out, data[i:end]...)
+		}
+		if string(data[i+4:i+8]) == "IEND" {
+			break
+		}
+		i = end
+	}
+	return out, nil
+}
+
+// stripWebP drops the EXIF and XMP chunks and clears their flags in the
+// extended header.
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrMalformed
+	}
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, ErrMalformed
+		}
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if end > len(data) || end < i {
+			return nil, ErrMalformed
+		}
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			start := len(out)
+			out = append(out, data[i:end]...)
+			if size > 0 {
+				out[start+8] &^= 0x08 | 0x04
+			}
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// stripHEIC overwrites the EXIF and XMP items of a HEIF file with zeros,
+// whether they are stored in the file or in the meta box's item data.
+// Removing them would shift every offset in the file, so their space is
+// kept but their content is wiped.
+func stripHEIC(data []byte) ([]byte, error) {
+	meta, ok := findBox(data, "meta")
+	if !ok || len(meta) < 4 {
+		return nil, ErrMalformed
+	}
+	children := meta[4:] // version and flags
+
+	iinf, ok := findBox(children, "iinf")
+	if !ok {
+		return nil, ErrMalformed
+	}
+	iloc, ok := findBox(children, "iloc")
+	if !ok {
+		return nil, ErrMalformed
+	}
+
+	items, err := metadataItems(iinf)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return data, nil
+	}
+
+	out := append([]byte(nil), data...)
+	outMeta, _ := findBox(out, "meta")
+	idat, _ := findBox(outMeta[4:], "idat")
+	err = itemExtents(iloc, func(id uint32, method, offset, length uint64) error {
+		if !items[id] {
+			return nil
+		}
+		target := out
+		switch method {
+		case 0:
+		case 1:
+			target = idat
+		default:
+			// Offsets into other items cannot be checked, so the file is
+			// refused rather than kept with its metadata.
+			return ErrMalformed
+		}
+		if offset > uint64(len(target)) || length > uint64(len(target))-offset {
+			return ErrMalformed
+		}
+		clear(target[offset : offset+length])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// findBox returns the content of the first box of the given type.
+func findBox(data []byte, boxType string) ([]byte, bool) {
+	for i := 0; i+8 <= len(data); {
+		size := uint64(binary.BigEndian.Uint32(data[i:]))
+		header := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data) - i)
+		case 1:
+			if i+16 > len(data) {
+				return nil, false
+			}
+			size, header = binary.BigEndian.Uint64(data[i+8:]), 16
+		}
+		if size < header || size > uint64(len(data)-i) {
+			return nil, false
+		}
+		if string(data[i+4:i+8]) == boxType {
+			return data[i+int(header) : i+int(size)], true
+		}
+		i += int(size)
+	}
+	return nil, false
+}
+
+// metadataItems returns the IDs of the EXIF and XMP items listed in an
+// item information box.
+func metadataItems(iinf []byte) (map[uint32]bool, error) {
+	r := reader{data: iinf}
+	version := r.uint(1)
+	r.skip(3)
+	if version == 0 {
+		r.uint(2)
+	} else {
+		r.uint(4)
+	}
+	if r.err {
+		return nil, ErrMalformed
+	}
+
+	items := map[uint32]bool{}
+	entries := r.data[r.pos:]
+	for len(entries) >= 8 {
+		size := int(binary.BigEndian.Uint32(entries))
+		if size < 8 || size > len(entries) {
+			return nil, ErrMalformed
+		}
+		if string(entries[4:8]) == "infe" {
+			e := reader{data: entries[8:size]}
+			version := e.uint(1)
+			e.skip(3)
+			if version >= 2 {
+				var id uint32
+				if version == 2 {
+					id = uint32(e.uint(2))
+				} else {
+					id = uint32(e.uint(4))
+				}
+				e.skip(2) // protection index
+				itemType := string(e.bytes(4))
+				if !e.err && (itemType == "Exif" || itemType == "mime") {
+					items[id] = true
+				}
+			}
+		}
+		entries = entries[size:]
+	}
+	return items, nil
+}
+
+// itemExtents calls fn with every extent listed in an item location box,
+// along with its construction method: 0 for a range of the file and 1 for a
+// range of the idat box.
+func itemExtents(iloc []byte, fn func(id uint32, method, offset, length uint64) error) error {
+	r := reader{data: iloc}
+	version := r.uint(1)
+	r.skip(3)
+	sizes := r.uint(2)
+	offsetSize, lengthSize := int(sizes>>12&0xF), int(sizes>>8&0xF)
+	baseOffsetSize, indexSize := int(sizes>>4&0xF), 0
+	if version == 1 || version == 2 {
+		indexSize = int(sizes & 0xF)
+	}
+
+	var count uint64
+	if version < 2 {
+		count = r.uint(2)
+	} else {
+		count = r.uint(4)
+	}
+
+	for n := uint64(0); n < count && !r.err; n++ {
+		var id uint32
+		if version < 2 {
+			id = uint32(r.uint(2))
+		} else {
+			id = uint32(r.uint(4))
+		}
+		method := uint64(0)
+		if version == 1 || version == 2 {
+			method = r.uint(2) & 0xF
+		}
+		r.skip(2) // data reference index
+		base := r.uint(baseOffsetSize)
+		extents := r.uint(2)
+		for e := uint64(0); e < extents && !r.err; e++ {
+			r.uint(indexSize)
+			offset := r.uint(offsetSize)
+			length := r.uint(lengthSize)
+			if !r.err {
+				if err := fn(id, method, base+offset, length); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if r.err {
+		return ErrMalformed
+	}
+	return nil
+}
+
+// reader reads big-endian integers of varying width, remembering whether
+// it ran out of data.
+type reader struct {
+	data []byte
+	pos  int
+	err  bool
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err || r.pos+n > len(r.data) {
+		r.err = true
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) skip(n int) {
+	r.bytes(n)
+}
+
+func (r *reader) uint(n int) uint64 {
+	var v uint64
+	for _, b := range r.bytes(n) {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
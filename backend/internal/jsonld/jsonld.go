@@ -0,0 +1,111 @@
+// Package jsonld extracts schema.org objects embedded in HTML pages as
+// <script type="application/ld+json"> blocks.
+package jsonld
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var scriptPattern = regexp.MustCompile(`(?is)<script[^>]*type\s*=\s*["']?application/ld\+json["']?[^>]*>(.*?)</script>`)
+
+// Extract returns every JSON object found in the page's JSON-LD blocks.
+// Top-level arrays and @graph containers are flattened; blocks that fail to
+// parse are skipped.
+func Extract(page []byte) []map[string]any {
+	var objects []map[string]any
+	for _, match := range scriptPattern.FindAllSubmatch(page, -1) {
+		var v any
+		if err := json.Unmarshal(match[1], &v); err != nil {
+			// Some sites HTML-escape the script body.
+			if err := json.Unmarshal([]byte(html.UnescapeString(string(match[1]))), &v); err != nil {
+				continue
+			}
+		}
+		objects = flatten(objects, v)
+	}
+	return objects
+}
+
+func flatten(dst []map[string]any, v any) []map[string]any {
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			dst = flatten(dst, item)
+		}
+	case map[string]any:
+		dst = append(dst, v)
+		if graph, ok := v["@graph"]; ok {
+			dst = flatten(dst, graph)
+		}
+	}
+	return dst
+}
+
+// Find returns the first object whose @type is typ.
+func Find(objects []map[string]any, typ string) map[string]any {
+	for _, obj := range objects {
+		if HasType(obj, typ) {
+			return obj
+		}
+	}
+	return nil
+}
+
+// HasType reports whether obj's @type is typ. Both single types and type
+// arrays are supported, as are prefixed forms like "schema:Recipe" or
+// "http://schema.org/Recipe".
+func HasType(obj map[string]any, typ string) bool {
+	for _, t := range Strings(obj["@type"]) {
+		if t == typ || strings.HasSuffix(t, "/"+typ) || strings.HasSuffix(t, ":"+typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the text of a JSON-LD value: strings and numbers as is,
+// {"@value": ...} objects by their value, and arrays by their first element.
+func String(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(html.UnescapeString(v))
+	case float64:
+		return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%f", v), "0"), ".")
+	case map[string]any:
+		if value, ok := v["@value"]; ok {
+			return String(value)
+		}
+		for _, key := range []string{"url", "name", "text"} {
+			if s := String(v[key]); s != "" {
+				return s
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if s := String(item); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// Strings returns the text of each element of an array value, or of the
+// value itself if it is not an array.
+func Strings(v any) []string {
+	items, ok := v.([]any)
+	if !ok {
+		items = []any{v}
+	}
+	var out []string
+	for _, item := range items {
+		if s := String(item); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,217 @@
+// Package linkmeta extracts a title, image and price from product pages
+// using their Open Graph tags and schema.org Product data.
+package linkmeta
+
+import (
+	"context"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/stadtaev/lofam/backend/internal/jsonld"
+)
+
+// Metadata describes the page a link points to. Fields the page does not
+// provide are left empty.
+type Metadata struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ImageURL    string `json:"imageUrl"`
+	SiteName    string `json:"siteName"`
+	// PriceCents is the price in minor units of Currency, assuming two
+	// decimal places.
+	PriceCents *int64 `json:"priceCents"`
+	Currency   string `json:"currency"`
+}
+
+// Fetcher downloads the page behind a link.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) ([]byte, error)
+}
+
+type Extractor struct {
+	fetcher Fetcher
+}
+
+func NewExtractor(fetcher Fetcher) *Extractor {
+	return &Extractor{fetcher: fetcher}
+}
+
+// Lookup fetches the page at pageURL and extracts its metadata.
+func (e *Extractor) Lookup(ctx context.Context, pageURL string) (*Metadata, error) {
+	page, err := e.fetcher.Fetch(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	return Extract(page, pageURL), nil
+}
+
+var (
+	metaPattern       = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attrPattern       = regexp.MustCompile(`(?s)([a-zA-Z:_-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	titlePattern      = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// Extract reads metadata from a page. schema.org Product data is preferred
+// over Open Graph tags, which are preferred over the page title. Relative
+// image URLs are resolved against pageURL.
+func Extract(page []byte, pageURL string) *Metadata {
+	meta := metaTags(page)
+	m := &Metadata{
+		Title:       first(meta["og:title"], meta["twitter:title"]),
+		Description: first(meta["og:description"], meta["description"]),
+		ImageURL:    first(meta["og:image"], meta["og:image:url"], meta["og:image:secure_url"], meta["twitter:image"]),
+		SiteName:    meta["og:site_name"],
+		Currency:    first(meta["product:price:currency"], meta["og:price:currency"]),
+	}
+	if price, ok := ParsePrice(first(meta["product:price:amount"], meta["og:price:amount"])); ok {
+		m.PriceCents = &price
+	}
+
+	if product := jsonld.Find(jsonld.Extract(page), "Product"); product != nil {
+		applyProduct(m, product)
+	}
+
+	if m.Title == "" {
+		if match := titlePattern.FindSubmatch(page); match != nil {
+			m.Title = clean(string(match[1]))
+		}
+	}
+	m.ImageURL = resolve(pageURL, m.ImageURL)
+	m.Currency = strings.ToUpper(m.Currency)
+
+	return m
+}
+
+func applyProduct(m *Metadata, product map[string]any) {
+	if name := jsonld.String(product["name"]); name != "" {
+		m.Title = clean(name)
+	}
+	if m.Description == "" {
+		m.Description = clean(jsonld.String(product["description"]))
+	}
+	if image := jsonld.String(product["image"]); image != "" {
+		m.ImageURL = image
+	}
+
+	for _, offer := range offers(product["offers"]) {
+		raw := offer["price"]
+		if raw == nil {
+			// AggregateOffer only has a range.
+			raw = offer["lowPrice"]
+		}
+		if price, ok := ParsePrice(jsonld.String(raw)); ok {
+			m.PriceCents = &price
+			if currency := jsonld.String(offer["priceCurrency"]); currency != "" {
+				m.Currency = currency
+			}
+			return
+		}
+	}
+}
+
+// offers returns the Offer objects of a product, which may be a single
+// object, an array, or an AggregateOffer listing its own offers.
+func offers(v any) []map[string]any {
+	var out []map[string]any
+	switch v := v.(type) {
+	case map[string]any:
+		out = append(out, v)
+		out = append(out, offers(v["offers"])...)
+	case []any:
+		for _, item := range v {
+			out = append(out, offers(item)...)
+		}
+	}
+	return out
+}
+
+// metaTags maps each meta tag's property or name to its content. The first
+// occurrence wins.
+func metaTags(page []byte) map[string]string {
+	tags := make(map[string]string)
+	for _, tag := range metaPattern.FindAll(page, -1) {
+		attrs := make(map[string]string)
+		for _, a := range attrPattern.FindAllSubmatch(tag, -1) {
+			attrs[strings.ToLower(string(a[1]))] = string(a[2]) + string(a[3]) + string(a[4])
+		}
+		key := strings.ToLower(first(attrs["property"], attrs["name"], attrs["itemprop"]))
+		content := clean(attrs["content"])
+		if key == "" || content == "" {
+			continue
+		}
+		if _, ok := tags[key]; !ok {
+			tags[key] = content
+		}
+	}
+	return tags
+}
+
+var priceCharsPattern = regexp.MustCompile(`[^0-9.,]`)
+
+// ParsePrice converts a price such as "49.99", "1,299.00", "1.299,00" or
+// "€ 5" to minor units. The last separator is taken as the decimal point
+// when it is followed by one or two digits.
+func ParsePrice(s string) (int64, bool) {
+	s = priceCharsPattern.ReplaceAllString(s, "")
+	if s == "" {
+		return 0, false
+	}
+
+	whole, fraction := s, ""
+	if i := strings.LastIndexAny(s, ".,"); i >= 0 && len(s)-i-1 <= 2 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	whole = strings.NewReplacer(".", "", ",", "").Replace(whole)
+	if whole == "" {
+		whole = "0"
+	}
+	for len(fraction) < 2 {
+		fraction += "0"
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	cents, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return units*100 + cents, true
+}
+
+func resolve(base, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	resolved := b.ResolveReference(r)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return ""
+	}
+	return resolved.String()
+}
+
+func clean(s string) string {
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(html.UnescapeString(s), " "))
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package linkmeta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stadtaev/lofam/backend/internal/fetch"
+)
+
+const openGraphPage = `<html><head>
+<title>Ignored &mdash; Shop</title>
+<meta property="og:title" content="Wooden Train Set">
+<meta property="og:image" content="/img/train.jpg">
+<meta property="og:site_name" content="Toy Shop">
+<meta property="product:price:amount" content="34,95">
+<meta property="product:price:currency" content="eur">
+</head></html>`
+
+const productPage = `<html><head>
+<meta property="og:title" content="Generic title">
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@type": "Product", "name": "Balance Bike",
+ "image": ["https://cdn.example.com/bike.jpg"],
+ "offers": {"@type": "AggregateOffer", "lowPrice": 89.5, "priceCurrency": "GBP"}}
+</script>
+</head></html>`
+
+func TestLookup(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/train":
+			w.Write([]byte(openGraphPage))
+		case "/bike":
+			w.Write([]byte(productPage))
+		case "/plain":
+			w.Write([]byte("<html><title>\n  Just a page </title></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	extractor := NewExtractor(fetch.NewHTTPFetcher(fetch.AllowPrivateNetworks()))
+
+	tests := []struct {
+		path  string
+		want  Metadata
+		price int64
+	}{
+		{"/train", Metadata{Title: "Wooden Train Set", ImageURL: ts.URL + "/img/train.jpg", SiteName: "Toy Shop", Currency: "EUR"}, 3495},
+		{"/bike", Metadata{Title: "Balance Bike", ImageURL: "https://cdn.example.com/bike.jpg", Currency: "GBP"}, 8950},
+		{"/plain", Metadata{Title: "Just a page"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := extractor.Lookup(context.Background(), ts.URL+tt.path)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+
+			price := got.PriceCents
+			got.PriceCents = nil
+			if *got != tt.want {
+				t.Errorf("Lookup() = %+v, want %+v", *got, tt.want)
+			}
+			if tt.price == 0 && price != nil {
+				t.Errorf("price = %d, want none", *price)
+			}
+			if tt.price != 0 && (price == nil || *price != tt.price) {
+				t.Errorf("price = %v, want %d", price, tt.price)
+			}
+		})
+	}
+
+	if _, err := extractor.Lookup(context.Background(), ts.URL+"/missing"); err == nil {
+		t.Error("Lookup() of missing page succeeded, want error")
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"49.99", 4999, true},
+		{"49,99", 4999, true},
+		{"1,299.00", 129900, true},
+		{"1.299,00", 129900, true},
+		{"1,299", 129900, true},
+		{"€ 5", 500, true},
+		{"12.5", 1250, true},
+		{"free", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParsePrice(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParsePrice(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type blockKind int
+
+const (
+	paragraphBlock blockKind = iota
+	headingBlock
+	codeBlock
+	quoteBlock
+	listBlock
+	itemBlock
+	ruleBlock
+)
+
+type block struct {
+	kind  blockKind
+	level int      // heading level
+	lines []string // text of paragraphs, headings and code blocks
+	info  string   // language of a fenced code block
+
+	ordered bool
+	start   int
+	loose   bool
+	task    *task
+
+	children []*block
+	// first and last are the source lines the block spans.
+	first, last int
+}
+
+// task is a task-list checkbox and the position of its "[ ]" in the source.
+type task struct {
+	index   int
+	checked bool
+	line    int
+	col     int
+}
+
+// line is a source line with the prefixes of enclosing containers (quote
+// markers, list indentation) removed. num and col locate text in the
+// original source.
+type line struct {
+	text string
+	num  int
+	col  int
+}
+
+type parser struct {
+	tasks []*task
+}
+
+func parse(source string) (*parser, []*block) {
+	raw := strings.Split(source, "\n")
+	lines := make([]line, len(raw))
+	for i, r := range raw {
+		lines[i] = line{text: strings.TrimSuffix(r, "\r"), num: i}
+	}
+
+	p := &parser{}
+	return p, p.blocks(lines)
+}
+
+var (
+	fencePattern   = regexp.MustCompile("^(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	headingPattern = regexp.MustCompile(`^(#{1,6})(?:[ \t]|$)`)
+	closingHashes  = regexp.MustCompile(`(?:^|[ \t]+)#+[ \t]*$`)
+	rulePattern    = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	listPattern    = regexp.MustCompile(`^([-*+]|(\d{1,9})[.)])([ \t]+|$)`)
+	taskPattern    = regexp.MustCompile(`^\[([ xX])\](?:[ \t]|$)`)
+	setextH1       = regexp.MustCompile(`^=+[ \t]*$`)
+	setextH2       = regexp.MustCompile(`^-+[ \t]*$`)
+)
+
+func (p *parser) blocks(lines []line) []*block {
+	var out []*block
+	for i := 0; i < len(lines); {
+		l := lines[i]
+		if isBlank(l.text) {
+			i++
+			continue
+		}
+
+		var b *block
+		var n int
+		t := dedent(l, 3).text
+		switch {
+		case indent(l.text) >= 4:
+			b, n = p.indentedCode(lines[i:])
+		case fencePattern.MatchString(t):
+			b, n = p.fencedCode(lines[i:])
+		case headingPattern.MatchString(t):
+			b, n = heading(t), 1
+		case rulePattern.MatchString(t):
+			b, n = &block{kind: ruleBlock}, 1
+		case strings.HasPrefix(t, ">"):
+			b, n = p.quote(lines[i:])
+		case listPattern.MatchString(t):
+			b, n = p.list(lines[i:])
+		default:
+			b, n = p.paragraph(lines[i:])
+		}
+
+		b.first, b.last = lines[i].num, lines[i+n-1].num
+		out = append(out, b)
+		i += n
+	}
+	return out
+}
+
+func (p *parser) indentedCode(lines []line) (*block, int) {
+	b := &block{kind: codeBlock}
+	n := 0
+	for n < len(lines) && (isBlank(lines[n].text) || indent(lines[n].text) >= 4) {
+		b.lines = append(b.lines, dedent(lines[n], 4).text)
+		n++
+	}
+	// Trailing blank lines separate the block from what follows.
+	for isBlank(b.lines[len(b.lines)-1]) {
+		b.lines = b.lines[:len(b.lines)-1]
+		n--
+	}
+	return b, n
+}
+
+func (p *parser) fencedCode(lines []line) (*block, int) {
+	fenceIndent := indent(lines[0].text)
+	m := fencePattern.FindStringSubmatch(dedent(lines[0], 3).text)
+	fence := m[1]
+	b := &block{kind: codeBlock, info: m[2], lines: []string{}}
+
+	n := 1
+	for ; n < len(lines); n++ {
+		l := lines[n]
+		t := strings.TrimRight(dedent(l, 3).text, " \t")
+		if indent(l.text) < 4 && len(t) >= len(fence) && strings.Trim(t, fence[:1]) == "" {
+			return b, n + 1
+		}
+		b.lines = append(b.lines, dedent(l, fenceIndent).text)
+	}
+	// An unclosed fence runs to the end of its container.
+	return b, n
+}
+
+func heading(t string) *block {
+	level := strings.IndexFunc(t, func(r rune) bool { return r != '#' })
+	if level < 0 {
+		level = len(t)
+	}
+	text := strings.TrimSpace(closingHashes.ReplaceAllString(t[level:], ""))
+	return &block{kind: headingBlock, level: level, lines: []string{text}}
+}
+
+func (p *parser) quote(lines []line) (*block, int) {
+	var inner []line
+	n := 0
+	for ; n < len(lines); n++ {
+		l := lines[n]
+		d := dedent(l, 3)
+		if indent(l.text) < 4 && strings.HasPrefix(d.text, ">") {
+			d.text, d.col = d.text[1:], d.col+1
+			if strings.HasPrefix(d.text, " ") || strings.HasPrefix(d.text, "\t") {
+				d.text, d.col = d.text[1:], d.col+1
+			}
+			inner = append(inner, d)
+			continue
+		}
+		// Lazy continuation of a quoted paragraph.
+		if !isBlank(l.text) && !isBlank(inner[len(inner)-1].text) && !startsBlock(l.text) {
+			inner = append(inner, l)
+			continue
+		}
+		break
+	}
+	return &block{kind: quoteBlock, children: p.blocks(inner)}, n
+}
+
+// marker is a parsed list item marker.
+type marker struct {
+	bullet  byte // '-', '*' or '+', or the delimiter of an ordered item
+	ordered bool
+	start   int
+}
+
+func (m marker) sameList(o marker) bool {
+	return m.bullet == o.bullet && m.ordered == o.ordered
+}
+
+func parseMarker(t string) (marker, []string, bool) {
+	m := listPattern.FindStringSubmatch(t)
+	if m == nil {
+		return marker{}, nil, false
+	}
+	if m[2] == "" {
+		return marker{bullet: m[1][0]}, m, true
+	}
+	start, _ := strconv.Atoi(m[2])
+	return marker{bullet: m[1][len(m[1])-1], ordered: true, start: start}, m, true
+}
+
+func (p *parser) list(lines []line) (*block, int) {
+	first, _, _ := parseMarker(dedent(lines[0], 3).text)
+	b := &block{kind: listBlock, ordered: first.ordered, start: first.start}
+
+	n := 0
+	for {
+		j := n
+		for j < len(lines) && isBlank(lines[j].text) {
+			j++
+		}
+		if j == len(lines) || indent(lines[j].text) >= 4 {
+			break
+		}
+		m, _, ok := parseMarker(dedent(lines[j], 3).text)
+		if !ok || !m.sameList(first) {
+			break
+		}
+		if j > n {
+			b.loose = true
+		}
+
+		item, used := p.item(lines[j:])
+		if item.loose {
+			b.loose = true
+		}
+		b.children = append(b.children, item)
+		n = j + used
+	}
+	return b, n
+}
+
+func (p *parser) item(lines []line) (*block, int) {
+	l := lines[0]
+	d := dedent(l, 3)
+	_, m, _ := parseMarker(d.text)
+	markerLen, spaces := len(m[1]), m[3]
+
+	// width is the column where the item's content starts; later lines
+	// must be indented that far to belong to the item.
+	width := indent(l.text) + markerLen + 1
+	skip := markerLen + len(spaces)
+	rest := d.text[skip:]
+	if spaceWidth := indent(spaces); !isBlank(rest) && spaceWidth <= 4 {
+		width = indent(l.text) + markerLen + spaceWidth
+	} else if !isBlank(rest) {
+		skip = markerLen + 1
+	}
+	content := []line{{text: d.text[skip:], num: l.num, col: d.col + skip}}
+
+	b := &block{kind: itemBlock}
+	if tm := taskPattern.FindStringSubmatch(content[0].text); tm != nil {
+		b.task = &task{index: len(p.tasks), checked: tm[1] != " ", line: l.num, col: content[0].col}
+		p.tasks = append(p.tasks, b.task)
+		content[0].text = strings.TrimLeft(content[0].text[3:], " \t")
+		content[0].col = l.col + len(l.text) - len(content[0].text)
+	}
+
+	n := 1
+	for ; n < len(lines); n++ {
+		next := lines[n]
+		switch {
+		case isBlank(next.text):
+			// An item can start with at most one blank line.
+			if isBlank(content[0].text) && n == 1 {
+				return b.parse(p, content), n
+			}
+			content = append(content, line{num: next.num})
+		case indent(next.text) >= width:
+			content = append(content, dedent(next, width))
+		case !isBlank(content[len(content)-1].text) && !startsBlock(next.text) &&
+			!listPattern.MatchString(dedent(next, 3).text):
+			// Lazy continuation of the item's paragraph.
+			content = append(content, dedent(next, 3))
+		default:
+			return b.parse(p, trimBlankTail(content)), n - blankTail(content)
+		}
+	}
+	return b.parse(p, trimBlankTail(content)), n - blankTail(content)
+}
+
+// parse parses the content of an item and decides whether it is loose,
+// that is whether blank lines separate its blocks.
+func (b *block) parse(p *parser, content []line) *block {
+	b.children = p.blocks(content)
+	for i := 1; i < len(b.children); i++ {
+		if b.children[i].first > b.children[i-1].last+1 {
+			b.loose = true
+		}
+	}
+	return b
+}
+
+func blankTail(lines []line) int {
+	n := 0
+	for i := len(lines) - 1; i > 0 && isBlank(lines[i].text); i-- {
+		n++
+	}
+	return n
+}
+
+func trimBlankTail(lines []line) []line {
+	return lines[:len(lines)-blankTail(lines)]
+}
+
+func (p *parser) paragraph(lines []line) (*block, int) {
+	b := &block{kind: paragraphBlock}
+	n := 0
+	for ; n < len(lines); n++ {
+		l := lines[n]
+		if isBlank(l.text) {
+			break
+		}
+		if n > 0 && indent(l.text) < 4 {
+			t := dedent(l, 3).text
+			if setextH1.MatchString(t) {
+				return &block{kind: headingBlock, level: 1, lines: b.lines}, n + 1
+			}
+			if setextH2.MatchString(t) {
+				return &block{kind: headingBlock, level: 2, lines: b.lines}, n + 1
+			}
+			if startsBlock(l.text) {
+				break
+			}
+		}
+		b.lines = append(b.lines, strings.TrimLeft(l.text, " \t"))
+	}
+	return b, n
+}
+
+// startsBlock reports whether s begins a block that interrupts a paragraph.
+func startsBlock(s string) bool {
+	if indent(s) >= 4 {
+		return false
+	}
+	t := strings.TrimLeft(s, " \t")
+	if fencePattern.MatchString(t) || headingPattern.MatchString(t) ||
+		rulePattern.MatchString(t) || strings.HasPrefix(t, ">") {
+		return true
+	}
+	// Only non-empty lists starting at 1 interrupt a paragraph.
+	m, parts, ok := parseMarker(t)
+	return ok && !isBlank(t[len(parts[0]):]) && (!m.ordered || m.start == 1)
+}
+
+func isBlank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+// indent returns the width of the leading whitespace of s, with tabs
+// advancing to the next multiple of four columns.
+func indent(s string) int {
+	w := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case ' ':
+			w++
+		case '\t':
+			w += 4 - w%4
+		default:
+			return w
+		}
+	}
+	return w
+}
+
+// dedent removes up to n columns of leading whitespace from l.
+func dedent(l line, n int) line {
+	w, i := 0, 0
+	for i < len(l.text) && w < n {
+		switch l.text[i] {
+		case ' ':
+			w++
+		case '\t':
+			w += 4 - w%4
+		default:
+			return line{text: l.text[i:], num: l.num, col: l.col + i}
+		}
+		i++
+	}
+	return line{text: l.text[i:], num: l.num, col: l.col + i}
+}
@@ -0,0 +1,487 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	entityPattern   = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+	uriAutolink     = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^<>\x00-\x20]*)>`)
+	emailAutolink   = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*)>`)
+	bareURL         = regexp.MustCompile(`^(?:https?://|www\.)[^\s<]*`)
+	schemePattern   = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*):`)
+	trailingURLPunc = "?!.,:*_~'\";"
+)
+
+// Scans for an emphasis opener or the end of a link stop after this many
+// delimiter runs or bytes, which keeps rendering linear on input such as
+// thousands of unmatched "*" or "![".
+const (
+	maxDelimiterScan = 100
+	maxLinkScan      = 2048
+)
+
+// token is a piece of inline output: either rendered HTML, or a run of
+// emphasis delimiters that may turn into tags.
+type token struct {
+	html string
+
+	delim     byte
+	count     int
+	length    int // length of the run before any delimiters were used
+	canOpen   bool
+	canClose  bool
+	inactive  bool
+	openTags  string
+	closeTags string
+}
+
+// renderInline renders the inline content of a block. Links are not
+// rendered inside link text, where links is false.
+func renderInline(s string, links bool) string {
+	var toks []*token
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			toks = append(toks, &token{html: text.String()})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			text.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			text.WriteString("<br />\n")
+			i += 2
+
+		case c == '`':
+			run := runLength(s, i, '`')
+			end := closingBackticks(s, i+run, run)
+			if end < 0 {
+				text.WriteString(s[i : i+run])
+				i += run
+				continue
+			}
+			code := strings.ReplaceAll(s[i+run:end], "\n", " ")
+			if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+				code = code[1 : len(code)-1]
+			}
+			text.WriteString("<code>" + html.EscapeString(code) + "</code>")
+			i = end + run
+
+		case c == '*' || c == '_' || c == '~':
+			run := runLength(s, i, c)
+			if c == '~' && run > 2 {
+				text.WriteString(s[i : i+run])
+				i += run
+				continue
+			}
+			prev, _ := utf8.DecodeLastRuneInString(s[:i])
+			next, _ := utf8.DecodeRuneInString(s[i+run:])
+			if i == 0 {
+				prev = ' '
+			}
+			if i+run == len(s) {
+				next = ' '
+			}
+			left := !unicode.IsSpace(next) && (!isPunct(next) || unicode.IsSpace(prev) || isPunct(prev))
+			right := !unicode.IsSpace(prev) && (!isPunct(prev) || unicode.IsSpace(next) || isPunct(next))
+			t := &token{delim: c, count: run, length: run, canOpen: left, canClose: right}
+			if c == '_' {
+				t.canOpen = left && (!right || isPunct(prev))
+				t.canClose = right && (!left || isPunct(next))
+			}
+			flush()
+			toks = append(toks, t)
+			i += run
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if out, n, ok := link(s[i+1:], true); ok {
+				text.WriteString(out)
+				i += 1 + n
+				continue
+			}
+			text.WriteString("!")
+			i++
+
+		case c == '[' && links:
+			if out, n, ok := link(s[i:], false); ok {
+				text.WriteString(out)
+				i += n
+				continue
+			}
+			text.WriteString("[")
+			i++
+
+		case c == '<':
+			if out, n, ok := autolink(s[i:], links); ok {
+				text.WriteString(out)
+				i += n
+				continue
+			}
+			text.WriteString("&lt;")
+			i++
+
+		case c == '&':
+			if m := entityPattern.FindString(s[i:]); m != "" {
+				text.WriteString(html.EscapeString(html.UnescapeString(m)))
+				i += len(m)
+				continue
+			}
+			text.WriteString("&amp;")
+			i++
+
+		case c == '\n':
+			// Two trailing spaces make a hard line break.
+			pending := text.String()
+			trimmed := strings.TrimRight(pending, " ")
+			text.Reset()
+			text.WriteString(trimmed)
+			if len(pending)-len(trimmed) >= 2 {
+				text.WriteString("<br />")
+			}
+			text.WriteString("\n")
+			i++
+			for i < len(s) && s[i] == ' ' {
+				i++
+			}
+
+		case links && (c == 'h' || c == 'w') && atWordStart(s, i):
+			if u := matchBareURL(s[i:]); u != "" {
+				href := u
+				if strings.HasPrefix(u, "www.") {
+					href = "http://" + u
+				}
+				text.WriteString(anchor(href, html.EscapeString(u)))
+				i += len(u)
+				continue
+			}
+			text.WriteByte(c)
+			i++
+
+		default:
+			text.WriteString(html.EscapeString(s[i : i+1]))
+			i++
+		}
+	}
+	flush()
+
+	matchEmphasis(toks)
+
+	var out strings.Builder
+	for _, t := range toks {
+		if t.delim == 0 {
+			out.WriteString(t.html)
+			continue
+		}
+		out.WriteString(t.closeTags)
+		out.WriteString(strings.Repeat(string(t.delim), t.count))
+		out.WriteString(t.openTags)
+	}
+	return out.String()
+}
+
+// matchEmphasis pairs delimiter runs into em, strong and del tags,
+// following the CommonMark delimiter rules.
+func matchEmphasis(toks []*token) {
+	for ci, c := range toks {
+		if c.delim == 0 || !c.canClose {
+			continue
+		}
+		for c.count > 0 {
+			oi := -1
+			for j, seen := ci-1, 0; j >= 0 && seen < maxDelimiterScan; j-- {
+				o := toks[j]
+				if o.delim == 0 {
+					continue
+				}
+				seen++
+				if o.delim != c.delim || !o.canOpen || o.count == 0 || o.inactive {
+					continue
+				}
+				if c.delim == '~' && o.count != c.count {
+					continue
+				}
+				if (o.canClose || c.canOpen) && (o.length+c.length)%3 == 0 &&
+					!(o.length%3 == 0 && c.length%3 == 0) {
+					continue
+				}
+				oi = j
+				break
+			}
+			if oi < 0 {
+				break
+			}
+
+			o := toks[oi]
+			use, tag := 1, "em"
+			switch {
+			case c.delim == '~':
+				use, tag = c.count, "del"
+			case o.count >= 2 && c.count >= 2:
+				use, tag = 2, "strong"
+			}
+			o.count -= use
+			c.count -= use
+			o.openTags = "<" + tag + ">" + o.openTags
+			c.closeTags += "</" + tag + ">"
+			for _, between := range toks[oi+1 : ci] {
+				between.inactive = true
+			}
+		}
+	}
+}
+
+// link renders a link or image starting at the "[" that begins s, and
+// returns the number of bytes it used.
+func link(s string, image bool) (string, int, bool) {
+	end := closingBracket(s)
+	if end < 0 || end+1 >= len(s) || s[end+1] != '(' {
+		return "", 0, false
+	}
+	label := s[1:end]
+
+	dest, title, n, ok := linkDestination(s[end+2:])
+	if !ok {
+		return "", 0, false
+	}
+	used := end + 2 + n
+
+	if image {
+		alt := html.EscapeString(plainText(label))
+		if !safeURL(dest) {
+			return alt, used, true
+		}
+		out := `<img src="` + html.EscapeString(dest) + `" alt="` + alt + `"`
+		if title != "" {
+			out += ` title="` + html.EscapeString(title) + `"`
+		}
+		return out + " />", used, true
+	}
+
+	content := renderInline(label, false)
+	if !safeURL(dest) {
+		return content, used, true
+	}
+	out := `<a href="` + html.EscapeString(dest) + `"`
+	if title != "" {
+		out += ` title="` + html.EscapeString(title) + `"`
+	}
+	return out + ` rel="nofollow noopener noreferrer">` + content + "</a>", used, true
+}
+
+// closingBracket returns the index of the "]" matching the "[" at s[0],
+// looking no further than maxLinkScan bytes.
+func closingBracket(s string) int {
+	if len(s) > maxLinkScan {
+		s = s[:maxLinkScan]
+	}
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '`':
+			run := runLength(s, i, '`')
+			if end := closingBackticks(s, i+run, run); end >= 0 {
+				i = end + run - 1
+			} else {
+				i += run - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// linkDestination parses `dest "title")` after the opening parenthesis,
+// within maxLinkScan bytes.
+func linkDestination(s string) (dest, title string, n int, ok bool) {
+	if len(s) > maxLinkScan {
+		s = s[:maxLinkScan]
+	}
+	i := skipSpace(s, 0)
+
+	if i < len(s) && s[i] == '<' {
+		end := strings.IndexAny(s[i+1:], ">\n")
+		if end < 0 || s[i+1+end] != '>' {
+			return "", "", 0, false
+		}
+		dest = s[i+1 : i+1+end]
+		i += end + 2
+	} else {
+		start, depth := i, 0
+		for ; i < len(s); i++ {
+			c := s[i]
+			if c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+				i++
+				continue
+			}
+			if c <= ' ' {
+				break
+			}
+			if c == '(' {
+				depth++
+			}
+			if c == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		dest = unescapePunct(s[start:i])
+	}
+
+	if j := skipSpace(s, i); j > i && j < len(s) && strings.IndexByte("\"'(", s[j]) >= 0 {
+		closer := s[j]
+		if closer == '(' {
+			closer = ')'
+		}
+		end := strings.IndexByte(s[j+1:], closer)
+		if end < 0 {
+			return "", "", 0, false
+		}
+		title = unescapePunct(s[j+1 : j+1+end])
+		i = j + end + 2
+	}
+
+	i = skipSpace(s, i)
+	if i >= len(s) || s[i] != ')' {
+		return "", "", 0, false
+	}
+	return dest, title, i + 1, true
+}
+
+func autolink(s string, links bool) (string, int, bool) {
+	if !links {
+		return "", 0, false
+	}
+	if m := uriAutolink.FindStringSubmatch(s); m != nil && safeURL(m[1]) {
+		return anchor(m[1], html.EscapeString(m[1])), len(m[0]), true
+	}
+	if m := emailAutolink.FindStringSubmatch(s); m != nil {
+		return anchor("mailto:"+m[1], html.EscapeString(m[1])), len(m[0]), true
+	}
+	return "", 0, false
+}
+
+// matchBareURL returns the URL starting s, without trailing punctuation
+// or an unbalanced closing parenthesis.
+func matchBareURL(s string) string {
+	u := bareURL.FindString(s)
+	for u != "" {
+		last := u[len(u)-1]
+		if strings.IndexByte(trailingURLPunc, last) >= 0 ||
+			(last == ')' && strings.Count(u, "(") < strings.Count(u, ")")) {
+			u = u[:len(u)-1]
+			continue
+		}
+		break
+	}
+	if u == "www." || strings.HasSuffix(u, "://") {
+		return ""
+	}
+	return u
+}
+
+func anchor(href, content string) string {
+	return `<a href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer">` + content + "</a>"
+}
+
+// safeURL reports whether a link target may be rendered: relative URLs
+// and http, https, mailto and tel links. Control characters are refused
+// because browsers strip them, which could reveal a javascript: scheme.
+func safeURL(u string) bool {
+	for i := 0; i < len(u); i++ {
+		if u[i] < 0x20 || u[i] == 0x7f {
+			return false
+		}
+	}
+	m := schemePattern.FindStringSubmatch(u)
+	if m == nil {
+		return true
+	}
+	switch strings.ToLower(m[1]) {
+	case "http", "https", "mailto", "tel":
+		return true
+	}
+	return false
+}
+
+// plainText strips inline markup for image descriptions.
+func plainText(s string) string {
+	return strings.NewReplacer("*", "", "_", "", "`", "", "~", "").Replace(unescapePunct(s))
+}
+
+func unescapePunct(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func runLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+// closingBackticks finds a run of exactly n backticks at or after i.
+func closingBackticks(s string, i, n int) int {
+	for i < len(s) {
+		j := strings.IndexByte(s[i:], '`')
+		if j < 0 {
+			return -1
+		}
+		run := runLength(s, i+j, '`')
+		if run == n {
+			return i + j
+		}
+		i += j + run
+	}
+	return -1
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+func atWordStart(s string, i int) bool {
+	return i == 0 || strings.IndexByte(" \t\n*_~(", s[i-1]) >= 0
+}
+
+func isASCIIPunct(c byte) bool {
+	return c < utf8.RuneSelf && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
@@ -0,0 +1,125 @@
+// Package markdown renders note content written in CommonMark, with
+// GitHub-style task lists and autolinks, to HTML that is safe to insert
+// into a page. Raw HTML in the source is escaped, never passed through.
+package markdown
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// Render converts Markdown source to sanitised HTML.
+func Render(source string) string {
+	_, blocks := parse(source)
+	var b strings.Builder
+	renderBlocks(&b, blocks, false)
+	return b.String()
+}
+
+// ToggleTask flips the checkbox of the task-list item with the given
+// index, counting from zero in document order, and returns the rewritten
+// source. It reports false when there is no such item.
+func ToggleTask(source string, index int) (string, bool) {
+	p, _ := parse(source)
+	if index < 0 || index >= len(p.tasks) {
+		return "", false
+	}
+	t := p.tasks[index]
+
+	lines := strings.Split(source, "\n")
+	l := []byte(lines[t.line])
+	if t.checked {
+		l[t.col+1] = ' '
+	} else {
+		l[t.col+1] = 'x'
+	}
+	lines[t.line] = string(l)
+	return strings.Join(lines, "\n"), true
+}
+
+// renderBlocks writes blocks as HTML. In a tight list, paragraphs are
+// written without <p> tags.
+func renderBlocks(b *strings.Builder, blocks []*block, tight bool) {
+	for _, bl := range blocks {
+		switch bl.kind {
+		case paragraphBlock:
+			text := renderInline(strings.Join(bl.lines, "\n"), true)
+			if tight {
+				b.WriteString(text)
+				continue
+			}
+			b.WriteString("<p>" + text + "</p>\n")
+
+		case headingBlock:
+			tag := "h" + strconv.Itoa(bl.level)
+			text := renderInline(strings.Join(bl.lines, "\n"), true)
+			b.WriteString("<" + tag + ">" + text + "</" + tag + ">\n")
+
+		case codeBlock:
+			b.WriteString("<pre><code")
+			if bl.info != "" {
+				b.WriteString(` class="language-` + html.EscapeString(unescapePunct(bl.info)) + `"`)
+			}
+			b.WriteString(">")
+			for _, l := range bl.lines {
+				b.WriteString(html.EscapeString(l) + "\n")
+			}
+			b.WriteString("</code></pre>\n")
+
+		case quoteBlock:
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, bl.children, false)
+			b.WriteString("</blockquote>\n")
+
+		case listBlock:
+			renderList(b, bl)
+
+		case ruleBlock:
+			b.WriteString("<hr />\n")
+		}
+	}
+}
+
+func renderList(b *strings.Builder, list *block) {
+	tag := "ul"
+	if list.ordered {
+		tag = "ol"
+	}
+	b.WriteString("<" + tag)
+	if list.ordered && list.start != 1 {
+		b.WriteString(` start="` + strconv.Itoa(list.start) + `"`)
+	}
+	b.WriteString(">\n")
+
+	for _, item := range list.children {
+		if item.task != nil {
+			b.WriteString(`<li class="task-list-item"><input type="checkbox" data-task="` + strconv.Itoa(item.task.index) + `"`)
+			if item.task.checked {
+				b.WriteString(" checked")
+			}
+			b.WriteString(" /> ")
+		} else {
+			b.WriteString("<li>")
+		}
+
+		// Block children other than a leading paragraph start on their own
+		// line, as do all children of a loose item.
+		for i, child := range item.children {
+			if !list.loose && child.kind == paragraphBlock {
+				if i > 0 {
+					b.WriteString("\n")
+				}
+				renderBlocks(b, []*block{child}, true)
+				continue
+			}
+			if i == 0 || !list.loose && item.children[i-1].kind == paragraphBlock {
+				b.WriteString("\n")
+			}
+			renderBlocks(b, []*block{child}, false)
+		}
+		b.WriteString("</li>\n")
+	}
+
+	b.WriteString("</" + tag + ">\n")
+}
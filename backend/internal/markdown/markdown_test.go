@@ -0,0 +1,104 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"paragraph", "Hello *world*", "<p>Hello <em>world</em></p>\n"},
+		{"emphasis", "**bold** ~~gone~~ `a < b`", "<p><strong>bold</strong> <del>gone</del> <code>a &lt; b</code></p>\n"},
+		{"intraword underscore", "snake_case_name", "<p>snake_case_name</p>\n"},
+		{"heading", "## Shopping ##", "<h2>Shopping</h2>\n"},
+		{"setext heading", "Title\n=====", "<h1>Title</h1>\n"},
+		{"fenced code", "```go\nx := \"<b>\"\n```", "<pre><code class=\"language-go\">x := &#34;&lt;b&gt;&#34;\n</code></pre>\n"},
+		{"blockquote", "> quoted\nlazy", "<blockquote>\n<p>quoted\nlazy</p>\n</blockquote>\n"},
+		{"rule", "a\n\n***", "<p>a</p>\n<hr />\n"},
+		{"hard break", "one  \ntwo\\\nthree", "<p>one<br />\ntwo<br />\nthree</p>\n"},
+		{"tight list", "- a\n- b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n"},
+		{"loose list", "- a\n\n- b", "<ul>\n<li>\n<p>a</p>\n</li>\n<li>\n<p>b</p>\n</li>\n</ul>\n"},
+		{"ordered list", "3. a\n4. b", "<ol start=\"3\">\n<li>a</li>\n<li>b</li>\n</ol>\n"},
+		{"nested list", "- a\n  - b", "<ul>\n<li>a\n<ul>\n<li>b</li>\n</ul>\n</li>\n</ul>\n"},
+		{
+			"task list", "- [ ] milk\n- [x] eggs",
+			"<ul>\n<li class=\"task-list-item\"><input type=\"checkbox\" data-task=\"0\" /> milk</li>\n" +
+				"<li class=\"task-list-item\"><input type=\"checkbox\" data-task=\"1\" checked /> eggs</li>\n</ul>\n",
+		},
+		{
+			"link", "[docs](https://example.com \"Docs\")",
+			"<p><a href=\"https://example.com\" title=\"Docs\" rel=\"nofollow noopener noreferrer\">docs</a></p>\n",
+		},
+		{
+			"autolink", "<https://example.com>",
+			"<p><a href=\"https://example.com\" rel=\"nofollow noopener noreferrer\">https://example.com</a></p>\n",
+		},
+		{
+			"bare url", "See www.example.com/a.",
+			"<p>See <a href=\"http://www.example.com/a\" rel=\"nofollow noopener noreferrer\">www.example.com/a</a>.</p>\n",
+		},
+		{"image", "![a cat](/cat.png)", "<p><img src=\"/cat.png\" alt=\"a cat\" /></p>\n"},
+		{"entities", "&copy; & &amp;", "<p>© &amp; &amp;</p>\n"},
+
+		{"raw html", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"inline handler", "<img src=x onerror=alert(1)>", "<p>&lt;img src=x onerror=alert(1)&gt;</p>\n"},
+		{"javascript link", "[click](javascript:alert(1))", "<p>click</p>\n"},
+		{"javascript autolink", "<javascript:alert(1)>", "<p>&lt;javascript:alert(1)&gt;</p>\n"},
+		{"data image", "![x](data:text/html,hi)", "<p>x</p>\n"},
+		{"encoded scheme", "[x](&#106;avascript:alert(1))", "<p><a href=\"&amp;#106;avascript:alert(1)\" rel=\"nofollow noopener noreferrer\">x</a></p>\n"},
+		{"attribute break", "[x](https://a.b/\"onmouseover=\"alert(1))", "<p><a href=\"https://a.b/&#34;onmouseover=&#34;alert(1)\" rel=\"nofollow noopener noreferrer\">x</a></p>\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.source); got != tt.want {
+				t.Errorf("Render(%q)\n got %q\nwant %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderPathologicalInput(t *testing.T) {
+	tests := map[string]string{
+		"unmatched emphasis":   strings.Repeat("a*", 40000),
+		"unclosed images":      strings.Repeat("![", 20000),
+		"unclosed links":       strings.Repeat("[a](", 20000),
+		"unclosed code labels": strings.Repeat("[`", 20000),
+	}
+	for name, source := range tests {
+		start := time.Now()
+		Render(source)
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("%s: rendering %d bytes took %s", name, len(source), elapsed)
+		}
+	}
+}
+
+func TestToggleTask(t *testing.T) {
+	source := "Todo:\n\n- [ ] milk\n- [x] eggs\n  - [ ] free range\n\n> * [ ] quoted\n\n`- [ ] not a task`"
+
+	tests := []struct {
+		index int
+		want  string
+		ok    bool
+	}{
+		{0, "Todo:\n\n- [x] milk\n- [x] eggs\n  - [ ] free range\n\n> * [ ] quoted\n\n`- [ ] not a task`", true},
+		{1, "Todo:\n\n- [ ] milk\n- [ ] eggs\n  - [ ] free range\n\n> * [ ] quoted\n\n`- [ ] not a task`", true},
+		{2, "Todo:\n\n- [ ] milk\n- [x] eggs\n  - [x] free range\n\n> * [ ] quoted\n\n`- [ ] not a task`", true},
+		{3, "Todo:\n\n- [ ] milk\n- [x] eggs\n  - [ ] free range\n\n> * [x] quoted\n\n`- [ ] not a task`", true},
+		{4, "", false},
+		{-1, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ToggleTask(source, tt.index)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ToggleTask(%d) = %q, %v; want %q, %v", tt.index, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package mealplan
+
+import "time"
+
+// DateLayout is the format of meal plan dates.
+const DateLayout = "2006-01-02"
+
+type Meal string
+
+const (
+	MealBreakfast Meal = "breakfast"
+	MealLunch     Meal = "lunch"
+	MealDinner    Meal = "dinner"
+	MealSnack     Meal = "snack"
+)
+
+// Entry assigns a recipe to a meal on a given date.
+type Entry struct {
+	ID         int64     `json:"id"`
+	Date       string    `json:"date"`
+	Meal       Meal      `json:"meal"`
+	RecipeID   int64     `json:"recipeId"`
+	RecipeName string    `json:"recipeName"`
+	Servings   int       `json:"servings"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type CreateRequest struct {
+	Date     string `json:"date"`
+	Meal     Meal   `json:"meal"`
+	RecipeID int64  `json:"recipeId"`
+	// Servings defaults to the recipe's own servings.
+	Servings int    `json:"servings"`
+	Note     string `json:"note"`
+}
+
+func (r CreateRequest) Validate() error {
+	if !isValidDate(r.Date) {
+		return ErrValidation("date must be in YYYY-MM-DD format")
+	}
+	if r.Meal != "" && !isValidMeal(r.Meal) {
+		return ErrValidation("invalid meal: must be breakfast, lunch, dinner, or snack")
+	}
+	if r.RecipeID == 0 {
+		return ErrValidation("recipeId is required")
+	}
+	if r.Servings < 0 {
+		return ErrValidation("servings must be positive")
+	}
+	return nil
+}
+
+type UpdateRequest struct {
+	Date     *string `json:"date,omitempty"`
+	Meal     *Meal   `json:"meal,omitempty"`
+	RecipeID *int64  `json:"recipeId,omitempty"`
+	Servings *int    `json:"servings,omitempty"`
+	Note     *string `json:"note,omitempty"`
+}
+
+func (r UpdateRequest) Validate() error {
+	if r.Date != nil && !isValidDate(*r.Date) {
+		return ErrValidation("date must be in YYYY-MM-DD format")
+	}
+	if r.Meal != nil && !isValidMeal(*r.Meal) {
+		return ErrValidation("invalid meal: must be breakfast, lunch, dinner, or snack")
+	}
+	if r.Servings != nil && *r.Servings <= 0 {
+		return ErrValidation("servings must be positive")
+	}
+	return nil
+}
+
+// ShoppingRequest asks for the ingredients of every entry between From and
+// To (inclusive) to be added to a shopping list.
+type ShoppingRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// ListID selects the shopping list; the default list is used when nil.
+	ListID *int64 `json:"listId,omitempty"`
+}
+
+func (r ShoppingRequest) Validate() error {
+	if !isValidDate(r.From) || !isValidDate(r.To) {
+		return ErrValidation("from and to must be in YYYY-MM-DD format")
+	}
+	if r.To < r.From {
+		return ErrValidation("to must not be before from")
+	}
+	return nil
+}
+
+func isValidDate(s string) bool {
+	_, err := time.Parse(DateLayout, s)
+	return err == nil
+}
+
+func isValidMeal(m Meal) bool {
+	return m == MealBreakfast || m == MealLunch || m == MealDinner || m == MealSnack
+}
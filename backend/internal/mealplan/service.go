@@ -0,0 +1,198 @@
+package mealplan
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/recipe"
+	"github.com/stadtaev/lofam/backend/internal/shopping"
+	"github.com/stadtaev/lofam/backend/internal/unit"
+)
+
+// Recipes looks up the recipes that entries refer to.
+type Recipes interface {
+	GetByID(ctx context.Context, id int64) (*recipe.Recipe, error)
+}
+
+// ShoppingList receives the ingredients generated from the plan.
+type ShoppingList interface {
+	AddOrMerge(ctx context.Context, req shopping.CreateRequest) (*shopping.Item, bool, error)
+}
+
+type Service struct {
+	store    Store
+	recipes  Recipes
+	shopping ShoppingList
+}
+
+func NewService(store Store, recipes Recipes, shopping ShoppingList) *Service {
+	return &Service{store: store, recipes: recipes, shopping: shopping}
+}
+
+func (s *Service) Create(ctx context.Context, req CreateRequest) (*Entry, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	r, err := s.recipes.GetByID(ctx, req.RecipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	meal := req.Meal
+	if meal == "" {
+		meal = MealDinner
+	}
+	servings := req.Servings
+	if servings == 0 {
+		servings = r.Servings
+	}
+
+	e := &Entry{
+		Date:       req.Date,
+		Meal:       meal,
+		RecipeID:   r.ID,
+		RecipeName: r.Name,
+		Servings:   servings,
+		Note:       req.Note,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.store.Create(ctx, e); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int64) (*Entry, error) {
+	return s.store.GetByID(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context, from, to string) ([]Entry, error) {
+	if (from != "" && !isValidDate(from)) || (to != "" && !isValidDate(to)) {
+		return nil, ErrValidation("from and to must be in YYYY-MM-DD format")
+	}
+	return s.store.List(ctx, from, to)
+}
+
+func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Entry, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	e, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Date != nil {
+		e.Date = *req.Date
+	}
+	if req.Meal != nil {
+		e.Meal = *req.Meal
+	}
+	if req.RecipeID != nil && *req.RecipeID != e.RecipeID {
+		r, err := s.recipes.GetByID(ctx, *req.RecipeID)
+		if err != nil {
+			return nil, err
+		}
+		e.RecipeID = r.ID
+		e.RecipeName = r.Name
+	}
+	if req.Servings != nil {
+		e.Servings = *req.Servings
+	}
+	if req.Note != nil {
+		e.Note = *req.Note
+	}
+
+	if err := s.store.Update(ctx, e); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	return s.store.Delete(ctx, id)
+}
+
+// ShoppingResult reports the shopping items touched by GenerateShopping.
+type ShoppingResult struct {
+	Items   []shopping.Item `json:"items"`
+	Created int             `json:"created"`
+	Merged  int             `json:"merged"`
+}
+
+// GenerateShopping adds the ingredients of every planned meal in the range to
+// a shopping list. Quantities are scaled from each recipe's servings to the
+// planned servings, identical ingredients are combined, and ingredients that
+// are already pending on the list increase the existing item's quantity.
+// Ingredients without a quantity are left off.
+func (s *Service) GenerateShopping(ctx context.Context, req ShoppingRequest) (*ShoppingResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.store.List(ctx, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	var needed []recipe.Ingredient
+	index := make(map[string]int)
+	recipes := make(map[int64]*recipe.Recipe)
+
+	for _, e := range entries {
+		r, ok := recipes[e.RecipeID]
+		if !ok {
+			if r, err = s.recipes.GetByID(ctx, e.RecipeID); err != nil {
+				return nil, err
+			}
+			recipes[e.RecipeID] = r
+		}
+
+		scale := float64(e.Servings) / float64(r.Servings)
+		for _, ing := range r.Ingredients {
+			key := shopping.NormalizeTitle(ing.Name) + "\x00" + unit.Normalize(ing.Unit)
+			if i, ok := index[key]; ok {
+				needed[i].Quantity += ing.Quantity * scale
+				continue
+			}
+			index[key] = len(needed)
+			needed = append(needed, recipe.Ingredient{
+				Name:     ing.Name,
+				Unit:     unit.Normalize(ing.Unit),
+				Quantity: ing.Quantity * scale,
+			})
+		}
+	}
+
+	result := &ShoppingResult{Items: []shopping.Item{}}
+	for _, ing := range needed {
+		// Ingredients without an amount ("salt to taste") are kept in the
+		// cupboard rather than bought, and shopping would count them as 1.
+		if ing.Quantity == 0 {
+			continue
+		}
+		item, merged, err := s.shopping.AddOrMerge(ctx, shopping.CreateRequest{
+			ListID:   req.ListID,
+			Title:    ing.Name,
+			Quantity: math.Round(ing.Quantity*100) / 100,
+			Unit:     ing.Unit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if merged {
+			result.Merged++
+		} else {
+			result.Created++
+		}
+		result.Items = append(result.Items, *item)
+	}
+
+	return result, nil
+}
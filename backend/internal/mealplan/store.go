@@ -0,0 +1,13 @@
+package mealplan
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, e *Entry) error
+	GetByID(ctx context.Context, id int64) (*Entry, error)
+	// List returns the entries between from and to inclusive; empty bounds
+	// are open.
+	List(ctx context.Context, from, to string) ([]Entry, error)
+	Update(ctx context.Context, e *Entry) error
+	Delete(ctx context.Context, id int64) error
+}
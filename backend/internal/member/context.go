@@ -0,0 +1,16 @@
+package member
+
+import "context"
+
+type contextKey struct{}
+
+// WithID returns a context that identifies the member making a request.
+func WithID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// IDFromContext returns the member making the request, if known.
+func IDFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(contextKey{}).(int64)
+	return id, ok
+}
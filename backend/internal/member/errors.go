@@ -0,0 +1,27 @@
+package member
+
+import "fmt"
+
+type ValidationError struct {
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Message
+}
+
+func ErrValidation(msg string) ValidationError {
+	return ValidationError{Message: msg}
+}
+
+type NotFoundError struct {
+	ID int64
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("member with id %d not found", e.ID)
+}
+
+func ErrNotFound(id int64) NotFoundError {
+	return NotFoundError{ID: id}
+}
@@ -0,0 +1,33 @@
+package member
+
+import "time"
+
+// Member is a person in the household.
+type Member struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CreateRequest struct {
+	Name string `json:"name"`
+}
+
+func (r CreateRequest) Validate() error {
+	if r.Name == "" {
+		return ErrValidation("name is required")
+	}
+	return nil
+}
+
+type UpdateRequest struct {
+	Name string `json:"name"`
+}
+
+func (r UpdateRequest) Validate() error {
+	if r.Name == "" {
+		return ErrValidation("name is required")
+	}
+	return nil
+}
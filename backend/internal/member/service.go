@@ -0,0 +1,65 @@
+package member
+
+import (
+	"context"
+	"time"
+)
+
+type Service struct {
+	store Store
+}
+
+func NewService(store Store) *Service {
+	return &Service{store: store}
+}
+
+func (s *Service) Create(ctx context.Context, req CreateRequest) (*Member, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	m := &Member{
+		Name:      req.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.store.Create(ctx, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int64) (*Member, error) {
+	return s.store.GetByID(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context) ([]Member, error) {
+	return s.store.List(ctx)
+}
+
+func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Member, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	m, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Name = req.Name
+	m.UpdatedAt = time.Now()
+
+	if err := s.store.Update(ctx, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	return s.store.Delete(ctx, id)
+}
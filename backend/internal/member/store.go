@@ -0,0 +1,12 @@
+package member
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, m *Member) error
+	GetByID(ctx context.Context, id int64) (*Member, error)
+	List(ctx context.Context) ([]Member, error)
+	Update(ctx context.Context, m *Member) error
+	// Delete removes a member and detaches everything that refers to them.
+	Delete(ctx context.Context, id int64) error
+}
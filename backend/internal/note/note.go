@@ -1,56 +1,101 @@
 package note
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
-type Color string
+// maxContentBytes bounds a note's Markdown, which is rendered on every
+// listing.
+const maxContentBytes = 64 << 10
 
-const (
-	ColorYellow Color = "yellow"
-	ColorPink   Color = "pink"
-	ColorGreen  Color = "green"
-)
+// Color names an entry of the configurable palette.
+type Color string
 
 type Note struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	Color     Color     `json:"color"`
+	ID      int64  `json:"id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	// HTML is Content rendered from Markdown and sanitised; it is not
+	// stored.
+	HTML     string `json:"html"`
+	Color    Color  `json:"color"`
+	Pinned   bool   `json:"pinned"`
+	Archived bool   `json:"archived"`
+	// Position is the manual sort order; lower comes first. Pinned notes
+	// are always listed before the rest.
+	Position  int       `json:"position"`
+	Tags      []string  `json:"tags"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// Filter narrows List results. A nil Archived and an empty Tag match every
+// note.
+type Filter struct {
+	Archived *bool
+	Tag      string
+}
+
 type CreateRequest struct {
 	Title   string `json:"title"`
 	Content string `json:"content"`
 	Color   Color  `json:"color"`
+	Pinned  bool   `json:"pinned"`
 }
 
 func (r CreateRequest) Validate() error {
 	if r.Title == "" {
 		return ErrValidation("title is required")
 	}
-	if !isValidColor(r.Color) {
-		return ErrValidation("color must be yellow, pink, or green")
+	if r.Color == "" {
+		return ErrValidation("color is required")
 	}
-	return nil
+	return validateContent(r.Content)
 }
 
 type UpdateRequest struct {
 	Title   string `json:"title"`
 	Content string `json:"content"`
 	Color   Color  `json:"color"`
+	// Pinned and Archived are left unchanged when omitted.
+	Pinned   *bool `json:"pinned,omitempty"`
+	Archived *bool `json:"archived,omitempty"`
 }
 
 func (r UpdateRequest) Validate() error {
 	if r.Title == "" {
 		return ErrValidation("title is required")
 	}
-	if !isValidColor(r.Color) {
-		return ErrValidation("color must be yellow, pink, or green")
+	if r.Color == "" {
+		return ErrValidation("color is required")
+	}
+	return validateContent(r.Content)
+}
+
+func validateContent(content string) error {
+	if len(content) > maxContentBytes {
+		return ErrValidation(fmt.Sprintf("content must be at most %d bytes", maxContentBytes))
 	}
 	return nil
 }
 
-func isValidColor(c Color) bool {
-	return c == ColorYellow || c == ColorPink || c == ColorGreen
+// ReorderRequest lists notes in their new order. Notes left out keep their
+// relative order after the listed ones.
+type ReorderRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+func (r ReorderRequest) Validate() error {
+	if len(r.IDs) == 0 {
+		return ErrValidation("ids are required")
+	}
+	seen := make(map[int64]bool, len(r.IDs))
+	for _, id := range r.IDs {
+		if seen[id] {
+			return ErrValidation("ids must not contain duplicates")
+		}
+		seen[id] = true
+	}
+	return nil
 }
@@ -2,27 +2,37 @@ package note
 
 import (
 	"context"
+	"fmt"
 	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/markdown"
+	"github.com/stadtaev/lofam/backend/internal/palette"
 )
 
 type Service struct {
-	store Store
+	store  Store
+	colors palette.Checker
 }
 
-func NewService(store Store) *Service {
-	return &Service{store: store}
+func NewService(store Store, colors palette.Checker) *Service {
+	return &Service{store: store, colors: colors}
 }
 
 func (s *Service) Create(ctx context.Context, req CreateRequest) (*Note, error) {
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
+	if err := s.colors.Check(ctx, string(req.Color)); err != nil {
+		return nil, err
+	}
 
 	now := time.Now()
 	n := &Note{
 		Title:     req.Title,
 		Content:   req.Content,
 		Color:     req.Color,
+		Pinned:    req.Pinned,
+		Tags:      []string{},
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -30,22 +40,40 @@ func (s *Service) Create(ctx context.Context, req CreateRequest) (*Note, error)
 	if err := s.store.Create(ctx, n); err != nil {
 		return nil, err
 	}
+	n.HTML = markdown.Render(n.Content)
 
 	return n, nil
 }
 
 func (s *Service) GetByID(ctx context.Context, id int64) (*Note, error) {
-	return s.store.GetByID(ctx, id)
+	n, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	n.HTML = markdown.Render(n.Content)
+	return n, nil
 }
 
-func (s *Service) List(ctx context.Context) ([]Note, error) {
-	return s.store.List(ctx)
+// List returns pinned notes first, then the rest, each in their manual
+// order.
+func (s *Service) List(ctx context.Context, filter Filter) ([]Note, error) {
+	notes, err := s.store.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range notes {
+		notes[i].HTML = markdown.Render(notes[i].Content)
+	}
+	return notes, nil
 }
 
 func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Note, error) {
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
+	if err := s.colors.Check(ctx, string(req.Color)); err != nil {
+		return nil, err
+	}
 
 	n, err := s.store.GetByID(ctx, id)
 	if err != nil {
@@ -55,11 +83,42 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Not
 	n.Title = req.Title
 	n.Content = req.Content
 	n.Color = req.Color
+	if req.Pinned != nil {
+		n.Pinned = *req.Pinned
+	}
+	if req.Archived != nil {
+		n.Archived = *req.Archived
+	}
 	n.UpdatedAt = time.Now()
 
 	if err := s.store.Update(ctx, n); err != nil {
 		return nil, err
 	}
+	n.HTML = markdown.Render(n.Content)
+
+	return n, nil
+}
+
+// ToggleTask ticks or unticks a task-list checkbox in the note's content.
+// Checkboxes are numbered from zero in document order, as in the
+// data-task attribute of the rendered HTML.
+func (s *Service) ToggleTask(ctx context.Context, id int64, index int) (*Note, error) {
+	n, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	content, ok := markdown.ToggleTask(n.Content, index)
+	if !ok {
+		return nil, ErrValidation(fmt.Sprintf("note has no task %d", index))
+	}
+	n.Content = content
+	n.UpdatedAt = time.Now()
+
+	if err := s.store.Update(ctx, n); err != nil {
+		return nil, err
+	}
+	n.HTML = markdown.Render(n.Content)
 
 	return n, nil
 }
@@ -67,3 +126,10 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Not
 func (s *Service) Delete(ctx context.Context, id int64) error {
 	return s.store.Delete(ctx, id)
 }
+
+func (s *Service) Reorder(ctx context.Context, req ReorderRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	return s.store.Reorder(ctx, req.IDs)
+}
@@ -5,7 +5,10 @@ import "context"
 type Store interface {
 	Create(ctx context.Context, n *Note) error
 	GetByID(ctx context.Context, id int64) (*Note, error)
-	List(ctx context.Context) ([]Note, error)
+	List(ctx context.Context, filter Filter) ([]Note, error)
 	Update(ctx context.Context, n *Note) error
 	Delete(ctx context.Context, id int64) error
+	// Reorder moves the given notes to the front of the manual order, in
+	// the order given, and returns NotFoundError for an unknown id.
+	Reorder(ctx context.Context, ids []int64) error
 }
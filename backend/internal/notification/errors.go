@@ -0,0 +1,41 @@
+package notification
+
+import "fmt"
+
+type ValidationError struct {
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Message
+}
+
+func ErrValidation(msg string) ValidationError {
+	return ValidationError{Message: msg}
+}
+
+type NotFoundError struct {
+	ID int64
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("notification with id %d not found", e.ID)
+}
+
+func ErrNotFound(id int64) NotFoundError {
+	return NotFoundError{ID: id}
+}
+
+// ForbiddenError is returned when notifications are requested without
+// identifying a household member.
+type ForbiddenError struct {
+	Message string
+}
+
+func (e ForbiddenError) Error() string {
+	return e.Message
+}
+
+func ErrForbidden(msg string) ForbiddenError {
+	return ForbiddenError{Message: msg}
+}
@@ -0,0 +1,29 @@
+package notification
+
+import "time"
+
+type Kind string
+
+const (
+	// KindMention tells a member they were mentioned in a comment.
+	KindMention Kind = "mention"
+)
+
+// Notification is a message for one household member.
+type Notification struct {
+	ID       int64 `json:"id"`
+	MemberID int64 `json:"memberId"`
+	Kind     Kind  `json:"kind"`
+	// ActorID is the member whose action caused the notification.
+	ActorID   *int64     `json:"actorId,omitempty"`
+	TaskID    *int64     `json:"taskId,omitempty"`
+	CommentID *int64     `json:"commentId,omitempty"`
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"readAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Filter narrows List results; zero values match everything.
+type Filter struct {
+	Unread bool
+}
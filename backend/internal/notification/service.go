@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/member"
+)
+
+type Service struct {
+	store Store
+}
+
+func NewService(store Store) *Service {
+	return &Service{store: store}
+}
+
+// Notify stores a notification for its member.
+func (s *Service) Notify(ctx context.Context, n *Notification) error {
+	if n.MemberID == 0 {
+		return ErrValidation("member is required")
+	}
+	if n.Message == "" {
+		return ErrValidation("message is required")
+	}
+	n.ReadAt = nil
+	n.CreatedAt = time.Now()
+	return s.store.Create(ctx, n)
+}
+
+// List returns the requesting member's notifications, newest first.
+func (s *Service) List(ctx context.Context, filter Filter) ([]Notification, error) {
+	memberID, err := recipient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.List(ctx, memberID, filter)
+}
+
+// MarkRead marks one of the requesting member's notifications as read.
+func (s *Service) MarkRead(ctx context.Context, id int64) (*Notification, error) {
+	memberID, err := recipient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Other members' notifications are reported as missing.
+	if n.MemberID != memberID {
+		return nil, ErrNotFound(id)
+	}
+	if n.ReadAt != nil {
+		return n, nil
+	}
+
+	now := time.Now()
+	if err := s.store.MarkRead(ctx, memberID, []int64{id}, now); err != nil {
+		return nil, err
+	}
+	n.ReadAt = &now
+	return n, nil
+}
+
+// MarkAllRead marks every notification of the requesting member as read.
+func (s *Service) MarkAllRead(ctx context.Context) error {
+	memberID, err := recipient(ctx)
+	if err != nil {
+		return err
+	}
+	return s.store.MarkRead(ctx, memberID, nil, time.Now())
+}
+
+func recipient(ctx context.Context) (int64, error) {
+	memberID, ok := member.IDFromContext(ctx)
+	if !ok {
+		return 0, ErrForbidden("notifications require a household member")
+	}
+	return memberID, nil
+}
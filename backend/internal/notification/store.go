@@ -0,0 +1,16 @@
+package notification
+
+import (
+	"context"
+	"time"
+)
+
+type Store interface {
+	Create(ctx context.Context, n *Notification) error
+	GetByID(ctx context.Context, id int64) (*Notification, error)
+	// List returns a member's notifications, newest first.
+	List(ctx context.Context, memberID int64, filter Filter) ([]Notification, error)
+	// MarkRead sets the read time of the given notifications, or of all of
+	// the member's unread notifications when ids is nil.
+	MarkRead(ctx context.Context, memberID int64, ids []int64, at time.Time) error
+}
@@ -0,0 +1,114 @@
+package occasion
+
+import (
+	"regexp"
+	"time"
+)
+
+// DateLayout is the format of occasion dates.
+const DateLayout = "2006-01-02"
+
+// Occasion is a birthday, holiday or other event that gifts are bought for.
+type Occasion struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// Person is who the occasion is for; MemberID links them when they are
+	// part of the household.
+	Person   string `json:"person"`
+	MemberID *int64 `json:"memberId"`
+	Date     string `json:"date"`
+	// Yearly occasions repeat on the same day every year.
+	Yearly bool `json:"yearly"`
+	// NextDate is the next time the occasion happens, today included. For
+	// one-off occasions in the past it is the original date.
+	NextDate    string    `json:"nextDate"`
+	BudgetCents *int64    `json:"budgetCents"`
+	Currency    string    `json:"currency"`
+	WishlistIDs []int64   `json:"wishlistIds"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type CreateRequest struct {
+	Name        string  `json:"name"`
+	Person      string  `json:"person"`
+	MemberID    *int64  `json:"memberId,omitempty"`
+	Date        string  `json:"date"`
+	Yearly      bool    `json:"yearly"`
+	BudgetCents *int64  `json:"budgetCents,omitempty"`
+	Currency    string  `json:"currency"`
+	WishlistIDs []int64 `json:"wishlistIds"`
+}
+
+func (r CreateRequest) Validate() error {
+	if r.Name == "" {
+		return ErrValidation("name is required")
+	}
+	if !isValidDate(r.Date) {
+		return ErrValidation("date must be in YYYY-MM-DD format")
+	}
+	if r.BudgetCents != nil && *r.BudgetCents < 0 {
+		return ErrValidation("budgetCents must not be negative")
+	}
+	return validateCurrency(r.Currency)
+}
+
+// UpdateRequest changes the given fields. A MemberID of 0 unlinks the
+// member, a negative BudgetCents removes the budget, and WishlistIDs
+// replaces the linked wishlists.
+type UpdateRequest struct {
+	Name        *string  `json:"name,omitempty"`
+	Person      *string  `json:"person,omitempty"`
+	MemberID    *int64   `json:"memberId,omitempty"`
+	Date        *string  `json:"date,omitempty"`
+	Yearly      *bool    `json:"yearly,omitempty"`
+	BudgetCents *int64   `json:"budgetCents,omitempty"`
+	Currency    *string  `json:"currency,omitempty"`
+	WishlistIDs *[]int64 `json:"wishlistIds,omitempty"`
+}
+
+func (r UpdateRequest) Validate() error {
+	if r.Name != nil && *r.Name == "" {
+		return ErrValidation("name must not be empty")
+	}
+	if r.Date != nil && !isValidDate(*r.Date) {
+		return ErrValidation("date must be in YYYY-MM-DD format")
+	}
+	if r.Currency != nil {
+		return validateCurrency(*r.Currency)
+	}
+	return nil
+}
+
+// NextOccurrence returns the first day on or after today that the occasion
+// falls on. Yearly occasions on 29 February fall on 1 March in other years.
+func NextOccurrence(date string, yearly bool, today time.Time) string {
+	d, err := time.Parse(DateLayout, date)
+	if err != nil || !yearly {
+		return date
+	}
+
+	todayDate := today.Format(DateLayout)
+	next := time.Date(today.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	if next.Format(DateLayout) < todayDate {
+		next = time.Date(today.Year()+1, d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if next.Before(d) {
+		return date
+	}
+	return next.Format(DateLayout)
+}
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+func validateCurrency(c string) error {
+	if c != "" && !currencyPattern.MatchString(c) {
+		return ErrValidation("currency must be a three-letter ISO 4217 code such as EUR")
+	}
+	return nil
+}
+
+func isValidDate(s string) bool {
+	_, err := time.Parse(DateLayout, s)
+	return err == nil
+}
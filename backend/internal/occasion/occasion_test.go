@@ -0,0 +1,97 @@
+package occasion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/wishlist"
+)
+
+func TestNextOccurrence(t *testing.T) {
+	today := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		date   string
+		yearly bool
+		want   string
+	}{
+		{"one-off in the future", "2025-08-01", false, "2025-08-01"},
+		{"one-off in the past", "2024-08-01", false, "2024-08-01"},
+		{"yearly later this year", "1990-08-01", true, "2025-08-01"},
+		{"yearly earlier this year", "1990-03-01", true, "2026-03-01"},
+		{"yearly today", "1990-06-15", true, "2025-06-15"},
+		{"yearly starting in the future", "2027-03-01", true, "2027-03-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NextOccurrence(tt.date, tt.yearly, today); got != tt.want {
+				t.Errorf("NextOccurrence(%q, %v) = %q, want %q", tt.date, tt.yearly, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	price := func(cents int64) *int64 { return &cents }
+	anna, ben := int64(1), int64(2)
+
+	lists := []wishlist.Wishlist{
+		{Items: []wishlist.Item{
+			{Name: "Book", PriceCents: price(2000), Currency: "EUR", Quantity: 1,
+				Reservation: &wishlist.Reservation{MemberID: &anna, Status: wishlist.ReservationBought}},
+			{Name: "Socks", PriceCents: price(500), Quantity: 2,
+				Reservation: &wishlist.Reservation{MemberID: &ben, Status: wishlist.ReservationReserved}},
+			{Name: "Unclaimed", PriceCents: price(9900), Currency: "EUR", Quantity: 1},
+		}},
+		{Items: []wishlist.Item{
+			{Name: "Puzzle", PriceCents: price(3500), Currency: "EUR", Quantity: 1,
+				Reservation: &wishlist.Reservation{GuestName: "Grandma", Status: wishlist.ReservationReserved}},
+			{Name: "Mystery", Quantity: 1,
+				Reservation: &wishlist.Reservation{MemberID: &anna, Status: wishlist.ReservationReserved}},
+			{Name: "Souvenir", PriceCents: price(1000), Currency: "USD", Quantity: 1,
+				Reservation: &wishlist.Reservation{MemberID: &ben, Status: wishlist.ReservationReserved}},
+		}},
+	}
+	o := &Occasion{ID: 7, BudgetCents: price(5000), Currency: "EUR"}
+
+	s := summarize(o, lists, map[int64]string{anna: "Anna", ben: "Ben"})
+
+	if s.BoughtCents != 2000 || s.ReservedCents != 4500 || s.TotalCents != 6500 {
+		t.Errorf("totals = bought %d, reserved %d, total %d; want 2000, 4500, 6500",
+			s.BoughtCents, s.ReservedCents, s.TotalCents)
+	}
+	if s.RemainingCents == nil || *s.RemainingCents != -1500 {
+		t.Errorf("remaining = %v, want -1500", s.RemainingCents)
+	}
+	if s.ExcludedItems != 2 {
+		t.Errorf("excluded = %d, want 2", s.ExcludedItems)
+	}
+
+	want := []struct {
+		name  string
+		total int64
+	}{{"Grandma", 3500}, {"Anna", 2000}, {"Ben", 1000}}
+	if len(s.Givers) != len(want) {
+		t.Fatalf("givers = %+v, want %d", s.Givers, len(want))
+	}
+	for i, w := range want {
+		if s.Givers[i].Name != w.name || s.Givers[i].TotalCents != w.total {
+			t.Errorf("giver %d = %s %d, want %s %d", i, s.Givers[i].Name, s.Givers[i].TotalCents, w.name, w.total)
+		}
+	}
+	if s.Givers[0].MemberID != nil {
+		t.Errorf("guest giver has member id %d", *s.Givers[0].MemberID)
+	}
+}
+
+func TestSummarizeWithoutBudget(t *testing.T) {
+	s := summarize(&Occasion{ID: 1}, nil, nil)
+	if s.RemainingCents != nil {
+		t.Errorf("remaining = %d, want nil", *s.RemainingCents)
+	}
+	if s.Givers == nil {
+		t.Error("givers should be an empty slice")
+	}
+}
@@ -0,0 +1,211 @@
+package occasion
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/member"
+	"github.com/stadtaev/lofam/backend/internal/wishlist"
+)
+
+// Wishlists looks up the wishlists linked to occasions. Reservations must
+// already be hidden from the requesting member where appropriate.
+type Wishlists interface {
+	GetByID(ctx context.Context, id int64) (*wishlist.Wishlist, error)
+}
+
+// Members looks up the people occasions are for and the givers in a
+// summary.
+type Members interface {
+	GetByID(ctx context.Context, id int64) (*member.Member, error)
+	List(ctx context.Context) ([]member.Member, error)
+}
+
+type Service struct {
+	store     Store
+	wishlists Wishlists
+	members   Members
+}
+
+func NewService(store Store, wishlists Wishlists, members Members) *Service {
+	return &Service{store: store, wishlists: wishlists, members: members}
+}
+
+func (s *Service) Create(ctx context.Context, req CreateRequest) (*Occasion, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkMember(ctx, req.MemberID); err != nil {
+		return nil, err
+	}
+	wishlistIDs, err := s.checkWishlists(ctx, req.WishlistIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	o := &Occasion{
+		Name:        req.Name,
+		Person:      req.Person,
+		MemberID:    req.MemberID,
+		Date:        req.Date,
+		Yearly:      req.Yearly,
+		BudgetCents: req.BudgetCents,
+		Currency:    req.Currency,
+		WishlistIDs: wishlistIDs,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.store.Create(ctx, o); err != nil {
+		return nil, err
+	}
+	o.NextDate = NextOccurrence(o.Date, o.Yearly, now)
+
+	return o, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int64) (*Occasion, error) {
+	o, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	o.NextDate = NextOccurrence(o.Date, o.Yearly, time.Now())
+	return o, nil
+}
+
+// List returns all occasions, soonest first.
+func (s *Service) List(ctx context.Context) ([]Occasion, error) {
+	occasions, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range occasions {
+		occasions[i].NextDate = NextOccurrence(occasions[i].Date, occasions[i].Yearly, now)
+	}
+	sort.SliceStable(occasions, func(i, j int) bool {
+		return occasions[i].NextDate < occasions[j].NextDate
+	})
+
+	return occasions, nil
+}
+
+func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Occasion, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	o, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		o.Name = *req.Name
+	}
+	if req.Person != nil {
+		o.Person = *req.Person
+	}
+	if req.MemberID != nil {
+		o.MemberID = nil
+		if *req.MemberID != 0 {
+			if err := s.checkMember(ctx, req.MemberID); err != nil {
+				return nil, err
+			}
+			o.MemberID = req.MemberID
+		}
+	}
+	if req.Date != nil {
+		o.Date = *req.Date
+	}
+	if req.Yearly != nil {
+		o.Yearly = *req.Yearly
+	}
+	if req.BudgetCents != nil {
+		o.BudgetCents = req.BudgetCents
+		if *req.BudgetCents < 0 {
+			o.BudgetCents = nil
+		}
+	}
+	if req.Currency != nil {
+		o.Currency = *req.Currency
+	}
+	if req.WishlistIDs != nil {
+		if o.WishlistIDs, err = s.checkWishlists(ctx, *req.WishlistIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	o.UpdatedAt = now
+
+	if err := s.store.Update(ctx, o); err != nil {
+		return nil, err
+	}
+	o.NextDate = NextOccurrence(o.Date, o.Yearly, now)
+
+	return o, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	return s.store.Delete(ctx, id)
+}
+
+// Summary totals the prices of reserved and bought items on the occasion's
+// wishlists, per giver and against the budget. Reservations the requesting
+// member may not see are left out.
+func (s *Service) Summary(ctx context.Context, id int64) (*Summary, error) {
+	o, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([]wishlist.Wishlist, 0, len(o.WishlistIDs))
+	for _, wishlistID := range o.WishlistIDs {
+		w, err := s.wishlists.GetByID(ctx, wishlistID)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, *w)
+	}
+
+	members, err := s.members.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(members))
+	for _, m := range members {
+		names[m.ID] = m.Name
+	}
+
+	return summarize(o, lists, names), nil
+}
+
+func (s *Service) checkMember(ctx context.Context, id *int64) error {
+	if id == nil {
+		return nil
+	}
+	_, err := s.members.GetByID(ctx, *id)
+	return err
+}
+
+// checkWishlists verifies that the wishlists exist and drops duplicates.
+func (s *Service) checkWishlists(ctx context.Context, ids []int64) ([]int64, error) {
+	seen := make(map[int64]bool, len(ids))
+	unique := []int64{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := s.wishlists.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		unique = append(unique, id)
+	}
+	return unique, nil
+}
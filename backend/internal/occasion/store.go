@@ -0,0 +1,12 @@
+package occasion
+
+import "context"
+
+type Store interface {
+	// Create and Update also store the linked wishlist IDs.
+	Create(ctx context.Context, o *Occasion) error
+	GetByID(ctx context.Context, id int64) (*Occasion, error)
+	List(ctx context.Context) ([]Occasion, error)
+	Update(ctx context.Context, o *Occasion) error
+	Delete(ctx context.Context, id int64) error
+}
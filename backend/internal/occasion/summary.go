@@ -0,0 +1,111 @@
+package occasion
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/stadtaev/lofam/backend/internal/wishlist"
+)
+
+// Summary totals what has been reserved and bought for an occasion against
+// its budget.
+type Summary struct {
+	OccasionID  int64  `json:"occasionId"`
+	BudgetCents *int64 `json:"budgetCents"`
+	Currency    string `json:"currency"`
+	// ReservedCents counts items that are reserved but not yet bought.
+	ReservedCents int64 `json:"reservedCents"`
+	BoughtCents   int64 `json:"boughtCents"`
+	TotalCents    int64 `json:"totalCents"`
+	// RemainingCents is nil when the occasion has no budget, and negative
+	// when the budget is exceeded.
+	RemainingCents *int64  `json:"remainingCents"`
+	Givers         []Giver `json:"givers"`
+	// ExcludedItems counts reserved items left out of the totals because
+	// they have no price or are priced in another currency.
+	ExcludedItems int `json:"excludedItems"`
+}
+
+// Giver is a member, or a guest who reserved through a share link, with
+// the gifts they have claimed.
+type Giver struct {
+	MemberID      *int64 `json:"memberId"`
+	Name          string `json:"name"`
+	Items         int    `json:"items"`
+	ReservedCents int64  `json:"reservedCents"`
+	BoughtCents   int64  `json:"boughtCents"`
+	TotalCents    int64  `json:"totalCents"`
+}
+
+// summarize adds up the reserved items of the occasion's wishlists. Items
+// without a currency are assumed to be in the budget's currency; when the
+// occasion has none, the first priced item decides it. memberNames resolves
+// member givers.
+func summarize(o *Occasion, lists []wishlist.Wishlist, memberNames map[int64]string) *Summary {
+	s := &Summary{
+		OccasionID:  o.ID,
+		BudgetCents: o.BudgetCents,
+		Currency:    o.Currency,
+		Givers:      []Giver{},
+	}
+
+	givers := make(map[string]*Giver)
+	var order []string
+
+	for _, l := range lists {
+		for _, item := range l.Items {
+			r := item.Reservation
+			if r == nil {
+				continue
+			}
+			if item.PriceCents == nil {
+				s.ExcludedItems++
+				continue
+			}
+			if s.Currency == "" {
+				s.Currency = item.Currency
+			}
+			if item.Currency != "" && item.Currency != s.Currency {
+				s.ExcludedItems++
+				continue
+			}
+
+			key, name := "guest:"+r.GuestName, r.GuestName
+			if r.MemberID != nil {
+				key, name = "member:"+strconv.FormatInt(*r.MemberID, 10), memberNames[*r.MemberID]
+			}
+			g, ok := givers[key]
+			if !ok {
+				g = &Giver{MemberID: r.MemberID, Name: name}
+				givers[key] = g
+				order = append(order, key)
+			}
+
+			amount := *item.PriceCents * int64(item.Quantity)
+			g.Items++
+			if r.Status == wishlist.ReservationBought {
+				g.BoughtCents += amount
+				s.BoughtCents += amount
+			} else {
+				g.ReservedCents += amount
+				s.ReservedCents += amount
+			}
+			g.TotalCents += amount
+			s.TotalCents += amount
+		}
+	}
+
+	for _, key := range order {
+		s.Givers = append(s.Givers, *givers[key])
+	}
+	sort.SliceStable(s.Givers, func(i, j int) bool {
+		return s.Givers[i].TotalCents > s.Givers[j].TotalCents
+	})
+
+	if o.BudgetCents != nil {
+		remaining := *o.BudgetCents - s.TotalCents
+		s.RemainingCents = &remaining
+	}
+
+	return s
+}
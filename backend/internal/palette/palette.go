@@ -0,0 +1,65 @@
+package palette
+
+import (
+	"regexp"
+	"time"
+)
+
+// Color is a named entry of the palette that notes and wishlists refer to
+// by name. Hex is used on light backgrounds and DarkHex in dark mode.
+type Color struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Hex     string `json:"hex"`
+	DarkHex string `json:"darkHex"`
+	// Position orders the palette in colour pickers; lower comes first.
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CreateRequest struct {
+	Name string `json:"name"`
+	Hex  string `json:"hex"`
+	// DarkHex defaults to Hex.
+	DarkHex string `json:"darkHex"`
+}
+
+func (r CreateRequest) Validate() error {
+	if r.Name == "" {
+		return ErrValidation("name is required")
+	}
+	if !namePattern.MatchString(r.Name) {
+		return ErrValidation("name must be lowercase letters, digits or hyphens, at most 30 characters")
+	}
+	if !hexPattern.MatchString(r.Hex) {
+		return ErrValidation("hex must be a colour such as #fef08a")
+	}
+	if r.DarkHex != "" && !hexPattern.MatchString(r.DarkHex) {
+		return ErrValidation("darkHex must be a colour such as #a16207")
+	}
+	return nil
+}
+
+// UpdateRequest changes how a colour looks; its name is fixed because notes
+// and wishlists refer to it. Omitted fields are unchanged.
+type UpdateRequest struct {
+	Hex      *string `json:"hex,omitempty"`
+	DarkHex  *string `json:"darkHex,omitempty"`
+	Position *int    `json:"position,omitempty"`
+}
+
+func (r UpdateRequest) Validate() error {
+	if r.Hex != nil && !hexPattern.MatchString(*r.Hex) {
+		return ErrValidation("hex must be a colour such as #fef08a")
+	}
+	if r.DarkHex != nil && !hexPattern.MatchString(*r.DarkHex) {
+		return ErrValidation("darkHex must be a colour such as #a16207")
+	}
+	return nil
+}
+
+var (
+	namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,29}$`)
+	hexPattern  = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
@@ -0,0 +1,129 @@
+package palette
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Checker vouches for the colours that notes and wishlists are saved with.
+type Checker interface {
+	Check(ctx context.Context, name string) error
+}
+
+type Service struct {
+	store Store
+}
+
+func NewService(store Store) *Service {
+	return &Service{store: store}
+}
+
+// Create adds a colour at the end of the palette.
+func (s *Service) Create(ctx context.Context, req CreateRequest) (*Color, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.store.GetByName(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrConflict(fmt.Sprintf("a colour named %q already exists", req.Name))
+	}
+
+	colors, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	darkHex := req.DarkHex
+	if darkHex == "" {
+		darkHex = req.Hex
+	}
+
+	now := time.Now()
+	c := &Color{
+		Name:      req.Name,
+		Hex:       req.Hex,
+		DarkHex:   darkHex,
+		Position:  len(colors),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.store.Create(ctx, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int64) (*Color, error) {
+	return s.store.GetByID(ctx, id)
+}
+
+// List returns the palette in picker order.
+func (s *Service) List(ctx context.Context) ([]Color, error) {
+	return s.store.List(ctx)
+}
+
+func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Color, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Hex != nil {
+		c.Hex = *req.Hex
+	}
+	if req.DarkHex != nil {
+		c.DarkHex = *req.DarkHex
+	}
+	if req.Position != nil {
+		c.Position = *req.Position
+	}
+	c.UpdatedAt = time.Now()
+
+	if err := s.store.Update(ctx, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Delete removes a colour that no note or wishlist uses any more.
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	c, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	used, err := s.store.Usage(ctx, c.Name)
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		return ErrConflict(fmt.Sprintf("colour %q is still used by %d notes or wishlists", c.Name, used))
+	}
+
+	return s.store.Delete(ctx, id)
+}
+
+// Check returns a ValidationError unless the palette has a colour with the
+// given name.
+func (s *Service) Check(ctx context.Context, name string) error {
+	c, err := s.store.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return ErrValidation(fmt.Sprintf("color %q is not in the palette", name))
+	}
+	return nil
+}
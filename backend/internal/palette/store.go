@@ -0,0 +1,16 @@
+package palette
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, c *Color) error
+	GetByID(ctx context.Context, id int64) (*Color, error)
+	// GetByName returns the colour with the given name, or nil when there
+	// is none.
+	GetByName(ctx context.Context, name string) (*Color, error)
+	List(ctx context.Context) ([]Color, error)
+	Update(ctx context.Context, c *Color) error
+	Delete(ctx context.Context, id int64) error
+	// Usage counts the notes and wishlists using the named colour.
+	Usage(ctx context.Context, name string) (int, error)
+}
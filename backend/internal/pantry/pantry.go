@@ -0,0 +1,104 @@
+package pantry
+
+import "time"
+
+// DateLayout is the format of expiry dates.
+const DateLayout = "2006-01-02"
+
+type Location string
+
+const (
+	LocationFridge  Location = "fridge"
+	LocationFreezer Location = "freezer"
+	LocationPantry  Location = "pantry"
+	LocationOther   Location = "other"
+)
+
+// Item is something the household has at home.
+type Item struct {
+	ID       int64    `json:"id"`
+	Name     string   `json:"name"`
+	Quantity float64  `json:"quantity"`
+	Unit     string   `json:"unit"`
+	Location Location `json:"location"`
+	// ExpiresOn is a YYYY-MM-DD date, or nil for items that keep.
+	ExpiresOn *string `json:"expiresOn"`
+	// ShoppingItemID links items that arrived by checking off a shopping
+	// item, so that unchecking it can take them back out.
+	ShoppingItemID *int64    `json:"shoppingItemId,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// ExpiringItem is an item that expires within the requested window.
+// DaysLeft is negative for items that have already expired.
+type ExpiringItem struct {
+	Item
+	DaysLeft int `json:"daysLeft"`
+}
+
+// Filter narrows List results; zero values match everything.
+type Filter struct {
+	Location Location
+}
+
+type CreateRequest struct {
+	Name string `json:"name"`
+	// Quantity defaults to 1.
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	// Location defaults to the pantry.
+	Location  Location `json:"location"`
+	ExpiresOn *string  `json:"expiresOn,omitempty"`
+}
+
+func (r CreateRequest) Validate() error {
+	if r.Name == "" {
+		return ErrValidation("name is required")
+	}
+	if r.Quantity < 0 {
+		return ErrValidation("quantity must not be negative")
+	}
+	if r.Location != "" && !IsValidLocation(r.Location) {
+		return ErrValidation("invalid location: must be fridge, freezer, pantry, or other")
+	}
+	if r.ExpiresOn != nil && !isValidDate(*r.ExpiresOn) {
+		return ErrValidation("expiresOn must be in YYYY-MM-DD format")
+	}
+	return nil
+}
+
+// UpdateRequest changes the given fields. An empty ExpiresOn clears the
+// expiry date.
+type UpdateRequest struct {
+	Name      *string   `json:"name,omitempty"`
+	Quantity  *float64  `json:"quantity,omitempty"`
+	Unit      *string   `json:"unit,omitempty"`
+	Location  *Location `json:"location,omitempty"`
+	ExpiresOn *string   `json:"expiresOn,omitempty"`
+}
+
+func (r UpdateRequest) Validate() error {
+	if r.Name != nil && *r.Name == "" {
+		return ErrValidation("name must not be empty")
+	}
+	if r.Quantity != nil && *r.Quantity < 0 {
+		return ErrValidation("quantity must not be negative")
+	}
+	if r.Location != nil && !IsValidLocation(*r.Location) {
+		return ErrValidation("invalid location: must be fridge, freezer, pantry, or other")
+	}
+	if r.ExpiresOn != nil && *r.ExpiresOn != "" && !isValidDate(*r.ExpiresOn) {
+		return ErrValidation("expiresOn must be in YYYY-MM-DD format")
+	}
+	return nil
+}
+
+func IsValidLocation(l Location) bool {
+	return l == LocationFridge || l == LocationFreezer || l == LocationPantry || l == LocationOther
+}
+
+func isValidDate(s string) bool {
+	_, err := time.Parse(DateLayout, s)
+	return err == nil
+}
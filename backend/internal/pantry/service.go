@@ -0,0 +1,194 @@
+package pantry
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/shopping"
+)
+
+type Service struct {
+	store Store
+}
+
+func NewService(store Store) *Service {
+	return &Service{store: store}
+}
+
+func (s *Service) Create(ctx context.Context, req CreateRequest) (*Item, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	location := req.Location
+	if location == "" {
+		location = LocationPantry
+	}
+
+	now := time.Now()
+	item := &Item{
+		Name:      req.Name,
+		Quantity:  quantity,
+		Unit:      req.Unit,
+		Location:  location,
+		ExpiresOn: req.ExpiresOn,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.store.Create(ctx, item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int64) (*Item, error) {
+	return s.store.GetByID(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context, filter Filter) ([]Item, error) {
+	if filter.Location != "" && !IsValidLocation(filter.Location) {
+		return nil, ErrValidation("invalid location: must be fridge, freezer, pantry, or other")
+	}
+	return s.store.List(ctx, filter)
+}
+
+func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Item, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	item, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+	if req.Quantity != nil {
+		item.Quantity = *req.Quantity
+	}
+	if req.Unit != nil {
+		item.Unit = *req.Unit
+	}
+	if req.Location != nil {
+		item.Location = *req.Location
+	}
+	if req.ExpiresOn != nil {
+		item.ExpiresOn = req.ExpiresOn
+		if *req.ExpiresOn == "" {
+			item.ExpiresOn = nil
+		}
+	}
+	item.UpdatedAt = time.Now()
+
+	if err := s.store.Update(ctx, item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	return s.store.Delete(ctx, id)
+}
+
+// Expiring returns the items that expire within days of today, including
+// those that are already past their date.
+func (s *Service) Expiring(ctx context.Context, today time.Time, days int) ([]ExpiringItem, error) {
+	if days < 0 {
+		return nil, ErrValidation("days must not be negative")
+	}
+
+	today = startOfDay(today)
+	items, err := s.store.ExpiringBefore(ctx, today.AddDate(0, 0, days).Format(DateLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	expiring := make([]ExpiringItem, 0, len(items))
+	for _, item := range items {
+		expires, err := time.ParseInLocation(DateLayout, *item.ExpiresOn, today.Location())
+		if err != nil {
+			return nil, err
+		}
+		expiring = append(expiring, ExpiringItem{
+			Item:     item,
+			DaysLeft: int(math.Round(expires.Sub(today).Hours() / 24)),
+		})
+	}
+
+	return expiring, nil
+}
+
+// StockShoppingItem adds a checked-off shopping item to the pantry. Each
+// purchase becomes its own item, so that batches bought on different days
+// keep their own expiry dates.
+func (s *Service) StockShoppingItem(ctx context.Context, bought shopping.Item) error {
+	location, shelfLife := storageFor(bought.Category)
+
+	purchased := time.Now()
+	if bought.CheckedAt != nil {
+		purchased = *bought.CheckedAt
+	}
+
+	var expiresOn *string
+	if shelfLife > 0 {
+		date := purchased.AddDate(0, 0, shelfLife).Format(DateLayout)
+		expiresOn = &date
+	}
+
+	shoppingItemID := bought.ID
+	now := time.Now()
+	return s.store.Create(ctx, &Item{
+		Name:           bought.Title,
+		Quantity:       bought.Quantity,
+		Unit:           bought.Unit,
+		Location:       location,
+		ExpiresOn:      expiresOn,
+		ShoppingItemID: &shoppingItemID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+}
+
+// UnstockShoppingItem takes back out what StockShoppingItem added when a
+// shopping item is unchecked again.
+func (s *Service) UnstockShoppingItem(ctx context.Context, shoppingItemID int64) error {
+	return s.store.DeleteByShoppingItem(ctx, shoppingItemID)
+}
+
+// storageFor picks where a bought item is usually kept and how many days it
+// typically lasts there; zero means it keeps well enough not to track.
+func storageFor(c shopping.Category) (Location, int) {
+	switch c {
+	case shopping.CategoryProduce:
+		return LocationFridge, 7
+	case shopping.CategoryDairy:
+		return LocationFridge, 10
+	case shopping.CategoryMeat:
+		return LocationFridge, 3
+	case shopping.CategorySeafood:
+		return LocationFridge, 2
+	case shopping.CategoryBakery:
+		return LocationPantry, 4
+	case shopping.CategoryFrozen:
+		return LocationFreezer, 0
+	case shopping.CategoryPantry, shopping.CategorySnacks, shopping.CategoryBeverages:
+		return LocationPantry, 0
+	default:
+		return LocationOther, 0
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,17 @@
+package pantry
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, item *Item) error
+	GetByID(ctx context.Context, id int64) (*Item, error)
+	List(ctx context.Context, filter Filter) ([]Item, error)
+	// ExpiringBefore returns items with an expiry date on or before date,
+	// soonest first.
+	ExpiringBefore(ctx context.Context, date string) ([]Item, error)
+	Update(ctx context.Context, item *Item) error
+	Delete(ctx context.Context, id int64) error
+	// DeleteByShoppingItem removes the items that were stocked from the
+	// given shopping item.
+	DeleteByShoppingItem(ctx context.Context, shoppingItemID int64) error
+}
@@ -0,0 +1,50 @@
+package project
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type ValidationError struct {
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Message
+}
+
+func ErrValidation(msg string) ValidationError {
+	return ValidationError{Message: msg}
+}
+
+type NotFoundError struct {
+	ID int64
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("project with id %d not found", e.ID)
+}
+
+func ErrNotFound(id int64) NotFoundError {
+	return NotFoundError{ID: id}
+}
+
+// BlockedError is returned when a column is given a category that would
+// start or finish tasks in it while tasks blocking them are still open.
+type BlockedError struct {
+	ColumnID int64
+	TaskIDs  []int64
+}
+
+func (e BlockedError) Error() string {
+	ids := make([]string, len(e.TaskIDs))
+	for i, id := range e.TaskIDs {
+		ids[i] = strconv.FormatInt(id, 10)
+	}
+	return fmt.Sprintf("column %d holds tasks %s, which are blocked by open tasks", e.ColumnID, strings.Join(ids, ", "))
+}
+
+func ErrBlocked(columnID int64, taskIDs []int64) BlockedError {
+	return BlockedError{ColumnID: columnID, TaskIDs: taskIDs}
+}
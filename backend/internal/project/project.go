@@ -0,0 +1,185 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stadtaev/lofam/backend/internal/task"
+)
+
+const (
+	maxNameLength = 100
+	maxColumns    = 20
+)
+
+// Project groups tasks, such as a renovation or a holiday, on a board of
+// its own columns.
+type Project struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Columns   []Column  `json:"columns"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Column is a stage on a project's board. Every column belongs to one of
+// the base task statuses, its category, which is the status of the tasks
+// in it; that keeps status filters working across projects.
+type Column struct {
+	ID       int64       `json:"id"`
+	Name     string      `json:"name"`
+	Category task.Status `json:"category"`
+}
+
+// DefaultColumns are given to projects created without columns.
+func DefaultColumns() []ColumnRequest {
+	return []ColumnRequest{
+		{Name: "To do", Category: task.StatusTodo},
+		{Name: "In progress", Category: task.StatusInProgress},
+		{Name: "Done", Category: task.StatusDone},
+	}
+}
+
+// ColumnRequest describes a column in the order it appears on the board.
+// Columns sent back with their ID keep their tasks; tasks in columns left
+// out move to the first remaining column of the same category.
+type ColumnRequest struct {
+	ID       int64       `json:"id,omitempty"`
+	Name     string      `json:"name"`
+	Category task.Status `json:"category"`
+}
+
+type CreateRequest struct {
+	Name string `json:"name"`
+	// Columns default to DefaultColumns.
+	Columns []ColumnRequest `json:"columns"`
+}
+
+func (r CreateRequest) Validate() error {
+	if err := validateName(r.Name); err != nil {
+		return err
+	}
+	if len(r.Columns) == 0 {
+		return nil
+	}
+	return validateColumns(r.Columns)
+}
+
+// UpdateRequest renames a project or replaces its columns. Omitted fields
+// are unchanged.
+type UpdateRequest struct {
+	Name    *string         `json:"name,omitempty"`
+	Columns []ColumnRequest `json:"columns,omitempty"`
+}
+
+func (r UpdateRequest) Validate() error {
+	if r.Name != nil {
+		if err := validateName(*r.Name); err != nil {
+			return err
+		}
+	}
+	if r.Columns == nil {
+		return nil
+	}
+	return validateColumns(r.Columns)
+}
+
+func validateName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrValidation("name is required")
+	}
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return ErrValidation(fmt.Sprintf("name must be at most %d characters", maxNameLength))
+	}
+	return nil
+}
+
+// validateColumns checks a board layout. Each base status needs a column
+// so that any task can be placed on the board.
+func validateColumns(columns []ColumnRequest) error {
+	if len(columns) > maxColumns {
+		return ErrValidation(fmt.Sprintf("a project has at most %d columns", maxColumns))
+	}
+
+	names := map[string]bool{}
+	ids := map[int64]bool{}
+	categories := map[task.Status]bool{}
+	for _, c := range columns {
+		name := strings.ToLower(strings.TrimSpace(c.Name))
+		if name == "" {
+			return ErrValidation("column name is required")
+		}
+		if utf8.RuneCountInString(name) > maxNameLength {
+			return ErrValidation(fmt.Sprintf("column name must be at most %d characters", maxNameLength))
+		}
+		if names[name] {
+			return ErrValidation(fmt.Sprintf("column %q appears more than once", c.Name))
+		}
+		names[name] = true
+
+		if c.ID != 0 {
+			if ids[c.ID] {
+				return ErrValidation(fmt.Sprintf("column %d appears more than once", c.ID))
+			}
+			ids[c.ID] = true
+		}
+
+		switch c.Category {
+		case task.StatusTodo, task.StatusInProgress, task.StatusDone:
+			categories[c.Category] = true
+		default:
+			return ErrValidation("column category must be todo, in_progress, or done")
+		}
+	}
+
+	for _, status := range []task.Status{task.StatusTodo, task.StatusInProgress, task.StatusDone} {
+		if !categories[status] {
+			return ErrValidation(fmt.Sprintf("a column with category %s is required", status))
+		}
+	}
+	return nil
+}
+
+// Board is a project's tasks laid out in its columns.
+type Board struct {
+	Project *Project      `json:"project"`
+	Columns []BoardColumn `json:"columns"`
+}
+
+type BoardColumn struct {
+	Column
+	Tasks []task.Task `json:"tasks"`
+}
+
+// NewBoard sorts the project's tasks into its columns, keeping their
+// order. Tasks without a known column go to the first column of their
+// status.
+func NewBoard(p *Project, tasks []task.Task) *Board {
+	b := &Board{Project: p, Columns: make([]BoardColumn, len(p.Columns))}
+	byID := make(map[int64]int, len(p.Columns))
+	first := map[task.Status]int{}
+	for i, c := range p.Columns {
+		b.Columns[i] = BoardColumn{Column: c, Tasks: []task.Task{}}
+		byID[c.ID] = i
+		if _, ok := first[c.Category]; !ok {
+			first[c.Category] = i
+		}
+	}
+
+	for _, t := range tasks {
+		i, ok := -1, false
+		if t.ColumnID != nil {
+			i, ok = byID[*t.ColumnID]
+		}
+		if !ok {
+			if i, ok = first[t.Status]; !ok {
+				continue
+			}
+		}
+		b.Columns[i].Tasks = append(b.Columns[i].Tasks, t)
+	}
+	return b
+}
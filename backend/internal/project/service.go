@@ -0,0 +1,146 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/task"
+)
+
+type Service struct {
+	store Store
+}
+
+func NewService(store Store) *Service {
+	return &Service{store: store}
+}
+
+func (s *Service) Create(ctx context.Context, req CreateRequest) (*Project, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	columns := req.Columns
+	if len(columns) == 0 {
+		columns = DefaultColumns()
+	}
+
+	now := time.Now()
+	p := &Project{
+		Name:      strings.TrimSpace(req.Name),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for _, c := range columns {
+		if c.ID != 0 {
+			return nil, ErrValidation("new columns must not have an id")
+		}
+		p.Columns = append(p.Columns, Column{Name: strings.TrimSpace(c.Name), Category: c.Category})
+	}
+
+	if err := s.store.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int64) (*Project, error) {
+	return s.store.GetByID(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context) ([]Project, error) {
+	return s.store.List(ctx)
+}
+
+func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Project, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	p, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		p.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Columns != nil {
+		existing := make(map[int64]task.Status, len(p.Columns))
+		for _, c := range p.Columns {
+			existing[c.ID] = c.Category
+		}
+
+		columns := make([]Column, len(req.Columns))
+		for i, c := range req.Columns {
+			category, ok := existing[c.ID]
+			if c.ID != 0 && !ok {
+				return nil, ErrValidation(fmt.Sprintf("column %d is not on this project", c.ID))
+			}
+			if c.ID != 0 && c.Category != category {
+				if err := s.checkRecategorize(ctx, c.ID, c.Category); err != nil {
+					return nil, err
+				}
+			}
+			columns[i] = Column{ID: c.ID, Name: strings.TrimSpace(c.Name), Category: c.Category}
+		}
+		p.Columns = columns
+	}
+	p.UpdatedAt = time.Now()
+
+	if err := s.store.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// checkRecategorize refuses to give a column a category that would start
+// or finish blocked tasks in it, as moving them there one by one would.
+func (s *Service) checkRecategorize(ctx context.Context, columnID int64, category task.Status) error {
+	if category == task.StatusTodo {
+		return nil
+	}
+	blocked, err := s.store.BlockedTasks(ctx, columnID)
+	if err != nil {
+		return err
+	}
+	if len(blocked) > 0 {
+		return ErrBlocked(columnID, blocked)
+	}
+	return nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	return s.store.Delete(ctx, id)
+}
+
+// ColumnStatus returns the category of one of the project's columns, which
+// is the status of tasks placed in it.
+func (s *Service) ColumnStatus(ctx context.Context, projectID, columnID int64) (task.Status, error) {
+	p, err := s.store.GetByID(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range p.Columns {
+		if c.ID == columnID {
+			return c.Category, nil
+		}
+	}
+	return "", ErrValidation(fmt.Sprintf("column %d is not on project %d", columnID, projectID))
+}
+
+// ColumnFor returns the first of the project's columns for a status.
+func (s *Service) ColumnFor(ctx context.Context, projectID int64, status task.Status) (int64, error) {
+	p, err := s.store.GetByID(ctx, projectID)
+	if err != nil {
+		return 0, err
+	}
+	for _, c := range p.Columns {
+		if c.Category == status {
+			return c.ID, nil
+		}
+	}
+	return 0, ErrValidation(fmt.Sprintf("project %d has no column for status %s", projectID, status))
+}
@@ -0,0 +1,18 @@
+package project
+
+import "context"
+
+// Store defines the interface for project persistence.
+type Store interface {
+	Create(ctx context.Context, p *Project) error
+	GetByID(ctx context.Context, id int64) (*Project, error)
+	List(ctx context.Context) ([]Project, error)
+	// Update saves the name and columns of a project, assigning IDs to new
+	// columns and moving the tasks of removed columns.
+	Update(ctx context.Context, p *Project) error
+	// BlockedTasks returns the ids of the tasks in a column that have
+	// blockers which are not done.
+	BlockedTasks(ctx context.Context, columnID int64) ([]int64, error)
+	// Delete removes a project; its tasks stay, outside any project.
+	Delete(ctx context.Context, id int64) error
+}
@@ -0,0 +1,93 @@
+// Package rank makes lexicographic sort keys. An item is moved between two
+// others by giving it a key that sorts between theirs, so nothing else has
+// to be renumbered.
+package rank
+
+import "strings"
+
+// digits are in ASCII order, so keys compare correctly as plain strings,
+// including in SQLite's default collation.
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// MaxLength is the key length past which keys have become too dense and
+// should be handed out again with Spread.
+const MaxLength = 12
+
+// Between returns a key that sorts after lower and before upper; an empty
+// lower or upper leaves that side open. lower must sort before upper. Keys
+// made here never end in the smallest digit, which guarantees that there is
+// always room for another key between two of them.
+func Between(lower, upper string) string {
+	if upper != "" {
+		// Keep the prefix the two keys share, treating the missing tail of
+		// lower as smallest digits.
+		n := 0
+		for n < len(upper) && digitAt(lower, n) == upper[n] {
+			n++
+		}
+		if n > 0 {
+			return upper[:n] + Between(tail(lower, n), upper[n:])
+		}
+	}
+
+	lo := 0
+	if lower != "" {
+		lo = strings.IndexByte(digits, lower[0])
+	}
+	hi := len(digits)
+	if upper != "" {
+		hi = strings.IndexByte(digits, upper[0])
+	}
+
+	if hi-lo > 1 {
+		return string(digits[(lo+hi)/2])
+	}
+	// The first digits are adjacent: a longer upper can be cut short,
+	// otherwise the key continues after lower's first digit.
+	if len(upper) > 1 {
+		return upper[:1]
+	}
+	return string(digits[lo]) + Between(tail(lower, 1), "")
+}
+
+// Spread returns n keys in ascending order, evenly spaced and as short as
+// possible while leaving room between neighbours.
+func Spread(n int) []string {
+	width, space := 1, int64(len(digits))
+	for space < int64(n+1)*int64(len(digits)) && width < 10 {
+		width++
+		space *= int64(len(digits))
+	}
+	step := space / int64(n+1)
+
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = encode(step*int64(i+1), width)
+	}
+	return keys
+}
+
+// encode writes v in width digits, dropping trailing smallest digits,
+// which does not change the order of keys of the same width.
+func encode(v int64, width int) string {
+	b := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		b[i] = digits[v%int64(len(digits))]
+		v /= int64(len(digits))
+	}
+	return strings.TrimRight(string(b), digits[:1])
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return digits[0]
+}
+
+func tail(s string, n int) string {
+	if n < len(s) {
+		return s[n:]
+	}
+	return ""
+}
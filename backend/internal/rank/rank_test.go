@@ -0,0 +1,95 @@
+package rank
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		lower, upper string
+	}{
+		{"", ""},
+		{"", "1"},
+		{"", "01"},
+		{"V", ""},
+		{"z", ""},
+		{"zz", ""},
+		{"A", "B"},
+		{"A", "A1"},
+		{"A1", "B"},
+		{"0001", "0002"},
+		{"Az", "B01"},
+	}
+
+	for _, tt := range tests {
+		got := Between(tt.lower, tt.upper)
+		if got <= tt.lower || (tt.upper != "" && got >= tt.upper) {
+			t.Errorf("Between(%q, %q) = %q, not between", tt.lower, tt.upper, got)
+		}
+		if strings.HasSuffix(got, "0") {
+			t.Errorf("Between(%q, %q) = %q ends in the smallest digit", tt.lower, tt.upper, got)
+		}
+	}
+}
+
+func TestBetweenRepeatedInserts(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	keys := []string{}
+	for i := 0; i < 2000; i++ {
+		at := rng.Intn(len(keys) + 1)
+		var lower, upper string
+		if at > 0 {
+			lower = keys[at-1]
+		}
+		if at < len(keys) {
+			upper = keys[at]
+		}
+
+		key := Between(lower, upper)
+		keys = append(keys[:at], append([]string{key}, keys[at:]...)...)
+	}
+
+	if !sort.StringsAreSorted(keys) {
+		t.Fatal("keys are out of order")
+	}
+	for i := 1; i < len(keys); i++ {
+		if keys[i] == keys[i-1] {
+			t.Fatalf("duplicate key %q", keys[i])
+		}
+	}
+}
+
+func TestBetweenDenseEnd(t *testing.T) {
+	// Always inserting right after the same key makes keys grow; MaxLength
+	// is the point at which callers spread them out again.
+	lower, upper := "V", "W"
+	for i := 0; i < 100; i++ {
+		upper = Between(lower, upper)
+	}
+	if len(upper) <= MaxLength {
+		t.Errorf("after 100 inserts the key is only %d long", len(upper))
+	}
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 61, 62, 5000} {
+		keys := Spread(n)
+		if len(keys) != n {
+			t.Fatalf("Spread(%d) returned %d keys", n, len(keys))
+		}
+		if !sort.StringsAreSorted(keys) {
+			t.Errorf("Spread(%d) is out of order", n)
+		}
+		for i, key := range keys {
+			if key == "" || strings.HasSuffix(key, "0") || len(key) > 4 {
+				t.Errorf("Spread(%d)[%d] = %q", n, i, key)
+			}
+			if i > 0 && Between(keys[i-1], key) >= key {
+				t.Errorf("no room between %q and %q", keys[i-1], key)
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package recipe
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/stadtaev/lofam/backend/internal/jsonld"
+)
+
+// Fetcher downloads the page a recipe is imported from.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) ([]byte, error)
+}
+
+// ImportRequest imports a recipe either from a URL or from HTML the client
+// already has. HTML takes precedence when both are given.
+type ImportRequest struct {
+	URL  string `json:"url"`
+	HTML string `json:"html"`
+}
+
+func (r ImportRequest) Validate() error {
+	if r.URL == "" && r.HTML == "" {
+		return ErrValidation("url or html is required")
+	}
+	return nil
+}
+
+var firstNumberPattern = regexp.MustCompile(`\d+`)
+
+// ParseHTML extracts the schema.org Recipe embedded in a page as JSON-LD.
+func ParseHTML(page []byte) (*CreateRequest, error) {
+	obj := jsonld.Find(jsonld.Extract(page), "Recipe")
+	if obj == nil {
+		return nil, ErrValidation("no schema.org Recipe found in page")
+	}
+
+	req := &CreateRequest{
+		Name:        cleanText(jsonld.String(obj["name"])),
+		Description: cleanText(jsonld.String(obj["description"])),
+		Servings:    parseYield(obj["recipeYield"]),
+		SourceURL:   jsonld.String(obj["url"]),
+		Steps:       parseInstructions(obj["recipeInstructions"]),
+	}
+
+	lines := jsonld.Strings(obj["recipeIngredient"])
+	if len(lines) == 0 {
+		// Older markup uses the deprecated "ingredients" property.
+		lines = jsonld.Strings(obj["ingredients"])
+	}
+	for _, line := range lines {
+		if ing := ParseIngredient(line); ing.Name != "" {
+			req.Ingredients = append(req.Ingredients, ing)
+		}
+	}
+
+	if req.Name == "" {
+		return nil, ErrValidation("recipe in page has no name")
+	}
+	return req, nil
+}
+
+// parseYield returns the first number in recipeYield, which may be a number,
+// "4 servings", or an array of either.
+func parseYield(v any) int {
+	for _, s := range jsonld.Strings(v) {
+		if m := firstNumberPattern.FindString(s); m != "" {
+			if n, err := strconv.Atoi(m); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// parseInstructions flattens recipeInstructions, which may be a single text
+// block, a list of strings, HowToStep objects, or HowToSections of steps.
+func parseInstructions(v any) []string {
+	var steps []string
+	switch v := v.(type) {
+	case string:
+		for _, line := range strings.Split(v, "\n") {
+			if line = cleanText(line); line != "" {
+				steps = append(steps, line)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			steps = append(steps, parseInstructions(item)...)
+		}
+	case map[string]any:
+		if list, ok := v["itemListElement"]; ok {
+			return parseInstructions(list)
+		}
+		text := jsonld.String(v["text"])
+		if text == "" {
+			text = jsonld.String(v["name"])
+		}
+		if text = cleanText(text); text != "" {
+			steps = append(steps, text)
+		}
+	}
+	return steps
+}
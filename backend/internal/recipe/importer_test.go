@@ -0,0 +1,143 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+const recipePage = `<!doctype html>
+<html><head>
+<script type="application/ld+json">
+{
+  "@context": "https://schema.org",
+  "@graph": [
+    {"@type": "WebSite", "name": "Example Kitchen"},
+    {
+      "@type": ["Recipe"],
+      "name": "Tomato Soup",
+      "description": "A weeknight classic &amp; kid favourite.",
+      "recipeYield": ["4", "4 servings"],
+      "recipeIngredient": [
+        "2 tbsp olive oil",
+        "1 (400 g) can chopped tomatoes",
+        "1½ cups vegetable stock",
+        "2 cloves garlic, minced",
+        "salt to taste"
+      ],
+      "recipeInstructions": [
+        {"@type": "HowToSection", "name": "Soup", "itemListElement": [
+          {"@type": "HowToStep", "text": "Heat the <b>oil</b>."},
+          {"@type": "HowToStep", "text": "Add everything and simmer."}
+        ]},
+        "Blend until smooth."
+      ]
+    }
+  ]
+}
+</script>
+</head><body></body></html>`
+
+type stubFetcher struct {
+	pages map[string]string
+}
+
+func (f stubFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	page, ok := f.pages[url]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return []byte(page), nil
+}
+
+type memoryStore struct {
+	recipes []Recipe
+}
+
+func (s *memoryStore) Create(ctx context.Context, r *Recipe) error {
+	r.ID = int64(len(s.recipes) + 1)
+	s.recipes = append(s.recipes, *r)
+	return nil
+}
+
+func (s *memoryStore) GetByID(ctx context.Context, id int64) (*Recipe, error) {
+	return nil, ErrNotFound(id)
+}
+
+func (s *memoryStore) List(ctx context.Context) ([]Recipe, error) { return s.recipes, nil }
+
+func (s *memoryStore) Update(ctx context.Context, r *Recipe) error { return nil }
+
+func (s *memoryStore) Delete(ctx context.Context, id int64) error { return nil }
+
+func TestImportFromURL(t *testing.T) {
+	const url = "https://example.com/tomato-soup"
+	service := NewService(&memoryStore{}, stubFetcher{pages: map[string]string{url: recipePage}})
+
+	got, err := service.Import(context.Background(), ImportRequest{URL: url})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if got.Name != "Tomato Soup" {
+		t.Errorf("name = %q, want %q", got.Name, "Tomato Soup")
+	}
+	if got.Description != "A weeknight classic & kid favourite." {
+		t.Errorf("description = %q", got.Description)
+	}
+	if got.Servings != 4 {
+		t.Errorf("servings = %d, want 4", got.Servings)
+	}
+	if got.SourceURL != url {
+		t.Errorf("sourceUrl = %q, want %q", got.SourceURL, url)
+	}
+	wantSteps := []string{"Heat the oil.", "Add everything and simmer.", "Blend until smooth."}
+	if len(got.Steps) != len(wantSteps) {
+		t.Fatalf("steps = %q, want %q", got.Steps, wantSteps)
+	}
+	for i := range wantSteps {
+		if got.Steps[i] != wantSteps[i] {
+			t.Errorf("steps[%d] = %q, want %q", i, got.Steps[i], wantSteps[i])
+		}
+	}
+	if len(got.Ingredients) != 5 {
+		t.Errorf("len(ingredients) = %d, want 5", len(got.Ingredients))
+	}
+}
+
+func TestImportWithoutRecipe(t *testing.T) {
+	service := NewService(&memoryStore{}, nil)
+
+	_, err := service.Import(context.Background(), ImportRequest{HTML: "<html><body>Hi</body></html>"})
+	var validationErr ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("Import() error = %v, want ValidationError", err)
+	}
+}
+
+func TestParseIngredient(t *testing.T) {
+	tests := []struct {
+		line string
+		want Ingredient
+	}{
+		{"2 cups flour", Ingredient{Quantity: 2, Unit: "cup", Name: "flour"}},
+		{"1 1/2 tsp baking powder", Ingredient{Quantity: 1.5, Unit: "tsp", Name: "baking powder"}},
+		{"1½ Tablespoons sugar", Ingredient{Quantity: 1.5, Unit: "tbsp", Name: "sugar"}},
+		{"½ cup of milk", Ingredient{Quantity: 0.5, Unit: "cup", Name: "milk"}},
+		{"200g butter, softened", Ingredient{Quantity: 200, Unit: "g", Name: "butter", Note: "softened"}},
+		{"1 (400 g) can chopped tomatoes", Ingredient{Quantity: 1, Unit: "can", Name: "chopped tomatoes", Note: "400 g"}},
+		{"2-3 cloves garlic", Ingredient{Quantity: 3, Unit: "clove", Name: "garlic"}},
+		{"3 large eggs", Ingredient{Quantity: 3, Name: "large eggs"}},
+		{"1,5 l water", Ingredient{Quantity: 1.5, Unit: "l", Name: "water"}},
+		{"2 fl oz cream", Ingredient{Quantity: 2, Unit: "fl oz", Name: "cream"}},
+		{"salt to taste", Ingredient{Name: "salt to taste"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := ParseIngredient(tt.line); got != tt.want {
+				t.Errorf("ParseIngredient(%q) = %+v, want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package recipe
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/stadtaev/lofam/backend/internal/unit"
+)
+
+var vulgarFractions = map[rune]float64{
+	'½': 1.0 / 2, '⅓': 1.0 / 3, '⅔': 2.0 / 3, '¼': 1.0 / 4, '¾': 3.0 / 4,
+	'⅕': 1.0 / 5, '⅖': 2.0 / 5, '⅗': 3.0 / 5, '⅘': 4.0 / 5, '⅙': 1.0 / 6,
+	'⅚': 5.0 / 6, '⅛': 1.0 / 8, '⅜': 3.0 / 8, '⅝': 5.0 / 8, '⅞': 7.0 / 8,
+}
+
+var (
+	// quantityPattern matches a leading amount: "2", "1.5", "1,5", "1/2",
+	// "1 1/2", optionally followed by a range upper bound ("2-3", "2 to 3").
+	quantityPattern = regexp.MustCompile(`^(\d+/\d+|\d+(?:[.,]\d+)?(?:\s+\d+/\d+)?)(?:\s*(?:-|–|to)\s*(\d+/\d+|\d+(?:[.,]\d+)?(?:\s+\d+/\d+)?))?\s*`)
+	// attachedUnitPattern splits "200g" or "1.5kg" into amount and unit.
+	attachedUnitPattern = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)([a-zA-Z]+\.?)(\s|$)`)
+	tagPattern          = regexp.MustCompile(`<[^>]*>`)
+	spaceBeforePunct    = regexp.MustCompile(`\s+([.,;:!?])`)
+)
+
+// ParseIngredient splits an ingredient line such as "1 ½ cups flour, sifted"
+// into quantity, unit, name and note. Ranges use their upper bound, since
+// they are mostly used to decide how much to buy.
+func ParseIngredient(line string) Ingredient {
+	line = cleanText(line)
+	line = expandVulgarFractions(line)
+
+	var ing Ingredient
+	rest := line
+
+	if m := attachedUnitPattern.FindStringSubmatch(rest); m != nil {
+		if u, ok := unit.Lookup(m[2]); ok {
+			ing.Quantity = parseAmount(m[1])
+			ing.Unit = u
+			rest = strings.TrimSpace(rest[len(m[0]):])
+		}
+	}
+
+	if ing.Quantity == 0 {
+		if m := quantityPattern.FindStringSubmatch(rest); m != nil {
+			ing.Quantity = parseAmount(m[1])
+			if m[2] != "" {
+				ing.Quantity = parseAmount(m[2])
+			}
+			rest = rest[len(m[0]):]
+		}
+	}
+
+	// A parenthetical right after the amount describes the package size:
+	// "1 (400 g) can tomatoes".
+	var notes []string
+	if strings.HasPrefix(rest, "(") {
+		if end := strings.Index(rest, ")"); end > 0 {
+			notes = append(notes, strings.TrimSpace(rest[1:end]))
+			rest = strings.TrimSpace(rest[end+1:])
+		}
+	}
+
+	if ing.Unit == "" && ing.Quantity > 0 {
+		ing.Unit, rest = splitUnit(rest)
+	}
+	rest = strings.TrimPrefix(rest, "of ")
+
+	if i := strings.Index(rest, ","); i >= 0 {
+		notes = append(notes, strings.TrimSpace(rest[i+1:]))
+		rest = rest[:i]
+	}
+
+	ing.Name = strings.TrimSpace(rest)
+	ing.Note = strings.Join(notes, "; ")
+	if ing.Name == "" {
+		ing.Name = line
+	}
+	return ing
+}
+
+// splitUnit removes a leading unit (one or two words) from s.
+func splitUnit(s string) (string, string) {
+	words := strings.Fields(s)
+	if len(words) >= 2 {
+		if u, ok := unit.Lookup(words[0] + " " + words[1]); ok {
+			return u, strings.Join(words[2:], " ")
+		}
+	}
+	if len(words) >= 1 {
+		if u, ok := unit.Lookup(words[0]); ok {
+			return u, strings.Join(words[1:], " ")
+		}
+	}
+	return "", s
+}
+
+func parseAmount(s string) float64 {
+	var total float64
+	for _, part := range strings.Fields(strings.ReplaceAll(s, ",", ".")) {
+		if num, den, ok := strings.Cut(part, "/"); ok {
+			n, err1 := strconv.ParseFloat(num, 64)
+			d, err2 := strconv.ParseFloat(den, 64)
+			if err1 == nil && err2 == nil && d != 0 {
+				total += n / d
+			}
+			continue
+		}
+		if v, err := strconv.ParseFloat(part, 64); err == nil {
+			total += v
+		}
+	}
+	return total
+}
+
+// expandVulgarFractions rewrites "1½" and "½" as "1 1/2" and "1/2" so that the
+// quantity pattern only has to deal with ASCII.
+func expandVulgarFractions(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		v, ok := vulgarFractions[r]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if b.Len() > 0 {
+			if last := b.String()[b.Len()-1]; last >= '0' && last <= '9' {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(fractionString(v))
+	}
+	return b.String()
+}
+
+func fractionString(v float64) string {
+	for den := 2; den <= 8; den++ {
+		num := v * float64(den)
+		if rounded := float64(int(num + 0.5)); num-rounded < 1e-9 && rounded-num < 1e-9 {
+			return strconv.Itoa(int(rounded)) + "/" + strconv.Itoa(den)
+		}
+	}
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+// cleanText strips tags and entities and collapses whitespace.
+func cleanText(s string) string {
+	s = tagPattern.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	s = strings.Join(strings.Fields(s), " ")
+	return spaceBeforePunct.ReplaceAllString(s, "$1")
+}
@@ -0,0 +1,77 @@
+package recipe
+
+import "time"
+
+type Ingredient struct {
+	// Quantity is zero for ingredients without an amount ("salt to taste").
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	Name     string  `json:"name"`
+	Note     string  `json:"note"`
+}
+
+type Recipe struct {
+	ID          int64        `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Servings    int          `json:"servings"`
+	Ingredients []Ingredient `json:"ingredients"`
+	Steps       []string     `json:"steps"`
+	SourceURL   string       `json:"sourceUrl"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+type CreateRequest struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Servings    int          `json:"servings"`
+	Ingredients []Ingredient `json:"ingredients"`
+	Steps       []string     `json:"steps"`
+	SourceURL   string       `json:"sourceUrl"`
+}
+
+func (r CreateRequest) Validate() error {
+	if r.Name == "" {
+		return ErrValidation("name is required")
+	}
+	if r.Servings < 0 {
+		return ErrValidation("servings must be positive")
+	}
+	return validateIngredients(r.Ingredients)
+}
+
+// UpdateRequest changes the given fields; omitted fields are unchanged.
+type UpdateRequest struct {
+	Name        *string       `json:"name,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	Servings    *int          `json:"servings,omitempty"`
+	Ingredients *[]Ingredient `json:"ingredients,omitempty"`
+	Steps       *[]string     `json:"steps,omitempty"`
+	SourceURL   *string       `json:"sourceUrl,omitempty"`
+}
+
+func (r UpdateRequest) Validate() error {
+	if r.Name != nil && *r.Name == "" {
+		return ErrValidation("name must not be empty")
+	}
+	if r.Servings != nil && *r.Servings <= 0 {
+		return ErrValidation("servings must be positive")
+	}
+	if r.Ingredients != nil {
+		return validateIngredients(*r.Ingredients)
+	}
+	return nil
+}
+
+func validateIngredients(ingredients []Ingredient) error {
+	for _, ing := range ingredients {
+		if ing.Name == "" {
+			return ErrValidation("ingredient name is required")
+		}
+		if ing.Quantity < 0 {
+			return ErrValidation("ingredient quantity must not be negative")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package recipe
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/unit"
+)
+
+type Service struct {
+	store   Store
+	fetcher Fetcher
+}
+
+// NewService creates a recipe service. fetcher is used to import recipes
+// from URLs and may be nil to allow only HTML imports.
+func NewService(store Store, fetcher Fetcher) *Service {
+	return &Service{store: store, fetcher: fetcher}
+}
+
+func (s *Service) Create(ctx context.Context, req CreateRequest) (*Recipe, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	r := &Recipe{
+		Name:        req.Name,
+		Description: req.Description,
+		Servings:    defaultServings(req.Servings),
+		Ingredients: normalizeIngredients(req.Ingredients),
+		Steps:       nonNil(req.Steps),
+		SourceURL:   req.SourceURL,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.store.Create(ctx, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Import creates a recipe from the schema.org markup of a web page.
+func (s *Service) Import(ctx context.Context, req ImportRequest) (*Recipe, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	page := []byte(req.HTML)
+	if req.HTML == "" {
+		if s.fetcher == nil {
+			return nil, ErrValidation("importing from a url is not enabled")
+		}
+		var err error
+		if page, err = s.fetcher.Fetch(ctx, req.URL); err != nil {
+			// The cause can name internal hosts and addresses, so it stays
+			// in the log.
+			log.Printf("recipe import: fetch %s: %v", req.URL, err)
+			return nil, ErrValidation("could not fetch recipe")
+		}
+	}
+
+	create, err := ParseHTML(page)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL != "" {
+		create.SourceURL = req.URL
+	}
+
+	return s.Create(ctx, *create)
+}
+
+func (s *Service) GetByID(ctx context.Context, id int64) (*Recipe, error) {
+	return s.store.GetByID(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context) ([]Recipe, error) {
+	return s.store.List(ctx)
+}
+
+func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Recipe, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	r, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		r.Name = *req.Name
+	}
+	if req.Description != nil {
+		r.Description = *req.Description
+	}
+	if req.Servings != nil {
+		r.Servings = *req.Servings
+	}
+	if req.Ingredients != nil {
+		r.Ingredients = normalizeIngredients(*req.Ingredients)
+	}
+	if req.Steps != nil {
+		r.Steps = nonNil(*req.Steps)
+	}
+	if req.SourceURL != nil {
+		r.SourceURL = *req.SourceURL
+	}
+	r.UpdatedAt = time.Now()
+
+	if err := s.store.Update(ctx, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	return s.store.Delete(ctx, id)
+}
+
+func defaultServings(n int) int {
+	if n == 0 {
+		return 1
+	}
+	return n
+}
+
+func normalizeIngredients(ingredients []Ingredient) []Ingredient {
+	out := make([]Ingredient, len(ingredients))
+	for i, ing := range ingredients {
+		ing.Unit = unit.Normalize(ing.Unit)
+		out[i] = ing
+	}
+	return out
+}
+
+func nonNil(steps []string) []string {
+	if steps == nil {
+		return []string{}
+	}
+	return steps
+}
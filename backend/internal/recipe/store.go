@@ -0,0 +1,12 @@
+package recipe
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, r *Recipe) error
+	GetByID(ctx context.Context, id int64) (*Recipe, error)
+	List(ctx context.Context) ([]Recipe, error)
+	Update(ctx context.Context, r *Recipe) error
+	// Delete removes a recipe and any meal plan entries that use it.
+	Delete(ctx context.Context, id int64) error
+}
@@ -0,0 +1,263 @@
+package shopping
+
+import (
+	"strings"
+	"unicode"
+)
+
+type Category string
+
+const (
+	CategoryProduce      Category = "produce"
+	CategoryBakery       Category = "bakery"
+	CategoryDairy        Category = "dairy"
+	CategoryMeat         Category = "meat"
+	CategorySeafood      Category = "seafood"
+	CategoryFrozen       Category = "frozen"
+	CategoryPantry       Category = "pantry"
+	CategorySnacks       Category = "snacks"
+	CategoryBeverages    Category = "beverages"
+	CategoryHousehold    Category = "household"
+	CategoryPersonalCare Category = "personal_care"
+	CategoryPharmacy     Category = "pharmacy"
+	CategoryBaby         Category = "baby"
+	CategoryPet          Category = "pet"
+	CategoryOther        Category = "other"
+)
+
+// DefaultAisleOrder is the walking order used for lists that have not
+// configured their own.
+var DefaultAisleOrder = []Category{
+	CategoryProduce,
+	CategoryBakery,
+	CategoryMeat,
+	CategorySeafood,
+	CategoryDairy,
+	CategoryFrozen,
+	CategoryPantry,
+	CategorySnacks,
+	CategoryBeverages,
+	CategoryHousehold,
+	CategoryPersonalCare,
+	CategoryPharmacy,
+	CategoryBaby,
+	CategoryPet,
+	CategoryOther,
+}
+
+func isValidCategory(c Category) bool {
+	for _, known := range DefaultAisleOrder {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+// keywords maps words found in item titles to their category. Multi-word
+// keywords take precedence over single words, so "ice cream" wins over "cream"
+// and "toilet roll" over "roll".
+var keywords = map[string]Category{
+	// produce
+	"apple": CategoryProduce, "banana": CategoryProduce, "orange": CategoryProduce,
+	"lemon": CategoryProduce, "lime": CategoryProduce, "grape": CategoryProduce,
+	"berry": CategoryProduce, "strawberry": CategoryProduce, "blueberry": CategoryProduce,
+	"raspberry": CategoryProduce, "pear": CategoryProduce, "peach": CategoryProduce,
+	"plum": CategoryProduce, "melon": CategoryProduce, "watermelon": CategoryProduce,
+	"pineapple": CategoryProduce, "mango": CategoryProduce, "kiwi": CategoryProduce,
+	"avocado": CategoryProduce, "tomato": CategoryProduce, "potato": CategoryProduce,
+	"onion": CategoryProduce, "garlic": CategoryProduce, "carrot": CategoryProduce,
+	"cucumber": CategoryProduce, "lettuce": CategoryProduce, "salad": CategoryProduce,
+	"spinach": CategoryProduce, "kale": CategoryProduce, "cabbage": CategoryProduce,
+	"broccoli": CategoryProduce, "cauliflower": CategoryProduce, "pepper": CategoryProduce,
+	"zucchini": CategoryProduce, "courgette": CategoryProduce, "eggplant": CategoryProduce,
+	"aubergine": CategoryProduce, "mushroom": CategoryProduce, "celery": CategoryProduce,
+	"leek": CategoryProduce, "ginger": CategoryProduce, "herb": CategoryProduce,
+	"parsley": CategoryProduce, "basil": CategoryProduce, "dill": CategoryProduce,
+	"cilantro": CategoryProduce, "coriander": CategoryProduce, "corn": CategoryProduce,
+	"bean sprout": CategoryProduce, "sweet potato": CategoryProduce, "fruit": CategoryProduce,
+	"vegetable": CategoryProduce, "radish": CategoryProduce, "beetroot": CategoryProduce,
+
+	// bakery
+	"bread": CategoryBakery, "baguette": CategoryBakery, "roll": CategoryBakery,
+	"bun": CategoryBakery, "croissant": CategoryBakery, "bagel": CategoryBakery,
+	"muffin": CategoryBakery, "cake": CategoryBakery, "pastry": CategoryBakery,
+	"tortilla": CategoryBakery, "pita": CategoryBakery, "toast": CategoryBakery,
+
+	// dairy
+	"milk": CategoryDairy, "oat milk": CategoryDairy, "almond milk": CategoryDairy,
+	"soy milk": CategoryDairy, "cheese": CategoryDairy, "butter": CategoryDairy,
+	"yogurt": CategoryDairy, "yoghurt": CategoryDairy, "cream": CategoryDairy,
+	"sour cream": CategoryDairy, "cream cheese": CategoryDairy, "egg": CategoryDairy,
+	"kefir": CategoryDairy, "mozzarella": CategoryDairy, "parmesan": CategoryDairy,
+	"cheddar": CategoryDairy, "feta": CategoryDairy, "quark": CategoryDairy,
+
+	// meat
+	"chicken": CategoryMeat, "beef": CategoryMeat, "pork": CategoryMeat,
+	"lamb": CategoryMeat, "turkey": CategoryMeat, "bacon": CategoryMeat,
+	"ham": CategoryMeat, "sausage": CategoryMeat, "mince": CategoryMeat,
+	"ground beef": CategoryMeat, "steak": CategoryMeat, "salami": CategoryMeat,
+
+	// seafood
+	"fish": CategorySeafood, "salmon": CategorySeafood, "tuna": CategorySeafood,
+	"cod": CategorySeafood, "shrimp": CategorySeafood, "prawn": CategorySeafood,
+	"mussel": CategorySeafood, "crab": CategorySeafood,
+
+	// frozen
+	"frozen": CategoryFrozen, "ice cream": CategoryFrozen, "ice": CategoryFrozen,
+	"pizza": CategoryFrozen, "fish finger": CategoryFrozen, "frozen pea": CategoryFrozen,
+
+	// pantry
+	"rice": CategoryPantry, "pasta": CategoryPantry, "spaghetti": CategoryPantry,
+	"noodle": CategoryPantry, "flour": CategoryPantry, "sugar": CategoryPantry,
+	"salt": CategoryPantry, "oil": CategoryPantry, "olive oil": CategoryPantry,
+	"vinegar": CategoryPantry, "cereal": CategoryPantry, "oat": CategoryPantry,
+	"oats": CategoryPantry, "muesli": CategoryPantry, "honey": CategoryPantry,
+	"jam": CategoryPantry, "peanut butter": CategoryPantry, "sauce": CategoryPantry,
+	"ketchup": CategoryPantry, "mustard": CategoryPantry, "mayonnaise": CategoryPantry,
+	"spice": CategoryPantry, "canned": CategoryPantry, "can": CategoryPantry,
+	"lentil": CategoryPantry, "chickpea": CategoryPantry, "bean": CategoryPantry,
+	"stock": CategoryPantry, "broth": CategoryPantry, "yeast": CategoryPantry,
+	"baking powder": CategoryPantry, "soup": CategoryPantry,
+	"baking soda": CategoryPantry, "coconut milk": CategoryPantry,
+	"black pepper": CategoryPantry, "white pepper": CategoryPantry,
+	"peppercorn": CategoryPantry, "garlic powder": CategoryPantry,
+	"onion powder": CategoryPantry, "ground ginger": CategoryPantry,
+
+	// snacks
+	"chips": CategorySnacks, "crisps": CategorySnacks, "chocolate": CategorySnacks,
+	"cookie": CategorySnacks, "biscuit": CategorySnacks, "candy": CategorySnacks,
+	"sweets": CategorySnacks, "nut": CategorySnacks, "popcorn": CategorySnacks,
+	"cracker": CategorySnacks, "pretzel": CategorySnacks,
+
+	// beverages
+	"water": CategoryBeverages, "juice": CategoryBeverages, "coffee": CategoryBeverages,
+	"tea": CategoryBeverages, "soda": CategoryBeverages, "cola": CategoryBeverages,
+	"beer": CategoryBeverages, "wine": CategoryBeverages, "lemonade": CategoryBeverages,
+	"ginger ale": CategoryBeverages, "ginger beer": CategoryBeverages,
+
+	// household
+	"toilet paper": CategoryHousehold, "paper towel": CategoryHousehold,
+	"toilet roll": CategoryHousehold, "kitchen roll": CategoryHousehold,
+	"detergent": CategoryHousehold, "dish soap": CategoryHousehold,
+	"sponge": CategoryHousehold, "trash bag": CategoryHousehold,
+	"bin bag": CategoryHousehold, "foil": CategoryHousehold,
+	"cling film": CategoryHousehold, "battery": CategoryHousehold,
+	"light bulb": CategoryHousehold, "bleach": CategoryHousehold,
+	"cleaner": CategoryHousehold, "napkin": CategoryHousehold,
+	"candle": CategoryHousehold, "tissue": CategoryHousehold,
+
+	// personal care
+	"shampoo": CategoryPersonalCare, "conditioner": CategoryPersonalCare,
+	"soap": CategoryPersonalCare, "toothpaste": CategoryPersonalCare,
+	"toothbrush": CategoryPersonalCare, "deodorant": CategoryPersonalCare,
+	"razor": CategoryPersonalCare, "lotion": CategoryPersonalCare,
+	"sunscreen": CategoryPersonalCare, "floss": CategoryPersonalCare,
+	"shaving cream": CategoryPersonalCare, "hand cream": CategoryPersonalCare,
+
+	// pharmacy
+	"aspirin": CategoryPharmacy, "ibuprofen": CategoryPharmacy,
+	"paracetamol": CategoryPharmacy, "vitamin": CategoryPharmacy,
+	"plaster": CategoryPharmacy, "bandage": CategoryPharmacy,
+	"medicine": CategoryPharmacy, "cough syrup": CategoryPharmacy,
+
+	// baby
+	"diaper": CategoryBaby, "nappy": CategoryBaby, "wipes": CategoryBaby,
+	"baby food": CategoryBaby, "formula": CategoryBaby,
+
+	// pet
+	"cat food": CategoryPet, "dog food": CategoryPet, "litter": CategoryPet,
+	"pet food": CategoryPet,
+}
+
+// NormalizeTitle lowercases a title and collapses whitespace so that learned
+// categories match regardless of how the item was typed.
+func NormalizeTitle(title string) string {
+	return strings.Join(strings.Fields(strings.ToLower(title)), " ")
+}
+
+// Categorize guesses the aisle category of an item from its title using the
+// built-in keyword dictionary. Only whole words match, so "rice" is not found
+// in "licorice".
+func Categorize(title string) Category {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for i, w := range words {
+		words[i] = singular(w)
+	}
+
+	// Prefer the longest phrase so that "ice cream" beats "cream", and scan
+	// from the end since the last word usually names the thing itself
+	// ("orange juice", "chicken stock").
+	for size := 3; size >= 1; size-- {
+		for i := len(words) - size; i >= 0; i-- {
+			if c, ok := keywords[strings.Join(words[i:i+size], " ")]; ok {
+				return c
+			}
+		}
+	}
+	return CategoryOther
+}
+
+// singular strips common English plural endings.
+func singular(w string) string {
+	if _, ok := keywords[w]; ok {
+		return w
+	}
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "oes") && len(w) > 4:
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && len(w) > 3:
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+// AisleGroup holds the items of one category in a list grouped for walking
+// the store.
+type AisleGroup struct {
+	Category Category `json:"category"`
+	Items    []Item   `json:"items"`
+}
+
+// GroupByAisle groups items by category following order. Categories missing
+// from order are appended in their default position; empty groups are
+// omitted. Items keep their relative order within a group.
+func GroupByAisle(items []Item, order []Category) []AisleGroup {
+	byCategory := make(map[Category][]Item)
+	for _, item := range items {
+		c := item.Category
+		if !isValidCategory(c) {
+			c = CategoryOther
+		}
+		byCategory[c] = append(byCategory[c], item)
+	}
+
+	groups := []AisleGroup{}
+	for _, c := range completeAisleOrder(order) {
+		if len(byCategory[c]) > 0 {
+			groups = append(groups, AisleGroup{Category: c, Items: byCategory[c]})
+		}
+	}
+	return groups
+}
+
+func completeAisleOrder(order []Category) []Category {
+	seen := make(map[Category]bool, len(DefaultAisleOrder))
+	full := make([]Category, 0, len(DefaultAisleOrder))
+	for _, c := range order {
+		if !seen[c] {
+			seen[c] = true
+			full = append(full, c)
+		}
+	}
+	for _, c := range DefaultAisleOrder {
+		if !seen[c] {
+			full = append(full, c)
+		}
+	}
+	return full
+}
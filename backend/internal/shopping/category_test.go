@@ -0,0 +1,26 @@
+package shopping
+
+import "testing"
+
+func TestCategorize(t *testing.T) {
+	tests := []struct {
+		title string
+		want  Category
+	}{
+		{"Bananas", CategoryProduce},
+		{"Orange juice", CategoryBeverages},
+		{"Ice cream", CategoryFrozen},
+		{"Bread rolls", CategoryBakery},
+		{"Toilet roll", CategoryHousehold},
+		{"Black pepper", CategoryPantry},
+		{"Red peppers", CategoryProduce},
+		{"Ginger ale", CategoryBeverages},
+		{"Licorice", CategoryOther},
+		{"Cannelloni", CategoryOther},
+	}
+	for _, tt := range tests {
+		if got := Categorize(tt.title); got != tt.want {
+			t.Errorf("Categorize(%q) = %s, want %s", tt.title, got, tt.want)
+		}
+	}
+}
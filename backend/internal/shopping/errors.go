@@ -25,3 +25,15 @@ func (e NotFoundError) Error() string {
 func ErrNotFound(id int64) NotFoundError {
 	return NotFoundError{ID: id}
 }
+
+type ListNotFoundError struct {
+	ID int64
+}
+
+func (e ListNotFoundError) Error() string {
+	return fmt.Sprintf("shopping list with id %d not found", e.ID)
+}
+
+func ErrListNotFound(id int64) ListNotFoundError {
+	return ListNotFoundError{ID: id}
+}
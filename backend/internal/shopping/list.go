@@ -0,0 +1,67 @@
+package shopping
+
+import (
+	"fmt"
+	"time"
+)
+
+// List is a named shopping list, typically one per store. Exactly one list is
+// the default, which backs the plain /api/shopping endpoints.
+type List struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Icon      string `json:"icon"`
+	SortOrder int    `json:"sortOrder"`
+	IsDefault bool   `json:"isDefault"`
+	// AisleOrder is the order categories are encountered when walking the
+	// store. Categories not listed follow in DefaultAisleOrder.
+	AisleOrder   []Category `json:"aisleOrder"`
+	PendingCount int        `json:"pendingCount"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+type CreateListRequest struct {
+	Name       string     `json:"name"`
+	Icon       string     `json:"icon"`
+	SortOrder  int        `json:"sortOrder"`
+	AisleOrder []Category `json:"aisleOrder"`
+}
+
+func (r CreateListRequest) Validate() error {
+	if r.Name == "" {
+		return ErrValidation("name is required")
+	}
+	return validateAisleOrder(r.AisleOrder)
+}
+
+type UpdateListRequest struct {
+	Name       *string     `json:"name,omitempty"`
+	Icon       *string     `json:"icon,omitempty"`
+	SortOrder  *int        `json:"sortOrder,omitempty"`
+	AisleOrder *[]Category `json:"aisleOrder,omitempty"`
+}
+
+func (r UpdateListRequest) Validate() error {
+	if r.Name != nil && *r.Name == "" {
+		return ErrValidation("name cannot be empty")
+	}
+	if r.AisleOrder != nil {
+		return validateAisleOrder(*r.AisleOrder)
+	}
+	return nil
+}
+
+func validateAisleOrder(order []Category) error {
+	seen := make(map[Category]bool, len(order))
+	for _, c := range order {
+		if !isValidCategory(c) {
+			return ErrValidation(fmt.Sprintf("invalid category in aisle order: %q", c))
+		}
+		if seen[c] {
+			return ErrValidation(fmt.Sprintf("duplicate category in aisle order: %q", c))
+		}
+		seen[c] = true
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package shopping
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// historyWindow bounds how far back purchases feed suggestions.
+	historyWindow = 180 * 24 * time.Hour
+	// recencyHalfLife is how long it takes a purchase to count half as much
+	// when ranking suggestions.
+	recencyHalfLife = 30 * 24 * time.Hour
+	// minStaplePurchases is how many purchases on separate days are needed
+	// before an item is considered a staple.
+	minStaplePurchases = 3
+	maxSuggestions     = 10
+)
+
+// Purchase is recorded whenever an item is checked off.
+type Purchase struct {
+	ID          int64     `json:"id"`
+	ItemID      int64     `json:"itemId"`
+	ListID      int64     `json:"listId"`
+	Title       string    `json:"title"`
+	Category    Category  `json:"category"`
+	Quantity    float64   `json:"quantity"`
+	Unit        string    `json:"unit"`
+	PurchasedAt time.Time `json:"purchasedAt"`
+}
+
+type PurchaseFilter struct {
+	// Prefix matches the start of any word of the normalized title.
+	Prefix string
+	Since  time.Time
+}
+
+// Suggestion is an autocomplete candidate drawn from purchase history.
+type Suggestion struct {
+	Title           string    `json:"title"`
+	Category        Category  `json:"category"`
+	Count           int       `json:"count"`
+	LastPurchasedAt time.Time `json:"lastPurchasedAt"`
+	Score           float64   `json:"score"`
+}
+
+// Staple is an item bought at a regular interval that is due again.
+type Staple struct {
+	Title           string    `json:"title"`
+	Category        Category  `json:"category"`
+	IntervalDays    int       `json:"intervalDays"`
+	PurchaseCount   int       `json:"purchaseCount"`
+	LastPurchasedAt time.Time `json:"lastPurchasedAt"`
+	DueAt           time.Time `json:"dueAt"`
+}
+
+// groupPurchases buckets purchases by normalized title, keeping each bucket in
+// chronological order.
+func groupPurchases(purchases []Purchase) map[string][]Purchase {
+	sort.Slice(purchases, func(i, j int) bool {
+		return purchases[i].PurchasedAt.Before(purchases[j].PurchasedAt)
+	})
+	byTerm := make(map[string][]Purchase)
+	for _, p := range purchases {
+		term := NormalizeTitle(p.Title)
+		byTerm[term] = append(byTerm[term], p)
+	}
+	return byTerm
+}
+
+// rankSuggestions scores each title by how often it was bought, with every
+// purchase decaying by recencyHalfLife, so that both frequent and recent
+// items rank high.
+func rankSuggestions(purchases []Purchase, now time.Time) []Suggestion {
+	suggestions := []Suggestion{}
+	for _, history := range groupPurchases(purchases) {
+		var score float64
+		for _, p := range history {
+			age := now.Sub(p.PurchasedAt)
+			score += math.Pow(0.5, float64(age)/float64(recencyHalfLife))
+		}
+		last := history[len(history)-1]
+		suggestions = append(suggestions, Suggestion{
+			Title:           last.Title,
+			Category:        last.Category,
+			Count:           len(history),
+			LastPurchasedAt: last.PurchasedAt,
+			Score:           math.Round(score*1000) / 1000,
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return strings.ToLower(suggestions[i].Title) < strings.ToLower(suggestions[j].Title)
+	})
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions
+}
+
+// findStaples returns items whose typical interval between purchases has
+// elapsed (or will within a day) since they were last bought. The interval is
+// the median gap between purchases made on separate days.
+func findStaples(purchases []Purchase, pending map[string]bool, now time.Time) []Staple {
+	staples := []Staple{}
+	for term, history := range groupPurchases(purchases) {
+		if pending[term] {
+			continue
+		}
+
+		var days []time.Time
+		for _, p := range history {
+			day := truncateDay(p.PurchasedAt)
+			if len(days) == 0 || !day.Equal(days[len(days)-1]) {
+				days = append(days, day)
+			}
+		}
+		if len(days) < minStaplePurchases {
+			continue
+		}
+
+		gaps := make([]float64, 0, len(days)-1)
+		for i := 1; i < len(days); i++ {
+			gaps = append(gaps, days[i].Sub(days[i-1]).Hours()/24)
+		}
+		sort.Float64s(gaps)
+		interval := int(math.Round(gaps[len(gaps)/2]))
+		if len(gaps)%2 == 0 {
+			interval = int(math.Round((gaps[len(gaps)/2-1] + gaps[len(gaps)/2]) / 2))
+		}
+
+		last := history[len(history)-1]
+		due := truncateDay(last.PurchasedAt).AddDate(0, 0, interval)
+		if now.Before(due.AddDate(0, 0, -1)) {
+			continue
+		}
+
+		staples = append(staples, Staple{
+			Title:           last.Title,
+			Category:        last.Category,
+			IntervalDays:    interval,
+			PurchaseCount:   len(days),
+			LastPurchasedAt: last.PurchasedAt,
+			DueAt:           due,
+		})
+	}
+
+	sort.Slice(staples, func(i, j int) bool {
+		return staples[i].DueAt.Before(staples[j].DueAt)
+	})
+	return staples
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
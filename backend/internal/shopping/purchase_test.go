@@ -0,0 +1,59 @@
+package shopping
+
+import (
+	"testing"
+	"time"
+)
+
+func purchasesOn(title string, days ...int) []Purchase {
+	base := time.Date(2025, 1, 1, 18, 0, 0, 0, time.UTC)
+	var ps []Purchase
+	for _, d := range days {
+		ps = append(ps, Purchase{Title: title, Category: CategoryDairy, PurchasedAt: base.AddDate(0, 0, d)})
+	}
+	return ps
+}
+
+func TestFindStaples(t *testing.T) {
+	purchases := append(purchasesOn("Oat milk", 0, 7, 14, 21), purchasesOn("Candles", 0, 40)...)
+	now := time.Date(2025, 1, 28, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		pending map[string]bool
+		want    int
+	}{
+		{name: "due", now: now, want: 1},
+		{name: "already on a list", now: now, pending: map[string]bool{"oat milk": true}, want: 0},
+		{name: "not due yet", now: now.AddDate(0, 0, -4), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findStaples(purchases, tt.pending, tt.now)
+			if len(got) != tt.want {
+				t.Fatalf("len(staples) = %d, want %d", len(got), tt.want)
+			}
+			if tt.want == 1 && (got[0].Title != "Oat milk" || got[0].IntervalDays != 7) {
+				t.Errorf("staple = %+v, want Oat milk every 7 days", got[0])
+			}
+		})
+	}
+}
+
+func TestRankSuggestions(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	purchases := append(purchasesOn("Oranges", 0, 1, 2), purchasesOn("Oat milk", 140, 145)...)
+
+	got := rankSuggestions(purchases, now)
+	if len(got) != 2 {
+		t.Fatalf("len(suggestions) = %d, want 2", len(got))
+	}
+	if got[0].Title != "Oat milk" {
+		t.Errorf("first suggestion = %q, want recent purchase to rank first", got[0].Title)
+	}
+	if got[1].Count != 3 {
+		t.Errorf("count = %d, want 3", got[1].Count)
+	}
+}
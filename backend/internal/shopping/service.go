@@ -3,14 +3,25 @@ package shopping
 import (
 	"context"
 	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/unit"
 )
 
+// Pantry is told about items as they are checked off and unchecked again.
+type Pantry interface {
+	StockShoppingItem(ctx context.Context, item Item) error
+	UnstockShoppingItem(ctx context.Context, itemID int64) error
+}
+
 type Service struct {
-	store Store
+	store  Store
+	pantry Pantry
 }
 
-func NewService(store Store) *Service {
-	return &Service{store: store}
+// NewService creates a shopping service. pantry may be nil, in which case
+// checked items are not transferred anywhere.
+func NewService(store Store, pantry Pantry) *Service {
+	return &Service{store: store, pantry: pantry}
 }
 
 func (s *Service) Create(ctx context.Context, req CreateRequest) (*Item, error) {
@@ -18,8 +29,33 @@ func (s *Service) Create(ctx context.Context, req CreateRequest) (*Item, error)
 		return nil, err
 	}
 
+	listID, err := s.resolveListID(ctx, req.ListID)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	category := req.Category
+	if category != "" {
+		if err := s.store.LearnCategory(ctx, NormalizeTitle(req.Title), category); err != nil {
+			return nil, err
+		}
+	} else if category, err = s.categorize(ctx, req.Title); err != nil {
+		return nil, err
+	}
+
 	item := &Item{
+		ListID:    listID,
 		Title:     req.Title,
+		Quantity:  quantity,
+		Unit:      req.Unit,
+		Note:      req.Note,
+		Category:  category,
+		Tags:      []string{},
 		CreatedAt: time.Now(),
 	}
 
@@ -30,10 +66,340 @@ func (s *Service) Create(ctx context.Context, req CreateRequest) (*Item, error)
 	return item, nil
 }
 
-func (s *Service) List(ctx context.Context) ([]Item, error) {
-	return s.store.List(ctx)
+// AddOrMerge adds an item to a list unless an unchecked item with the same
+// title and unit, however the unit is spelled, is already there, in which
+// case that item's quantity is increased instead. It reports whether an
+// existing item was merged into.
+func (s *Service) AddOrMerge(ctx context.Context, req CreateRequest) (*Item, bool, error) {
+	if err := req.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	listID, err := s.resolveListID(ctx, req.ListID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	items, err := s.store.List(ctx, listID, Filter{})
+	if err != nil {
+		return nil, false, err
+	}
+
+	term := NormalizeTitle(req.Title)
+	for _, item := range items {
+		if item.Checked || NormalizeTitle(item.Title) != term ||
+			unit.Normalize(item.Unit) != unit.Normalize(req.Unit) {
+			continue
+		}
+		if req.Quantity > 0 {
+			item.Quantity += req.Quantity
+			if err := s.store.Update(ctx, &item); err != nil {
+				return nil, false, err
+			}
+		}
+		return &item, true, nil
+	}
+
+	req.ListID = &listID
+	item, err := s.Create(ctx, req)
+	if err != nil {
+		return nil, false, err
+	}
+	return item, false, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int64) (*Item, error) {
+	return s.store.GetByID(ctx, id)
+}
+
+// List returns the items of the default list.
+func (s *Service) List(ctx context.Context, filter Filter) ([]Item, error) {
+	l, err := s.store.GetDefaultList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.List(ctx, l.ID, filter)
+}
+
+// ListItems returns the items of the given list.
+func (s *Service) ListItems(ctx context.Context, listID int64, filter Filter) ([]Item, error) {
+	if _, err := s.store.GetList(ctx, listID); err != nil {
+		return nil, err
+	}
+	return s.store.List(ctx, listID, filter)
+}
+
+func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Item, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	item, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ListID != nil && *req.ListID != item.ListID {
+		if _, err := s.store.GetList(ctx, *req.ListID); err != nil {
+			return nil, err
+		}
+		item.ListID = *req.ListID
+	}
+	if req.Title != nil && *req.Title != item.Title {
+		item.Title = *req.Title
+		if req.Category == nil {
+			if item.Category, err = s.categorize(ctx, item.Title); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if req.Category != nil {
+		item.Category = *req.Category
+		if err := s.store.LearnCategory(ctx, NormalizeTitle(item.Title), item.Category); err != nil {
+			return nil, err
+		}
+	}
+	if req.Quantity != nil {
+		item.Quantity = *req.Quantity
+	}
+	if req.Unit != nil {
+		item.Unit = *req.Unit
+	}
+	if req.Note != nil {
+		item.Note = *req.Note
+	}
+	checkedChanged := req.Checked != nil && *req.Checked != item.Checked
+	if checkedChanged {
+		item.Checked = *req.Checked
+		item.CheckedAt = nil
+		if item.Checked {
+			now := time.Now()
+			item.CheckedAt = &now
+		}
+	}
+
+	if err := s.store.Update(ctx, item); err != nil {
+		return nil, err
+	}
+
+	if checkedChanged {
+		if err := s.recordCheck(ctx, item); err != nil {
+			return nil, err
+		}
+	}
+
+	return item, nil
+}
+
+// recordCheck keeps purchase history and the pantry in step with an item's
+// checked state.
+func (s *Service) recordCheck(ctx context.Context, item *Item) error {
+	if !item.Checked {
+		if err := s.store.DeletePurchase(ctx, item.ID); err != nil {
+			return err
+		}
+		if s.pantry != nil {
+			return s.pantry.UnstockShoppingItem(ctx, item.ID)
+		}
+		return nil
+	}
+
+	err := s.store.RecordPurchase(ctx, &Purchase{
+		ItemID:      item.ID,
+		ListID:      item.ListID,
+		Title:       item.Title,
+		Category:    item.Category,
+		Quantity:    item.Quantity,
+		Unit:        item.Unit,
+		PurchasedAt: *item.CheckedAt,
+	})
+	if err != nil {
+		return err
+	}
+	if s.pantry != nil {
+		return s.pantry.StockShoppingItem(ctx, *item)
+	}
+	return nil
+}
+
+// Suggestions returns previously bought items whose title has a word
+// starting with prefix, ranked by frequency and recency.
+func (s *Service) Suggestions(ctx context.Context, prefix string) ([]Suggestion, error) {
+	now := time.Now()
+	purchases, err := s.store.Purchases(ctx, PurchaseFilter{
+		Prefix: NormalizeTitle(prefix),
+		Since:  now.Add(-historyWindow),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rankSuggestions(purchases, now), nil
+}
+
+// Staples returns regularly bought items that are due again and not already
+// on a list.
+func (s *Service) Staples(ctx context.Context) ([]Staple, error) {
+	now := time.Now()
+	purchases, err := s.store.Purchases(ctx, PurchaseFilter{Since: now.Add(-historyWindow)})
+	if err != nil {
+		return nil, err
+	}
+
+	titles, err := s.store.PendingTitles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending := make(map[string]bool, len(titles))
+	for _, t := range titles {
+		pending[NormalizeTitle(t)] = true
+	}
+
+	return findStaples(purchases, pending, now), nil
 }
 
 func (s *Service) Delete(ctx context.Context, id int64) error {
 	return s.store.Delete(ctx, id)
 }
+
+// ClearChecked removes the checked items of the default list and returns how
+// many were removed.
+func (s *Service) ClearChecked(ctx context.Context) (int64, error) {
+	l, err := s.store.GetDefaultList(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return s.store.DeleteChecked(ctx, l.ID)
+}
+
+// ClearCheckedList removes the checked items of the given list.
+func (s *Service) ClearCheckedList(ctx context.Context, listID int64) (int64, error) {
+	if _, err := s.store.GetList(ctx, listID); err != nil {
+		return 0, err
+	}
+	return s.store.DeleteChecked(ctx, listID)
+}
+
+func (s *Service) CreateList(ctx context.Context, req CreateListRequest) (*List, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	aisleOrder := req.AisleOrder
+	if aisleOrder == nil {
+		aisleOrder = []Category{}
+	}
+
+	l := &List{
+		Name:       req.Name,
+		Icon:       req.Icon,
+		SortOrder:  req.SortOrder,
+		AisleOrder: aisleOrder,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := s.store.CreateList(ctx, l); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Aisles returns the items of a list grouped by category in the list's
+// aisle order.
+func (s *Service) Aisles(ctx context.Context, listID int64, filter Filter) ([]AisleGroup, error) {
+	l, err := s.store.GetList(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.store.List(ctx, listID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return GroupByAisle(items, l.AisleOrder), nil
+}
+
+// DefaultList returns the list backing the plain /api/shopping endpoints.
+func (s *Service) DefaultList(ctx context.Context) (*List, error) {
+	return s.store.GetDefaultList(ctx)
+}
+
+func (s *Service) GetList(ctx context.Context, id int64) (*List, error) {
+	return s.store.GetList(ctx, id)
+}
+
+func (s *Service) Lists(ctx context.Context) ([]List, error) {
+	return s.store.Lists(ctx)
+}
+
+func (s *Service) UpdateList(ctx context.Context, id int64, req UpdateListRequest) (*List, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	l, err := s.store.GetList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		l.Name = *req.Name
+	}
+	if req.Icon != nil {
+		l.Icon = *req.Icon
+	}
+	if req.SortOrder != nil {
+		l.SortOrder = *req.SortOrder
+	}
+	if req.AisleOrder != nil {
+		l.AisleOrder = *req.AisleOrder
+	}
+	l.UpdatedAt = time.Now()
+
+	if err := s.store.UpdateList(ctx, l); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (s *Service) DeleteList(ctx context.Context, id int64) error {
+	l, err := s.store.GetList(ctx, id)
+	if err != nil {
+		return err
+	}
+	if l.IsDefault {
+		return ErrValidation("the default list cannot be deleted")
+	}
+	return s.store.DeleteList(ctx, id)
+}
+
+func (s *Service) resolveListID(ctx context.Context, id *int64) (int64, error) {
+	if id == nil {
+		l, err := s.store.GetDefaultList(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return l.ID, nil
+	}
+	if _, err := s.store.GetList(ctx, *id); err != nil {
+		return 0, err
+	}
+	return *id, nil
+}
+
+// categorize prefers a category the household picked by hand for the same
+// title over the built-in dictionary.
+func (s *Service) categorize(ctx context.Context, title string) (Category, error) {
+	learned, err := s.store.LearnedCategory(ctx, NormalizeTitle(title))
+	if err != nil {
+		return "", err
+	}
+	if learned != "" {
+		return learned, nil
+	}
+	return Categorize(title), nil
+}
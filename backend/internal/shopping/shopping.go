@@ -3,18 +3,68 @@ package shopping
 import "time"
 
 type Item struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        int64      `json:"id"`
+	ListID    int64      `json:"listId"`
+	Title     string     `json:"title"`
+	Quantity  float64    `json:"quantity"`
+	Unit      string     `json:"unit"`
+	Note      string     `json:"note"`
+	Category  Category   `json:"category"`
+	Checked   bool       `json:"checked"`
+	CheckedAt *time.Time `json:"checkedAt,omitempty"`
+	Tags      []string   `json:"tags"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Filter narrows item listings; zero values match everything.
+type Filter struct {
+	Tag string
 }
 
 type CreateRequest struct {
-	Title string `json:"title"`
+	// ListID selects the list to add to; the default list is used when nil.
+	ListID   *int64  `json:"listId,omitempty"`
+	Title    string  `json:"title"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	Note     string  `json:"note"`
+	// Category overrides automatic categorisation when set.
+	Category Category `json:"category"`
 }
 
 func (r CreateRequest) Validate() error {
 	if r.Title == "" {
 		return ErrValidation("title is required")
 	}
+	if r.Quantity < 0 {
+		return ErrValidation("quantity must be positive")
+	}
+	if r.Category != "" && !isValidCategory(r.Category) {
+		return ErrValidation("invalid category")
+	}
+	return nil
+}
+
+type UpdateRequest struct {
+	// ListID moves the item to another list.
+	ListID   *int64    `json:"listId,omitempty"`
+	Title    *string   `json:"title,omitempty"`
+	Quantity *float64  `json:"quantity,omitempty"`
+	Unit     *string   `json:"unit,omitempty"`
+	Note     *string   `json:"note,omitempty"`
+	Category *Category `json:"category,omitempty"`
+	Checked  *bool     `json:"checked,omitempty"`
+}
+
+func (r UpdateRequest) Validate() error {
+	if r.Title != nil && *r.Title == "" {
+		return ErrValidation("title cannot be empty")
+	}
+	if r.Quantity != nil && *r.Quantity <= 0 {
+		return ErrValidation("quantity must be positive")
+	}
+	if r.Category != nil && !isValidCategory(*r.Category) {
+		return ErrValidation("invalid category")
+	}
 	return nil
 }
@@ -4,6 +4,32 @@ import "context"
 
 type Store interface {
 	Create(ctx context.Context, item *Item) error
-	List(ctx context.Context) ([]Item, error)
+	GetByID(ctx context.Context, id int64) (*Item, error)
+	List(ctx context.Context, listID int64, filter Filter) ([]Item, error)
+	Update(ctx context.Context, item *Item) error
 	Delete(ctx context.Context, id int64) error
+	// DeleteChecked removes the checked items of a list and returns how many
+	// were removed.
+	DeleteChecked(ctx context.Context, listID int64) (int64, error)
+
+	CreateList(ctx context.Context, l *List) error
+	GetList(ctx context.Context, id int64) (*List, error)
+	GetDefaultList(ctx context.Context) (*List, error)
+	Lists(ctx context.Context) ([]List, error)
+	UpdateList(ctx context.Context, l *List) error
+	// DeleteList removes a list together with its items.
+	DeleteList(ctx context.Context, id int64) error
+
+	// LearnedCategory returns the category last chosen by hand for a
+	// normalized title, or "" if there is none.
+	LearnedCategory(ctx context.Context, term string) (Category, error)
+	LearnCategory(ctx context.Context, term string, c Category) error
+
+	RecordPurchase(ctx context.Context, p *Purchase) error
+	// DeletePurchase forgets the purchase recorded for an item that was
+	// unchecked again.
+	DeletePurchase(ctx context.Context, itemID int64) error
+	Purchases(ctx context.Context, filter PurchaseFilter) ([]Purchase, error)
+	// PendingTitles returns the titles of unchecked items across all lists.
+	PendingTitles(ctx context.Context) ([]string, error)
 }
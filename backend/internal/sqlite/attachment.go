@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/stadtaev/lofam/backend/internal/attachment"
+)
+
+type AttachmentStore struct {
+	db *DB
+}
+
+func NewAttachmentStore(db *DB) *AttachmentStore {
+	return &AttachmentStore{db: db}
+}
+
+const attachmentColumns = `id, owner_type, owner_id, filename, content_type, size, thumbnail_type, blob_key, created_at`
+
+func scanAttachment(row interface{ Scan(...any) error }, a *attachment.Attachment) error {
+	return row.Scan(&a.ID, &a.OwnerType, &a.OwnerID, &a.Filename, &a.ContentType, &a.Size, &a.ThumbnailType, &a.Key, &a.CreatedAt)
+}
+
+func (s *AttachmentStore) Create(ctx context.Context, a *attachment.Attachment) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO attachments (owner_type, owner_id, filename, content_type, size, thumbnail_type, blob_key, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, a.OwnerType, a.OwnerID, a.Filename, a.ContentType, a.Size, a.ThumbnailType, a.Key, a.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	a.ID = id
+	return nil
+}
+
+func (s *AttachmentStore) GetByID(ctx context.Context, id int64) (*attachment.Attachment, error) {
+	var a attachment.Attachment
+	err := scanAttachment(s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT `+attachmentColumns+` FROM attachments WHERE id = ?`, id), &a)
+	if err == sql.ErrNoRows {
+		return nil, attachment.ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s *AttachmentStore) List(ctx context.Context, owner attachment.OwnerType, ownerID int64) ([]attachment.Attachment, error) {
+	return s.query(ctx, `
+		SELECT `+attachmentColumns+` FROM attachments
+		WHERE owner_type = ? AND owner_id = ?
+		ORDER BY created_at, id
+	`, owner, ownerID)
+}
+
+func (s *AttachmentStore) Delete(ctx context.Context, id int64) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM attachments WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return attachment.ErrNotFound(id)
+	}
+	return nil
+}
+
+func (s *AttachmentStore) Orphans(ctx context.Context) ([]attachment.Attachment, error) {
+	return s.query(ctx, `
+		SELECT `+attachmentColumns+` FROM attachments a
+		WHERE (owner_type = ? AND NOT EXISTS (SELECT 1 FROM tasks WHERE id = a.owner_id))
+		   OR (owner_type = ? AND NOT EXISTS (SELECT 1 FROM notes WHERE id = a.owner_id))
+		ORDER BY id
+	`, attachment.OwnerTask, attachment.OwnerNote)
+}
+
+func (s *AttachmentStore) query(ctx context.Context, query string, args ...any) ([]attachment.Attachment, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []attachment.Attachment{}
+	for rows.Next() {
+		var a attachment.Attachment
+		if err := scanAttachment(rows, &a); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
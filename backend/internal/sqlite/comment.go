@@ -0,0 +1,200 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/stadtaev/lofam/backend/internal/comment"
+)
+
+type CommentStore struct {
+	db *DB
+}
+
+func NewCommentStore(db *DB) *CommentStore {
+	return &CommentStore{db: db}
+}
+
+const commentColumns = `id, task_id, author_id, body, created_at, updated_at`
+
+func scanComment(row interface{ Scan(...any) error }, c *comment.Comment) error {
+	return row.Scan(&c.ID, &c.TaskID, &c.AuthorID, &c.Body, &c.CreatedAt, &c.UpdatedAt)
+}
+
+func (s *CommentStore) Create(ctx context.Context, c *comment.Comment) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := s.db.conn(ctx).ExecContext(ctx, `
+			INSERT INTO task_comments (task_id, author_id, body, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, c.TaskID, c.AuthorID, c.Body, c.CreatedAt, c.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		c.ID = id
+
+		return s.replaceMentions(ctx, c)
+	})
+}
+
+func (s *CommentStore) GetByID(ctx context.Context, id int64) (*comment.Comment, error) {
+	var c comment.Comment
+	err := scanComment(s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT `+commentColumns+` FROM task_comments WHERE id = ?`, id), &c)
+	if err == sql.ErrNoRows {
+		return nil, comment.ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.loadMentions(ctx, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	c.Mentions = mentions[id]
+
+	return &c, nil
+}
+
+func (s *CommentStore) List(ctx context.Context, taskID int64) ([]comment.Comment, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx,
+		`SELECT `+commentColumns+` FROM task_comments WHERE task_id = ? ORDER BY created_at, id`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []comment.Comment{}
+	for rows.Next() {
+		var c comment.Comment
+		if err := scanComment(rows, &c); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	ids := make([]int64, len(comments))
+	for i, c := range comments {
+		ids[i] = c.ID
+	}
+	mentions, err := s.loadMentions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range comments {
+		comments[i].Mentions = mentions[comments[i].ID]
+	}
+
+	return comments, nil
+}
+
+func (s *CommentStore) Update(ctx context.Context, c *comment.Comment) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := s.db.conn(ctx).ExecContext(ctx,
+			`UPDATE task_comments SET body = ?, updated_at = ? WHERE id = ?`, c.Body, c.UpdatedAt, c.ID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return comment.ErrNotFound(c.ID)
+		}
+
+		return s.replaceMentions(ctx, c)
+	})
+}
+
+func (s *CommentStore) Delete(ctx context.Context, id int64) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		n, err := s.db.deleteComments(ctx, "id = ?", id)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return comment.ErrNotFound(id)
+		}
+		return nil
+	})
+}
+
+func (s *CommentStore) replaceMentions(ctx context.Context, c *comment.Comment) error {
+	if _, err := s.db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM comment_mentions WHERE comment_id = ?`, c.ID); err != nil {
+		return err
+	}
+	for i, memberID := range c.Mentions {
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`INSERT INTO comment_mentions (comment_id, member_id, position) VALUES (?, ?, ?)`,
+			c.ID, memberID, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadMentions returns the mentioned members of each comment, in the order
+// they appear.
+func (s *CommentStore) loadMentions(ctx context.Context, commentIDs []int64) (map[int64][]int64, error) {
+	mentions := make(map[int64][]int64, len(commentIDs))
+	for _, id := range commentIDs {
+		mentions[id] = []int64{}
+	}
+	if len(commentIDs) == 0 {
+		return mentions, nil
+	}
+
+	placeholders, args := inClause(commentIDs)
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT comment_id, member_id FROM comment_mentions
+		WHERE comment_id IN (`+placeholders+`)
+		ORDER BY comment_id, position
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var commentID, memberID int64
+		if err := rows.Scan(&commentID, &memberID); err != nil {
+			return nil, err
+		}
+		mentions[commentID] = append(mentions[commentID], memberID)
+	}
+	return mentions, rows.Err()
+}
+
+// deleteComments deletes the comments matching a condition on
+// task_comments, along with their mentions and the notifications about
+// them, and returns how many comments were deleted. It must run inside a
+// transaction.
+func (db *DB) deleteComments(ctx context.Context, where string, args ...any) (int64, error) {
+	selectIDs := `SELECT id FROM task_comments WHERE ` + where
+	if _, err := db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM comment_mentions WHERE comment_id IN (`+selectIDs+`)`, args...); err != nil {
+		return 0, err
+	}
+	if _, err := db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM notifications WHERE comment_id IN (`+selectIDs+`)`, args...); err != nil {
+		return 0, err
+	}
+
+	result, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM task_comments WHERE `+where, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -1,10 +1,12 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
@@ -13,6 +15,82 @@ type DB struct {
 	*sql.DB
 }
 
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+type txState struct {
+	tx         *sql.Tx
+	savepoints int
+}
+
+// conn returns the transaction carried by ctx, if any, so that stores take
+// part in a surrounding WithinTx call. Since the pool holds a single
+// connection, stores must always go through conn rather than db directly.
+func (db *DB) conn(ctx context.Context) querier {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok {
+		return st.tx
+	}
+	return db.DB
+}
+
+// WithinTx runs fn in a transaction that is committed if fn returns nil and
+// rolled back otherwise. Calls nested inside an existing transaction join it.
+func (db *DB) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{tx: tx})); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// WithinSavepoint runs fn inside a savepoint of the transaction carried by
+// ctx, undoing only fn's changes if it fails. Without a surrounding
+// transaction it behaves like WithinTx.
+func (db *DB) WithinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	st, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return db.WithinTx(ctx, fn)
+	}
+
+	st.savepoints++
+	name := fmt.Sprintf("sp_%d", st.savepoints)
+	if _, err := st.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("create savepoint: %w", err)
+	}
+
+	if err := fn(ctx); err != nil {
+		if _, rbErr := st.tx.ExecContext(ctx, "ROLLBACK TO "+name); rbErr != nil {
+			return fmt.Errorf("rollback to savepoint: %w", rbErr)
+		}
+		st.tx.ExecContext(ctx, "RELEASE "+name)
+		return err
+	}
+
+	if _, err := st.tx.ExecContext(ctx, "RELEASE "+name); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+	return nil
+}
+
 func New(dbPath string) (*DB, error) {
 	dir := filepath.Dir(dbPath)
 	if dir != "" && dir != "." {
@@ -75,6 +153,60 @@ func (db *DB) Migrate() error {
 
 	CREATE INDEX IF NOT EXISTS idx_wishlists_created_at ON wishlists(created_at DESC);
 
+	CREATE TABLE IF NOT EXISTS members (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS wishlist_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		wishlist_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		url TEXT NOT NULL DEFAULT '',
+		price_cents INTEGER,
+		currency TEXT NOT NULL DEFAULT '',
+		quantity INTEGER NOT NULL DEFAULT 1,
+		priority TEXT NOT NULL DEFAULT 'medium',
+		image_url TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_wishlist_items_wishlist_id ON wishlist_items(wishlist_id);
+
+	CREATE TABLE IF NOT EXISTS wishlist_shares (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		wishlist_id INTEGER NOT NULL,
+		token TEXT NOT NULL UNIQUE,
+		expires_at DATETIME,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_wishlist_shares_wishlist_id ON wishlist_shares(wishlist_id);
+
+	CREATE TABLE IF NOT EXISTS occasions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		person TEXT NOT NULL DEFAULT '',
+		member_id INTEGER,
+		date TEXT NOT NULL,
+		yearly INTEGER NOT NULL DEFAULT 0,
+		budget_cents INTEGER,
+		currency TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS occasion_wishlists (
+		occasion_id INTEGER NOT NULL,
+		wishlist_id INTEGER NOT NULL,
+		PRIMARY KEY (occasion_id, wishlist_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_occasion_wishlists_wishlist_id ON occasion_wishlists(wishlist_id);
+
 	CREATE TABLE IF NOT EXISTS shopping_items (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		title TEXT NOT NULL,
@@ -82,11 +214,353 @@ func (db *DB) Migrate() error {
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_shopping_items_created_at ON shopping_items(created_at DESC);
+
+	CREATE TABLE IF NOT EXISTS shopping_lists (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		icon TEXT NOT NULL DEFAULT '',
+		sort_order INTEGER NOT NULL DEFAULT 0,
+		is_default INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	INSERT INTO shopping_lists (name, is_default)
+	SELECT 'Shopping', 1
+	WHERE NOT EXISTS (SELECT 1 FROM shopping_lists WHERE is_default = 1);
+
+	CREATE TABLE IF NOT EXISTS shopping_learned_categories (
+		term TEXT PRIMARY KEY,
+		category TEXT NOT NULL,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS shopping_purchases (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		item_id INTEGER NOT NULL,
+		list_id INTEGER NOT NULL,
+		term TEXT NOT NULL,
+		title TEXT NOT NULL,
+		category TEXT NOT NULL DEFAULT 'other',
+		quantity REAL NOT NULL DEFAULT 1,
+		unit TEXT NOT NULL DEFAULT '',
+		purchased_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_shopping_purchases_term ON shopping_purchases(term);
+	CREATE INDEX IF NOT EXISTS idx_shopping_purchases_purchased_at ON shopping_purchases(purchased_at);
+	CREATE INDEX IF NOT EXISTS idx_shopping_purchases_item_id ON shopping_purchases(item_id);
+
+	CREATE TABLE IF NOT EXISTS recipes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		servings INTEGER NOT NULL DEFAULT 1,
+		source_url TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS recipe_ingredients (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		recipe_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		quantity REAL NOT NULL DEFAULT 0,
+		unit TEXT NOT NULL DEFAULT '',
+		name TEXT NOT NULL,
+		note TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_recipe_ingredients_recipe_id ON recipe_ingredients(recipe_id);
+
+	CREATE TABLE IF NOT EXISTS recipe_steps (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		recipe_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		text TEXT NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_recipe_steps_recipe_id ON recipe_steps(recipe_id);
+
+	CREATE TABLE IF NOT EXISTS meal_plan_entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		date TEXT NOT NULL,
+		meal TEXT NOT NULL DEFAULT 'dinner',
+		recipe_id INTEGER NOT NULL,
+		servings INTEGER NOT NULL DEFAULT 1,
+		note TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_meal_plan_entries_date ON meal_plan_entries(date);
+
+	CREATE TABLE IF NOT EXISTS pantry_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		quantity REAL NOT NULL DEFAULT 1,
+		unit TEXT NOT NULL DEFAULT '',
+		location TEXT NOT NULL DEFAULT 'pantry',
+		expires_on TEXT,
+		shopping_item_id INTEGER,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_pantry_items_expires_on ON pantry_items(expires_on);
+	CREATE INDEX IF NOT EXISTS idx_pantry_items_shopping_item_id ON pantry_items(shopping_item_id);
+
+	CREATE TABLE IF NOT EXISTS palette_colors (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		hex TEXT NOT NULL,
+		dark_hex TEXT NOT NULL,
+		position INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS tags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		color TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS taggings (
+		tag_id INTEGER NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id INTEGER NOT NULL,
+		PRIMARY KEY (tag_id, entity_type, entity_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_taggings_entity ON taggings(entity_type, entity_id);
+
+	CREATE TABLE IF NOT EXISTS attachments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		owner_type TEXT NOT NULL,
+		owner_id INTEGER NOT NULL,
+		filename TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		blob_key TEXT NOT NULL UNIQUE,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_attachments_owner ON attachments(owner_type, owner_id);
+
+	CREATE TABLE IF NOT EXISTS projects (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS project_columns (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		project_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		category TEXT NOT NULL,
+		position INTEGER NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_project_columns_project_id ON project_columns(project_id);
+
+	CREATE TABLE IF NOT EXISTS task_dependencies (
+		task_id INTEGER NOT NULL,
+		blocker_id INTEGER NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (task_id, blocker_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocker_id ON task_dependencies(blocker_id);
+
+	CREATE TABLE IF NOT EXISTS task_comments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		author_id INTEGER,
+		body TEXT NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_task_comments_task_id ON task_comments(task_id);
+
+	CREATE TABLE IF NOT EXISTS comment_mentions (
+		comment_id INTEGER NOT NULL,
+		member_id INTEGER NOT NULL,
+		position INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (comment_id, member_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS notifications (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		member_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		actor_id INTEGER,
+		task_id INTEGER,
+		comment_id INTEGER,
+		message TEXT NOT NULL,
+		read_at DATETIME,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_notifications_member_id ON notifications(member_id, created_at);
+
+	CREATE TABLE IF NOT EXISTS idempotency_keys (
+		key TEXT PRIMARY KEY,
+		request_hash TEXT NOT NULL,
+		status_code INTEGER NOT NULL,
+		body BLOB NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
 	`
 
+	// The palette is seeded only when its table is created, so that
+	// colours a household deletes stay deleted.
+	var hasPalette bool
+	if err := db.QueryRow(
+		"SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'palette_colors'",
+	).Scan(&hasPalette); err != nil {
+		return fmt.Errorf("check palette table: %w", err)
+	}
+
 	if _, err := db.Exec(schema); err != nil {
 		return fmt.Errorf("execute schema: %w", err)
 	}
 
+	// Columns added after a table was first released. SQLite has no
+	// ADD COLUMN IF NOT EXISTS, so each one is checked before altering.
+	columns := []struct {
+		table      string
+		name       string
+		definition string
+	}{
+		{"shopping_items", "quantity", "REAL NOT NULL DEFAULT 1"},
+		{"shopping_items", "unit", "TEXT NOT NULL DEFAULT ''"},
+		{"shopping_items", "note", "TEXT NOT NULL DEFAULT ''"},
+		{"shopping_items", "checked", "INTEGER NOT NULL DEFAULT 0"},
+		{"shopping_items", "checked_at", "DATETIME"},
+		{"shopping_items", "list_id", "INTEGER"},
+		{"shopping_items", "category", "TEXT NOT NULL DEFAULT 'other'"},
+		{"shopping_lists", "aisle_order", "TEXT NOT NULL DEFAULT ''"},
+		{"notes", "pinned", "INTEGER NOT NULL DEFAULT 0"},
+		{"notes", "archived", "INTEGER NOT NULL DEFAULT 0"},
+		{"notes", "position", "INTEGER NOT NULL DEFAULT 0"},
+		{"wishlists", "owner_id", "INTEGER"},
+		{"wishlist_items", "reserved_by", "INTEGER"},
+		{"wishlist_items", "reservation_status", "TEXT NOT NULL DEFAULT ''"},
+		{"wishlist_items", "reserved_at", "DATETIME"},
+		{"wishlist_items", "reserved_guest", "TEXT NOT NULL DEFAULT ''"},
+		{"attachments", "thumbnail_type", "TEXT NOT NULL DEFAULT ''"},
+		{"tasks", "project_id", "INTEGER"},
+		{"tasks", "column_id", "INTEGER"},
+		{"tasks", "rank", "TEXT NOT NULL DEFAULT ''"},
+		{"tasks", "start_at", "DATETIME"},
+		{"tasks", "end_at", "DATETIME"},
+		{"tasks", "all_day", "INTEGER NOT NULL DEFAULT 0"},
+		{"tasks", "assignee_id", "INTEGER"},
+	}
+
+	for _, c := range columns {
+		if err := db.addColumn(c.table, c.name, c.definition); err != nil {
+			return fmt.Errorf("add column %s.%s: %w", c.table, c.name, err)
+		}
+	}
+
+	// Data fixes and indexes that depend on the columns above.
+	backfill := `
+	UPDATE shopping_items
+	SET list_id = (SELECT id FROM shopping_lists WHERE is_default = 1)
+	WHERE list_id IS NULL;
+
+	CREATE INDEX IF NOT EXISTS idx_shopping_items_list_id ON shopping_items(list_id);
+	CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks(project_id);
+	CREATE INDEX IF NOT EXISTS idx_tasks_rank ON tasks(rank);
+	CREATE INDEX IF NOT EXISTS idx_tasks_column_rank ON tasks(column_id, rank);
+	CREATE INDEX IF NOT EXISTS idx_tasks_status_rank ON tasks(status, rank);
+	CREATE INDEX IF NOT EXISTS idx_tasks_start_at ON tasks(start_at);
+	`
+
+	if _, err := db.Exec(backfill); err != nil {
+		return fmt.Errorf("execute backfill: %w", err)
+	}
+
+	if !hasPalette {
+		seed := `
+		-- The palette starts with the colours that used to be hard-coded.
+		INSERT INTO palette_colors (name, hex, dark_hex, position)
+		VALUES
+			('yellow', '#fef08a', '#a16207', 0),
+			('pink', '#fbcfe8', '#be185d', 1),
+			('green', '#bbf7d0', '#15803d', 2);
+
+		-- Any other colours used by notes or wishlists stay valid, in name
+		-- order after the defaults.
+		INSERT OR IGNORE INTO palette_colors (name, hex, dark_hex, position)
+		SELECT color, '#e5e7eb', '#4b5563', 2 + ROW_NUMBER() OVER (ORDER BY color)
+		FROM (SELECT color FROM notes UNION SELECT color FROM wishlists)
+		WHERE color != '';
+		`
+		if _, err := db.Exec(seed); err != nil {
+			return fmt.Errorf("seed palette: %w", err)
+		}
+	}
+
+	// One-time data fixes are recorded in the user_version pragma.
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("read data version: %w", err)
+	}
+	if version < 1 {
+		// Items left in "other" by an older dictionary get another look.
+		if err := db.categorizeShoppingItems(context.Background()); err != nil {
+			return fmt.Errorf("categorize shopping items: %w", err)
+		}
+		if _, err := db.Exec(`PRAGMA user_version = 1`); err != nil {
+			return fmt.Errorf("record data version: %w", err)
+		}
+	}
+
+	// Tasks from before manual ordering get ranks in their old order.
+	var unranked bool
+	if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM tasks WHERE rank = '')`).Scan(&unranked); err != nil {
+		return fmt.Errorf("check task ranks: %w", err)
+	}
+	if unranked {
+		if err := db.rebalanceTasks(context.Background(), "1 = 1"); err != nil {
+			return fmt.Errorf("rank tasks: %w", err)
+		}
+	}
+
 	return nil
 }
+
+// inClause returns the placeholders and arguments for an IN (...) list.
+func inClause(ids []int64) (string, []any) {
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return strings.Join(placeholders, ", "), args
+}
+
+func (db *DB) addColumn(table, name, definition string) error {
+	var exists bool
+	err := db.QueryRow(
+		"SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = ?", table, name,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, name, definition))
+	return err
+}
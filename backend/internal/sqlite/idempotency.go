@@ -0,0 +1,45 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/idempotency"
+)
+
+type IdempotencyStore struct {
+	db *DB
+}
+
+func NewIdempotencyStore(db *DB) *IdempotencyStore {
+	return &IdempotencyStore{db: db}
+}
+
+func (s *IdempotencyStore) Get(ctx context.Context, key string) (*idempotency.Record, error) {
+	var rec idempotency.Record
+	err := s.db.conn(ctx).QueryRowContext(ctx, `
+		SELECT key, request_hash, status_code, body, created_at
+		FROM idempotency_keys WHERE key = ?
+	`, key).Scan(&rec.Key, &rec.RequestHash, &rec.StatusCode, &rec.Body, &rec.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (s *IdempotencyStore) Save(ctx context.Context, rec *idempotency.Record) error {
+	_, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT OR REPLACE INTO idempotency_keys (key, request_hash, status_code, body, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, rec.Key, rec.RequestHash, rec.StatusCode, rec.Body, rec.CreatedAt)
+	return err
+}
+
+func (s *IdempotencyStore) DeleteBefore(ctx context.Context, t time.Time) error {
+	_, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at < ?`, t)
+	return err
+}
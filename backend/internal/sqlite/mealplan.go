@@ -0,0 +1,118 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/stadtaev/lofam/backend/internal/mealplan"
+)
+
+type MealPlanStore struct {
+	db *DB
+}
+
+func NewMealPlanStore(db *DB) *MealPlanStore {
+	return &MealPlanStore{db: db}
+}
+
+func (s *MealPlanStore) Create(ctx context.Context, e *mealplan.Entry) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO meal_plan_entries (date, meal, recipe_id, servings, note, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, e.Date, e.Meal, e.RecipeID, e.Servings, e.Note, e.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	e.ID = id
+	return nil
+}
+
+func (s *MealPlanStore) GetByID(ctx context.Context, id int64) (*mealplan.Entry, error) {
+	var e mealplan.Entry
+	err := s.db.conn(ctx).QueryRowContext(ctx, `
+		SELECT m.id, m.date, m.meal, m.recipe_id, r.name, m.servings, m.note, m.created_at
+		FROM meal_plan_entries m JOIN recipes r ON r.id = m.recipe_id
+		WHERE m.id = ?
+	`, id).Scan(&e.ID, &e.Date, &e.Meal, &e.RecipeID, &e.RecipeName, &e.Servings, &e.Note, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, mealplan.ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (s *MealPlanStore) List(ctx context.Context, from, to string) ([]mealplan.Entry, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT m.id, m.date, m.meal, m.recipe_id, r.name, m.servings, m.note, m.created_at
+		FROM meal_plan_entries m JOIN recipes r ON r.id = m.recipe_id
+		WHERE (? = '' OR m.date >= ?) AND (? = '' OR m.date <= ?)
+		ORDER BY m.date,
+			CASE m.meal WHEN 'breakfast' THEN 0 WHEN 'lunch' THEN 1 WHEN 'dinner' THEN 2 ELSE 3 END,
+			m.id
+	`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []mealplan.Entry
+	for rows.Next() {
+		var e mealplan.Entry
+		if err := rows.Scan(&e.ID, &e.Date, &e.Meal, &e.RecipeID, &e.RecipeName, &e.Servings,
+			&e.Note, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	if entries == nil {
+		entries = []mealplan.Entry{}
+	}
+
+	return entries, rows.Err()
+}
+
+func (s *MealPlanStore) Update(ctx context.Context, e *mealplan.Entry) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE meal_plan_entries SET date = ?, meal = ?, recipe_id = ?, servings = ?, note = ?
+		WHERE id = ?
+	`, e.Date, e.Meal, e.RecipeID, e.Servings, e.Note, e.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return mealplan.ErrNotFound(e.ID)
+	}
+
+	return nil
+}
+
+func (s *MealPlanStore) Delete(ctx context.Context, id int64) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM meal_plan_entries WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return mealplan.ErrNotFound(id)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/stadtaev/lofam/backend/internal/member"
+)
+
+type MemberStore struct {
+	db *DB
+}
+
+func NewMemberStore(db *DB) *MemberStore {
+	return &MemberStore{db: db}
+}
+
+func (s *MemberStore) Create(ctx context.Context, m *member.Member) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO members (name, created_at, updated_at) VALUES (?, ?, ?)
+	`, m.Name, m.CreatedAt, m.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	m.ID = id
+	return nil
+}
+
+func (s *MemberStore) GetByID(ctx context.Context, id int64) (*member.Member, error) {
+	var m member.Member
+	err := s.db.conn(ctx).QueryRowContext(ctx, `
+		SELECT id, name, created_at, updated_at FROM members WHERE id = ?
+	`, id).Scan(&m.ID, &m.Name, &m.CreatedAt, &m.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, member.ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (s *MemberStore) List(ctx context.Context) ([]member.Member, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT id, name, created_at, updated_at FROM members ORDER BY name COLLATE NOCASE, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []member.Member{}
+	for rows.Next() {
+		var m member.Member
+		if err := rows.Scan(&m.ID, &m.Name, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+func (s *MemberStore) Update(ctx context.Context, m *member.Member) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE members SET name = ?, updated_at = ? WHERE id = ?
+	`, m.Name, m.UpdatedAt, m.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return member.ErrNotFound(m.ID)
+	}
+
+	return nil
+}
+
+// Delete also turns the member's wishlists into shared ones, releases their
+// reservations and unlinks their occasions.
+func (s *MemberStore) Delete(ctx context.Context, id int64) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`UPDATE wishlists SET owner_id = NULL WHERE owner_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`UPDATE occasions SET member_id = NULL WHERE member_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`UPDATE tasks SET assignee_id = NULL WHERE assignee_id = ?`, id); err != nil {
+			return err
+		}
+		// Comments stay in their threads without an author.
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`UPDATE task_comments SET author_id = NULL WHERE author_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`DELETE FROM comment_mentions WHERE member_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`DELETE FROM notifications WHERE member_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`UPDATE notifications SET actor_id = NULL WHERE actor_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := s.db.conn(ctx).ExecContext(ctx, `
+			UPDATE wishlist_items
+			SET reserved_by = NULL, reserved_guest = '', reservation_status = '', reserved_at = NULL
+			WHERE reserved_by = ?
+		`, id); err != nil {
+			return err
+		}
+
+		result, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM members WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return member.ErrNotFound(id)
+		}
+
+		return nil
+	})
+}
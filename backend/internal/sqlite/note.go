@@ -5,6 +5,7 @@ import (
 	"database/sql"
 
 	"github.com/stadtaev/lofam/backend/internal/note"
+	"github.com/stadtaev/lofam/backend/internal/tag"
 )
 
 type NoteStore struct {
@@ -15,11 +16,24 @@ func NewNoteStore(db *DB) *NoteStore {
 	return &NoteStore{db: db}
 }
 
+const noteColumns = `id, title, content, color, pinned, archived, position, created_at, updated_at`
+
+func scanNote(row interface{ Scan(...any) error }, n *note.Note) error {
+	return row.Scan(&n.ID, &n.Title, &n.Content, &n.Color, &n.Pinned, &n.Archived, &n.Position,
+		&n.CreatedAt, &n.UpdatedAt)
+}
+
+// Create puts the new note at the front of the manual order.
 func (s *NoteStore) Create(ctx context.Context, n *note.Note) error {
-	result, err := s.db.ExecContext(ctx, `
-		INSERT INTO notes (title, content, color, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?)
-	`, n.Title, n.Content, n.Color, n.CreatedAt, n.UpdatedAt)
+	if err := s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT COALESCE(MIN(position), 1) - 1 FROM notes`).Scan(&n.Position); err != nil {
+		return err
+	}
+
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO notes (title, content, color, pinned, archived, position, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, n.Title, n.Content, n.Color, n.Pinned, n.Archived, n.Position, n.CreatedAt, n.UpdatedAt)
 	if err != nil {
 		return err
 	}
@@ -35,24 +49,31 @@ func (s *NoteStore) Create(ctx context.Context, n *note.Note) error {
 
 func (s *NoteStore) GetByID(ctx context.Context, id int64) (*note.Note, error) {
 	var n note.Note
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, title, content, color, created_at, updated_at
-		FROM notes WHERE id = ?
-	`, id).Scan(&n.ID, &n.Title, &n.Content, &n.Color, &n.CreatedAt, &n.UpdatedAt)
+	err := scanNote(s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT `+noteColumns+` FROM notes WHERE id = ?`, id), &n)
 	if err == sql.ErrNoRows {
 		return nil, note.ErrNotFound(id)
 	}
 	if err != nil {
 		return nil, err
 	}
+
+	tags, err := s.db.tagNames(ctx, tag.KindNote, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	n.Tags = tags[id]
+
 	return &n, nil
 }
 
-func (s *NoteStore) List(ctx context.Context) ([]note.Note, error) {
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, title, content, color, created_at, updated_at
-		FROM notes ORDER BY created_at DESC
-	`)
+func (s *NoteStore) List(ctx context.Context, filter note.Filter) ([]note.Note, error) {
+	tagged, tagArgs := taggedWith(tag.KindNote, "id", filter.Tag)
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT `+noteColumns+` FROM notes
+		WHERE (? IS NULL OR archived = ?) AND `+tagged+`
+		ORDER BY pinned DESC, position, created_at DESC, id DESC
+	`, append([]any{filter.Archived, filter.Archived}, tagArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -61,24 +82,41 @@ func (s *NoteStore) List(ctx context.Context) ([]note.Note, error) {
 	var notes []note.Note
 	for rows.Next() {
 		var n note.Note
-		if err := rows.Scan(&n.ID, &n.Title, &n.Content, &n.Color, &n.CreatedAt, &n.UpdatedAt); err != nil {
+		if err := scanNote(rows, &n); err != nil {
 			return nil, err
 		}
 		notes = append(notes, n)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
 	if notes == nil {
 		notes = []note.Note{}
 	}
 
-	return notes, rows.Err()
+	ids := make([]int64, len(notes))
+	for i, n := range notes {
+		ids[i] = n.ID
+	}
+	tags, err := s.db.tagNames(ctx, tag.KindNote, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range notes {
+		notes[i].Tags = tags[notes[i].ID]
+	}
+
+	return notes, nil
 }
 
 func (s *NoteStore) Update(ctx context.Context, n *note.Note) error {
-	result, err := s.db.ExecContext(ctx, `
-		UPDATE notes SET title = ?, content = ?, color = ?, updated_at = ?
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE notes SET title = ?, content = ?, color = ?, pinned = ?, archived = ?, updated_at = ?
 		WHERE id = ?
-	`, n.Title, n.Content, n.Color, n.UpdatedAt, n.ID)
+	`, n.Title, n.Content, n.Color, n.Pinned, n.Archived, n.UpdatedAt, n.ID)
 	if err != nil {
 		return err
 	}
@@ -95,18 +133,74 @@ func (s *NoteStore) Update(ctx context.Context, n *note.Note) error {
 }
 
 func (s *NoteStore) Delete(ctx context.Context, id int64) error {
-	result, err := s.db.ExecContext(ctx, `DELETE FROM notes WHERE id = ?`, id)
-	if err != nil {
-		return err
-	}
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.db.untag(ctx, tag.KindNote, "?", id); err != nil {
+			return err
+		}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if rows == 0 {
-		return note.ErrNotFound(id)
-	}
+		result, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM notes WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return note.ErrNotFound(id)
+		}
+
+		return nil
+	})
+}
+
+func (s *NoteStore) Reorder(ctx context.Context, ids []int64) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		rows, err := s.db.conn(ctx).QueryContext(ctx,
+			`SELECT id FROM notes ORDER BY position, created_at DESC, id DESC`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		var current []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			current = append(current, id)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		rows.Close()
+
+		listed := make(map[int64]bool, len(ids))
+		for _, id := range ids {
+			listed[id] = true
+		}
+		order := make([]int64, 0, len(current))
+		order = append(order, ids...)
+		for _, id := range current {
+			if !listed[id] {
+				order = append(order, id)
+			}
+			delete(listed, id)
+		}
+		for _, id := range ids {
+			if listed[id] {
+				return note.ErrNotFound(id)
+			}
+		}
+
+		for position, id := range order {
+			if _, err := s.db.conn(ctx).ExecContext(ctx,
+				`UPDATE notes SET position = ? WHERE id = ?`, position, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/notification"
+)
+
+type NotificationStore struct {
+	db *DB
+}
+
+func NewNotificationStore(db *DB) *NotificationStore {
+	return &NotificationStore{db: db}
+}
+
+const notificationColumns = `id, member_id, kind, actor_id, task_id, comment_id, message, read_at, created_at`
+
+func scanNotification(row interface{ Scan(...any) error }, n *notification.Notification) error {
+	return row.Scan(&n.ID, &n.MemberID, &n.Kind, &n.ActorID, &n.TaskID, &n.CommentID,
+		&n.Message, &n.ReadAt, &n.CreatedAt)
+}
+
+func (s *NotificationStore) Create(ctx context.Context, n *notification.Notification) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO notifications (member_id, kind, actor_id, task_id, comment_id, message, read_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, n.MemberID, n.Kind, n.ActorID, n.TaskID, n.CommentID, n.Message, n.ReadAt, n.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	n.ID = id
+	return nil
+}
+
+func (s *NotificationStore) GetByID(ctx context.Context, id int64) (*notification.Notification, error) {
+	var n notification.Notification
+	err := scanNotification(s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT `+notificationColumns+` FROM notifications WHERE id = ?`, id), &n)
+	if err == sql.ErrNoRows {
+		return nil, notification.ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func (s *NotificationStore) List(ctx context.Context, memberID int64, filter notification.Filter) ([]notification.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE member_id = ?`
+	if filter.Unread {
+		query += ` AND read_at IS NULL`
+	}
+	rows, err := s.db.conn(ctx).QueryContext(ctx, query+` ORDER BY created_at DESC, id DESC`, memberID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []notification.Notification{}
+	for rows.Next() {
+		var n notification.Notification
+		if err := scanNotification(rows, &n); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+func (s *NotificationStore) MarkRead(ctx context.Context, memberID int64, ids []int64, at time.Time) error {
+	query := `UPDATE notifications SET read_at = ? WHERE member_id = ? AND read_at IS NULL`
+	args := []any{at, memberID}
+	if ids != nil {
+		if len(ids) == 0 {
+			return nil
+		}
+		placeholders, idArgs := inClause(ids)
+		query += ` AND id IN (` + placeholders + `)`
+		args = append(args, idArgs...)
+	}
+
+	_, err := s.db.conn(ctx).ExecContext(ctx, query, args...)
+	return err
+}
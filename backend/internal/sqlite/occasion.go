@@ -0,0 +1,191 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/stadtaev/lofam/backend/internal/occasion"
+)
+
+type OccasionStore struct {
+	db *DB
+}
+
+func NewOccasionStore(db *DB) *OccasionStore {
+	return &OccasionStore{db: db}
+}
+
+const occasionColumns = `id, name, person, member_id, date, yearly, budget_cents, currency,
+	created_at, updated_at`
+
+func scanOccasion(row interface{ Scan(...any) error }, o *occasion.Occasion) error {
+	return row.Scan(&o.ID, &o.Name, &o.Person, &o.MemberID, &o.Date, &o.Yearly, &o.BudgetCents,
+		&o.Currency, &o.CreatedAt, &o.UpdatedAt)
+}
+
+func (s *OccasionStore) Create(ctx context.Context, o *occasion.Occasion) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := s.db.conn(ctx).ExecContext(ctx, `
+			INSERT INTO occasions (name, person, member_id, date, yearly, budget_cents, currency,
+				created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, o.Name, o.Person, o.MemberID, o.Date, o.Yearly, o.BudgetCents, o.Currency,
+			o.CreatedAt, o.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		o.ID = id
+
+		return s.replaceWishlists(ctx, o)
+	})
+}
+
+func (s *OccasionStore) GetByID(ctx context.Context, id int64) (*occasion.Occasion, error) {
+	var o occasion.Occasion
+	err := scanOccasion(s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT `+occasionColumns+` FROM occasions WHERE id = ?`, id), &o)
+	if err == sql.ErrNoRows {
+		return nil, occasion.ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	occasions := []occasion.Occasion{o}
+	if err := s.loadWishlists(ctx, occasions); err != nil {
+		return nil, err
+	}
+	return &occasions[0], nil
+}
+
+func (s *OccasionStore) List(ctx context.Context) ([]occasion.Occasion, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx,
+		`SELECT `+occasionColumns+` FROM occasions ORDER BY date, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	occasions := []occasion.Occasion{}
+	for rows.Next() {
+		var o occasion.Occasion
+		if err := scanOccasion(rows, &o); err != nil {
+			return nil, err
+		}
+		occasions = append(occasions, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := s.loadWishlists(ctx, occasions); err != nil {
+		return nil, err
+	}
+	return occasions, nil
+}
+
+func (s *OccasionStore) loadWishlists(ctx context.Context, occasions []occasion.Occasion) error {
+	if len(occasions) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*occasion.Occasion, len(occasions))
+	ids := make([]int64, len(occasions))
+	for i := range occasions {
+		occasions[i].WishlistIDs = []int64{}
+		byID[occasions[i].ID] = &occasions[i]
+		ids[i] = occasions[i].ID
+	}
+	placeholders, args := inClause(ids)
+
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT occasion_id, wishlist_id FROM occasion_wishlists
+		WHERE occasion_id IN (`+placeholders+`)
+		ORDER BY occasion_id, wishlist_id
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var occasionID, wishlistID int64
+		if err := rows.Scan(&occasionID, &wishlistID); err != nil {
+			return err
+		}
+		o := byID[occasionID]
+		o.WishlistIDs = append(o.WishlistIDs, wishlistID)
+	}
+
+	return rows.Err()
+}
+
+func (s *OccasionStore) Update(ctx context.Context, o *occasion.Occasion) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := s.db.conn(ctx).ExecContext(ctx, `
+			UPDATE occasions
+			SET name = ?, person = ?, member_id = ?, date = ?, yearly = ?, budget_cents = ?,
+				currency = ?, updated_at = ?
+			WHERE id = ?
+		`, o.Name, o.Person, o.MemberID, o.Date, o.Yearly, o.BudgetCents, o.Currency,
+			o.UpdatedAt, o.ID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return occasion.ErrNotFound(o.ID)
+		}
+
+		return s.replaceWishlists(ctx, o)
+	})
+}
+
+func (s *OccasionStore) replaceWishlists(ctx context.Context, o *occasion.Occasion) error {
+	if _, err := s.db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM occasion_wishlists WHERE occasion_id = ?`, o.ID); err != nil {
+		return err
+	}
+	for _, wishlistID := range o.WishlistIDs {
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`INSERT INTO occasion_wishlists (occasion_id, wishlist_id) VALUES (?, ?)`,
+			o.ID, wishlistID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *OccasionStore) Delete(ctx context.Context, id int64) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`DELETE FROM occasion_wishlists WHERE occasion_id = ?`, id); err != nil {
+			return err
+		}
+
+		result, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM occasions WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return occasion.ErrNotFound(id)
+		}
+
+		return nil
+	})
+}
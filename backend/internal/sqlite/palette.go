@@ -0,0 +1,132 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/stadtaev/lofam/backend/internal/palette"
+)
+
+type PaletteStore struct {
+	db *DB
+}
+
+func NewPaletteStore(db *DB) *PaletteStore {
+	return &PaletteStore{db: db}
+}
+
+const paletteColumns = `id, name, hex, dark_hex, position, created_at, updated_at`
+
+func scanPaletteColor(row interface{ Scan(...any) error }, c *palette.Color) error {
+	return row.Scan(&c.ID, &c.Name, &c.Hex, &c.DarkHex, &c.Position, &c.CreatedAt, &c.UpdatedAt)
+}
+
+func (s *PaletteStore) Create(ctx context.Context, c *palette.Color) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO palette_colors (name, hex, dark_hex, position, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, c.Name, c.Hex, c.DarkHex, c.Position, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	c.ID = id
+	return nil
+}
+
+func (s *PaletteStore) GetByID(ctx context.Context, id int64) (*palette.Color, error) {
+	var c palette.Color
+	err := scanPaletteColor(s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT `+paletteColumns+` FROM palette_colors WHERE id = ?`, id), &c)
+	if err == sql.ErrNoRows {
+		return nil, palette.ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *PaletteStore) GetByName(ctx context.Context, name string) (*palette.Color, error) {
+	var c palette.Color
+	err := scanPaletteColor(s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT `+paletteColumns+` FROM palette_colors WHERE name = ?`, name), &c)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *PaletteStore) List(ctx context.Context) ([]palette.Color, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx,
+		`SELECT `+paletteColumns+` FROM palette_colors ORDER BY position, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	colors := []palette.Color{}
+	for rows.Next() {
+		var c palette.Color
+		if err := scanPaletteColor(rows, &c); err != nil {
+			return nil, err
+		}
+		colors = append(colors, c)
+	}
+
+	return colors, rows.Err()
+}
+
+func (s *PaletteStore) Update(ctx context.Context, c *palette.Color) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE palette_colors SET hex = ?, dark_hex = ?, position = ?, updated_at = ?
+		WHERE id = ?
+	`, c.Hex, c.DarkHex, c.Position, c.UpdatedAt, c.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return palette.ErrNotFound(c.ID)
+	}
+
+	return nil
+}
+
+func (s *PaletteStore) Delete(ctx context.Context, id int64) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM palette_colors WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return palette.ErrNotFound(id)
+	}
+
+	return nil
+}
+
+func (s *PaletteStore) Usage(ctx context.Context, name string) (int, error) {
+	var count int
+	err := s.db.conn(ctx).QueryRowContext(ctx, `
+		SELECT (SELECT COUNT(*) FROM notes WHERE color = ?)
+			+ (SELECT COUNT(*) FROM wishlists WHERE color = ?)
+	`, name, name).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/stadtaev/lofam/backend/internal/pantry"
+)
+
+type PantryStore struct {
+	db *DB
+}
+
+func NewPantryStore(db *DB) *PantryStore {
+	return &PantryStore{db: db}
+}
+
+const pantryItemColumns = `id, name, quantity, unit, location, expires_on, shopping_item_id,
+	created_at, updated_at`
+
+func scanPantryItem(row interface{ Scan(...any) error }, item *pantry.Item) error {
+	return row.Scan(&item.ID, &item.Name, &item.Quantity, &item.Unit, &item.Location,
+		&item.ExpiresOn, &item.ShoppingItemID, &item.CreatedAt, &item.UpdatedAt)
+}
+
+func (s *PantryStore) Create(ctx context.Context, item *pantry.Item) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO pantry_items (name, quantity, unit, location, expires_on, shopping_item_id,
+			created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, item.Name, item.Quantity, item.Unit, item.Location, item.ExpiresOn, item.ShoppingItemID,
+		item.CreatedAt, item.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	item.ID = id
+	return nil
+}
+
+func (s *PantryStore) GetByID(ctx context.Context, id int64) (*pantry.Item, error) {
+	var item pantry.Item
+	err := scanPantryItem(s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT `+pantryItemColumns+` FROM pantry_items WHERE id = ?`, id), &item)
+	if err == sql.ErrNoRows {
+		return nil, pantry.ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (s *PantryStore) List(ctx context.Context, filter pantry.Filter) ([]pantry.Item, error) {
+	return s.query(ctx, `
+		SELECT `+pantryItemColumns+` FROM pantry_items
+		WHERE (? = '' OR location = ?)
+		ORDER BY expires_on IS NULL, expires_on, name COLLATE NOCASE
+	`, filter.Location, filter.Location)
+}
+
+func (s *PantryStore) ExpiringBefore(ctx context.Context, date string) ([]pantry.Item, error) {
+	return s.query(ctx, `
+		SELECT `+pantryItemColumns+` FROM pantry_items
+		WHERE expires_on IS NOT NULL AND expires_on <= ?
+		ORDER BY expires_on, name COLLATE NOCASE
+	`, date)
+}
+
+func (s *PantryStore) query(ctx context.Context, query string, args ...any) ([]pantry.Item, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []pantry.Item{}
+	for rows.Next() {
+		var item pantry.Item
+		if err := scanPantryItem(rows, &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (s *PantryStore) Update(ctx context.Context, item *pantry.Item) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE pantry_items
+		SET name = ?, quantity = ?, unit = ?, location = ?, expires_on = ?, updated_at = ?
+		WHERE id = ?
+	`, item.Name, item.Quantity, item.Unit, item.Location, item.ExpiresOn, item.UpdatedAt, item.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return pantry.ErrNotFound(item.ID)
+	}
+
+	return nil
+}
+
+func (s *PantryStore) Delete(ctx context.Context, id int64) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM pantry_items WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return pantry.ErrNotFound(id)
+	}
+
+	return nil
+}
+
+func (s *PantryStore) DeleteByShoppingItem(ctx context.Context, shoppingItemID int64) error {
+	_, err := s.db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM pantry_items WHERE shopping_item_id = ?`, shoppingItemID)
+	return err
+}
@@ -0,0 +1,267 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/stadtaev/lofam/backend/internal/project"
+	"github.com/stadtaev/lofam/backend/internal/task"
+)
+
+type ProjectStore struct {
+	db *DB
+}
+
+func NewProjectStore(db *DB) *ProjectStore {
+	return &ProjectStore{db: db}
+}
+
+func (s *ProjectStore) Create(ctx context.Context, p *project.Project) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := s.db.conn(ctx).ExecContext(ctx, `
+			INSERT INTO projects (name, created_at, updated_at) VALUES (?, ?, ?)
+		`, p.Name, p.CreatedAt, p.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		p.ID = id
+
+		return s.saveColumns(ctx, p)
+	})
+}
+
+func (s *ProjectStore) GetByID(ctx context.Context, id int64) (*project.Project, error) {
+	var p project.Project
+	err := s.db.conn(ctx).QueryRowContext(ctx, `
+		SELECT id, name, created_at, updated_at FROM projects WHERE id = ?
+	`, id).Scan(&p.ID, &p.Name, &p.CreatedAt, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, project.ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	projects := []project.Project{p}
+	if err := s.loadColumns(ctx, projects); err != nil {
+		return nil, err
+	}
+	return &projects[0], nil
+}
+
+func (s *ProjectStore) List(ctx context.Context) ([]project.Project, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT id, name, created_at, updated_at FROM projects ORDER BY name COLLATE NOCASE
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []project.Project{}
+	for rows.Next() {
+		var p project.Project
+		if err := rows.Scan(&p.ID, &p.Name, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if len(projects) == 0 {
+		return projects, nil
+	}
+	return projects, s.loadColumns(ctx, projects)
+}
+
+func (s *ProjectStore) Update(ctx context.Context, p *project.Project) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := s.db.conn(ctx).ExecContext(ctx, `
+			UPDATE projects SET name = ?, updated_at = ? WHERE id = ?
+		`, p.Name, p.UpdatedAt, p.ID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return project.ErrNotFound(p.ID)
+		}
+
+		return s.saveColumns(ctx, p)
+	})
+}
+
+func (s *ProjectStore) Delete(ctx context.Context, id int64) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM projects WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return project.ErrNotFound(id)
+		}
+
+		result, err = s.db.conn(ctx).ExecContext(ctx,
+			`UPDATE tasks SET project_id = NULL, column_id = NULL WHERE project_id = ?`, id)
+		if err != nil {
+			return err
+		}
+		if _, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM project_columns WHERE project_id = ?`, id); err != nil {
+			return err
+		}
+
+		// The tasks join their status lanes, where their old ranks may tie.
+		if moved, err := result.RowsAffected(); err != nil || moved == 0 {
+			return err
+		}
+		return s.db.rebalanceTasks(ctx, "column_id IS NULL")
+	})
+}
+
+// saveColumns writes the project's columns in order: columns with an ID
+// are updated, the others inserted, and any not listed deleted. Tasks in
+// deleted columns move to the first column of their status, where the
+// board's ranks are spread out again, and tasks whose column changed
+// category take on the new status.
+func (s *ProjectStore) saveColumns(ctx context.Context, p *project.Project) error {
+	keep := []int64{}
+	for _, c := range p.Columns {
+		if c.ID != 0 {
+			keep = append(keep, c.ID)
+		}
+	}
+	query := `DELETE FROM project_columns WHERE project_id = ?`
+	args := []any{p.ID}
+	if len(keep) > 0 {
+		placeholders, keepArgs := inClause(keep)
+		query += ` AND id NOT IN (` + placeholders + `)`
+		args = append(args, keepArgs...)
+	}
+	if _, err := s.db.conn(ctx).ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	for i := range p.Columns {
+		c := &p.Columns[i]
+		if c.ID != 0 {
+			if _, err := s.db.conn(ctx).ExecContext(ctx, `
+				UPDATE project_columns SET name = ?, category = ?, position = ?
+				WHERE id = ? AND project_id = ?
+			`, c.Name, c.Category, i, c.ID, p.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, err := s.db.conn(ctx).ExecContext(ctx, `
+			INSERT INTO project_columns (project_id, name, category, position) VALUES (?, ?, ?, ?)
+		`, p.ID, c.Name, c.Category, i)
+		if err != nil {
+			return err
+		}
+		if c.ID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+	}
+
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE tasks SET column_id = (
+			SELECT c.id FROM project_columns c
+			WHERE c.project_id = tasks.project_id AND c.category = tasks.status
+			ORDER BY c.position LIMIT 1)
+		WHERE project_id = ? AND (column_id IS NULL
+			OR column_id NOT IN (SELECT id FROM project_columns WHERE project_id = ?))
+	`, p.ID, p.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE tasks SET status = (SELECT category FROM project_columns WHERE id = tasks.column_id)
+		WHERE project_id = ?
+	`, p.ID); err != nil {
+		return err
+	}
+
+	if moved, err := result.RowsAffected(); err != nil || moved == 0 {
+		return err
+	}
+	return s.db.rebalanceTasks(ctx,
+		"column_id IN (SELECT id FROM project_columns WHERE project_id = ?)", p.ID)
+}
+
+func (s *ProjectStore) BlockedTasks(ctx context.Context, columnID int64) ([]int64, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT DISTINCT t.id FROM tasks t
+		JOIN task_dependencies d ON d.task_id = t.id
+		JOIN tasks b ON b.id = d.blocker_id
+		WHERE t.column_id = ? AND b.status != ?
+		ORDER BY t.id
+	`, columnID, task.StatusDone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// loadColumns fills in the columns of the given projects.
+func (s *ProjectStore) loadColumns(ctx context.Context, projects []project.Project) error {
+	byID := make(map[int64]*project.Project, len(projects))
+	ids := make([]int64, len(projects))
+	for i := range projects {
+		projects[i].Columns = []project.Column{}
+		byID[projects[i].ID] = &projects[i]
+		ids[i] = projects[i].ID
+	}
+	placeholders, args := inClause(ids)
+
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT project_id, id, name, category FROM project_columns
+		WHERE project_id IN (`+placeholders+`)
+		ORDER BY project_id, position
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			projectID int64
+			c         project.Column
+		)
+		if err := rows.Scan(&projectID, &c.ID, &c.Name, &c.Category); err != nil {
+			return err
+		}
+		if p, ok := byID[projectID]; ok {
+			p.Columns = append(p.Columns, c)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,228 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/stadtaev/lofam/backend/internal/recipe"
+)
+
+type RecipeStore struct {
+	db *DB
+}
+
+func NewRecipeStore(db *DB) *RecipeStore {
+	return &RecipeStore{db: db}
+}
+
+func (s *RecipeStore) Create(ctx context.Context, r *recipe.Recipe) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := s.db.conn(ctx).ExecContext(ctx, `
+			INSERT INTO recipes (name, description, servings, source_url, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, r.Name, r.Description, r.Servings, r.SourceURL, r.CreatedAt, r.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		r.ID = id
+
+		return s.insertParts(ctx, r)
+	})
+}
+
+func (s *RecipeStore) GetByID(ctx context.Context, id int64) (*recipe.Recipe, error) {
+	var r recipe.Recipe
+	err := s.db.conn(ctx).QueryRowContext(ctx, `
+		SELECT id, name, description, servings, source_url, created_at, updated_at
+		FROM recipes WHERE id = ?
+	`, id).Scan(&r.ID, &r.Name, &r.Description, &r.Servings, &r.SourceURL, &r.CreatedAt, &r.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, recipe.ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	recipes := []recipe.Recipe{r}
+	if err := s.loadParts(ctx, recipes); err != nil {
+		return nil, err
+	}
+	return &recipes[0], nil
+}
+
+func (s *RecipeStore) List(ctx context.Context) ([]recipe.Recipe, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT id, name, description, servings, source_url, created_at, updated_at
+		FROM recipes ORDER BY name COLLATE NOCASE
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipes []recipe.Recipe
+	for rows.Next() {
+		var r recipe.Recipe
+		if err := rows.Scan(&r.ID, &r.Name, &r.Description, &r.Servings, &r.SourceURL,
+			&r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if recipes == nil {
+		return []recipe.Recipe{}, nil
+	}
+
+	return recipes, s.loadParts(ctx, recipes)
+}
+
+func (s *RecipeStore) Update(ctx context.Context, r *recipe.Recipe) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := s.db.conn(ctx).ExecContext(ctx, `
+			UPDATE recipes SET name = ?, description = ?, servings = ?, source_url = ?, updated_at = ?
+			WHERE id = ?
+		`, r.Name, r.Description, r.Servings, r.SourceURL, r.UpdatedAt, r.ID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return recipe.ErrNotFound(r.ID)
+		}
+
+		if err := s.deleteParts(ctx, r.ID); err != nil {
+			return err
+		}
+		return s.insertParts(ctx, r)
+	})
+}
+
+func (s *RecipeStore) Delete(ctx context.Context, id int64) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM recipes WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return recipe.ErrNotFound(id)
+		}
+
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`DELETE FROM meal_plan_entries WHERE recipe_id = ?`, id); err != nil {
+			return err
+		}
+		return s.deleteParts(ctx, id)
+	})
+}
+
+func (s *RecipeStore) insertParts(ctx context.Context, r *recipe.Recipe) error {
+	for i, ing := range r.Ingredients {
+		if _, err := s.db.conn(ctx).ExecContext(ctx, `
+			INSERT INTO recipe_ingredients (recipe_id, position, quantity, unit, name, note)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, r.ID, i, ing.Quantity, ing.Unit, ing.Name, ing.Note); err != nil {
+			return err
+		}
+	}
+
+	for i, step := range r.Steps {
+		if _, err := s.db.conn(ctx).ExecContext(ctx, `
+			INSERT INTO recipe_steps (recipe_id, position, text) VALUES (?, ?, ?)
+		`, r.ID, i, step); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *RecipeStore) deleteParts(ctx context.Context, recipeID int64) error {
+	if _, err := s.db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM recipe_ingredients WHERE recipe_id = ?`, recipeID); err != nil {
+		return err
+	}
+	_, err := s.db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM recipe_steps WHERE recipe_id = ?`, recipeID)
+	return err
+}
+
+// loadParts fills in the ingredients and steps of the given recipes.
+func (s *RecipeStore) loadParts(ctx context.Context, recipes []recipe.Recipe) error {
+	byID := make(map[int64]*recipe.Recipe, len(recipes))
+	ids := make([]int64, len(recipes))
+	for i := range recipes {
+		recipes[i].Ingredients = []recipe.Ingredient{}
+		recipes[i].Steps = []string{}
+		byID[recipes[i].ID] = &recipes[i]
+		ids[i] = recipes[i].ID
+	}
+	placeholders, args := inClause(ids)
+
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT recipe_id, quantity, unit, name, note
+		FROM recipe_ingredients WHERE recipe_id IN (`+placeholders+`)
+		ORDER BY recipe_id, position
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			recipeID int64
+			ing      recipe.Ingredient
+		)
+		if err := rows.Scan(&recipeID, &ing.Quantity, &ing.Unit, &ing.Name, &ing.Note); err != nil {
+			return err
+		}
+		if r, ok := byID[recipeID]; ok {
+			r.Ingredients = append(r.Ingredients, ing)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	stepRows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT recipe_id, text FROM recipe_steps WHERE recipe_id IN (`+placeholders+`)
+		ORDER BY recipe_id, position
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer stepRows.Close()
+
+	for stepRows.Next() {
+		var (
+			recipeID int64
+			text     string
+		)
+		if err := stepRows.Scan(&recipeID, &text); err != nil {
+			return err
+		}
+		if r, ok := byID[recipeID]; ok {
+			r.Steps = append(r.Steps, text)
+		}
+	}
+
+	return stepRows.Err()
+}
@@ -2,8 +2,12 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"strings"
 
 	"github.com/stadtaev/lofam/backend/internal/shopping"
+	"github.com/stadtaev/lofam/backend/internal/tag"
 )
 
 type ShoppingStore struct {
@@ -14,11 +18,18 @@ func NewShoppingStore(db *DB) *ShoppingStore {
 	return &ShoppingStore{db: db}
 }
 
+const shoppingItemColumns = `id, list_id, title, quantity, unit, note, category, checked, checked_at, created_at`
+
+func scanShoppingItem(row interface{ Scan(...any) error }, item *shopping.Item) error {
+	return row.Scan(&item.ID, &item.ListID, &item.Title, &item.Quantity, &item.Unit, &item.Note,
+		&item.Category, &item.Checked, &item.CheckedAt, &item.CreatedAt)
+}
+
 func (s *ShoppingStore) Create(ctx context.Context, item *shopping.Item) error {
-	result, err := s.db.ExecContext(ctx, `
-		INSERT INTO shopping_items (title, created_at)
-		VALUES (?, ?)
-	`, item.Title, item.CreatedAt)
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO shopping_items (list_id, title, quantity, unit, note, category, checked, checked_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, item.ListID, item.Title, item.Quantity, item.Unit, item.Note, item.Category, item.Checked, item.CheckedAt, item.CreatedAt)
 	if err != nil {
 		return err
 	}
@@ -32,11 +43,35 @@ func (s *ShoppingStore) Create(ctx context.Context, item *shopping.Item) error {
 	return nil
 }
 
-func (s *ShoppingStore) List(ctx context.Context) ([]shopping.Item, error) {
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, title, created_at
-		FROM shopping_items ORDER BY created_at DESC
-	`)
+func (s *ShoppingStore) GetByID(ctx context.Context, id int64) (*shopping.Item, error) {
+	var item shopping.Item
+	err := scanShoppingItem(s.db.conn(ctx).QueryRowContext(ctx, `
+		SELECT `+shoppingItemColumns+`
+		FROM shopping_items WHERE id = ?
+	`, id), &item)
+	if err == sql.ErrNoRows {
+		return nil, shopping.ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.db.tagNames(ctx, tag.KindShoppingItem, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	item.Tags = tags[id]
+
+	return &item, nil
+}
+
+func (s *ShoppingStore) List(ctx context.Context, listID int64, filter shopping.Filter) ([]shopping.Item, error) {
+	tagged, tagArgs := taggedWith(tag.KindShoppingItem, "id", filter.Tag)
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT `+shoppingItemColumns+`
+		FROM shopping_items WHERE list_id = ? AND `+tagged+`
+		ORDER BY checked, created_at DESC
+	`, append([]any{listID}, tagArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -45,21 +80,212 @@ func (s *ShoppingStore) List(ctx context.Context) ([]shopping.Item, error) {
 	var items []shopping.Item
 	for rows.Next() {
 		var item shopping.Item
-		if err := rows.Scan(&item.ID, &item.Title, &item.CreatedAt); err != nil {
+		if err := scanShoppingItem(rows, &item); err != nil {
 			return nil, err
 		}
 		items = append(items, item)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
 	if items == nil {
 		items = []shopping.Item{}
 	}
 
-	return items, rows.Err()
+	ids := make([]int64, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	tags, err := s.db.tagNames(ctx, tag.KindShoppingItem, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Tags = tags[items[i].ID]
+	}
+
+	return items, nil
+}
+
+func (s *ShoppingStore) Update(ctx context.Context, item *shopping.Item) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE shopping_items
+		SET list_id = ?, title = ?, quantity = ?, unit = ?, note = ?, category = ?, checked = ?, checked_at = ?
+		WHERE id = ?
+	`, item.ListID, item.Title, item.Quantity, item.Unit, item.Note, item.Category, item.Checked, item.CheckedAt, item.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return shopping.ErrNotFound(item.ID)
+	}
+
+	return nil
 }
 
 func (s *ShoppingStore) Delete(ctx context.Context, id int64) error {
-	result, err := s.db.ExecContext(ctx, `DELETE FROM shopping_items WHERE id = ?`, id)
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.db.untag(ctx, tag.KindShoppingItem, "?", id); err != nil {
+			return err
+		}
+
+		result, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM shopping_items WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return shopping.ErrNotFound(id)
+		}
+
+		return nil
+	})
+}
+
+func (s *ShoppingStore) DeleteChecked(ctx context.Context, listID int64) (int64, error) {
+	var deleted int64
+	err := s.db.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.db.untag(ctx, tag.KindShoppingItem,
+			`SELECT id FROM shopping_items WHERE list_id = ? AND checked = 1`, listID); err != nil {
+			return err
+		}
+
+		result, err := s.db.conn(ctx).ExecContext(ctx,
+			`DELETE FROM shopping_items WHERE list_id = ? AND checked = 1`, listID)
+		if err != nil {
+			return err
+		}
+		deleted, err = result.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
+
+const shoppingListColumns = `id, name, icon, sort_order, is_default, aisle_order,
+	(SELECT COUNT(*) FROM shopping_items WHERE list_id = shopping_lists.id AND checked = 0),
+	created_at, updated_at`
+
+func scanShoppingList(row interface{ Scan(...any) error }, l *shopping.List) error {
+	var aisleOrder string
+	if err := row.Scan(&l.ID, &l.Name, &l.Icon, &l.SortOrder, &l.IsDefault, &aisleOrder,
+		&l.PendingCount, &l.CreatedAt, &l.UpdatedAt); err != nil {
+		return err
+	}
+	l.AisleOrder = decodeAisleOrder(aisleOrder)
+	return nil
+}
+
+// Aisle orders are stored as a comma-separated list of categories.
+func encodeAisleOrder(order []shopping.Category) string {
+	parts := make([]string, len(order))
+	for i, c := range order {
+		parts[i] = string(c)
+	}
+	return strings.Join(parts, ",")
+}
+
+func decodeAisleOrder(s string) []shopping.Category {
+	order := []shopping.Category{}
+	if s == "" {
+		return order
+	}
+	for _, part := range strings.Split(s, ",") {
+		order = append(order, shopping.Category(part))
+	}
+	return order
+}
+
+func (s *ShoppingStore) CreateList(ctx context.Context, l *shopping.List) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO shopping_lists (name, icon, sort_order, aisle_order, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, l.Name, l.Icon, l.SortOrder, encodeAisleOrder(l.AisleOrder), l.CreatedAt, l.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	l.ID = id
+	return nil
+}
+
+func (s *ShoppingStore) GetList(ctx context.Context, id int64) (*shopping.List, error) {
+	var l shopping.List
+	err := scanShoppingList(s.db.conn(ctx).QueryRowContext(ctx, `
+		SELECT `+shoppingListColumns+`
+		FROM shopping_lists WHERE id = ?
+	`, id), &l)
+	if err == sql.ErrNoRows {
+		return nil, shopping.ErrListNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (s *ShoppingStore) GetDefaultList(ctx context.Context) (*shopping.List, error) {
+	var l shopping.List
+	err := scanShoppingList(s.db.conn(ctx).QueryRowContext(ctx, `
+		SELECT `+shoppingListColumns+`
+		FROM shopping_lists WHERE is_default = 1
+	`), &l)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("default shopping list is missing")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (s *ShoppingStore) Lists(ctx context.Context) ([]shopping.List, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT `+shoppingListColumns+`
+		FROM shopping_lists ORDER BY sort_order, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lists []shopping.List
+	for rows.Next() {
+		var l shopping.List
+		if err := scanShoppingList(rows, &l); err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+
+	if lists == nil {
+		lists = []shopping.List{}
+	}
+
+	return lists, rows.Err()
+}
+
+func (s *ShoppingStore) UpdateList(ctx context.Context, l *shopping.List) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE shopping_lists SET name = ?, icon = ?, sort_order = ?, aisle_order = ?, updated_at = ?
+		WHERE id = ?
+	`, l.Name, l.Icon, l.SortOrder, encodeAisleOrder(l.AisleOrder), l.UpdatedAt, l.ID)
 	if err != nil {
 		return err
 	}
@@ -69,8 +295,186 @@ func (s *ShoppingStore) Delete(ctx context.Context, id int64) error {
 		return err
 	}
 	if rows == 0 {
-		return shopping.ErrNotFound(id)
+		return shopping.ErrListNotFound(l.ID)
 	}
 
 	return nil
 }
+
+func (s *ShoppingStore) DeleteList(ctx context.Context, id int64) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.db.untag(ctx, tag.KindShoppingItem,
+			`SELECT id FROM shopping_items WHERE list_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`DELETE FROM shopping_items WHERE list_id = ?`, id); err != nil {
+			return err
+		}
+
+		result, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM shopping_lists WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return shopping.ErrListNotFound(id)
+		}
+
+		return nil
+	})
+}
+
+func (s *ShoppingStore) LearnedCategory(ctx context.Context, term string) (shopping.Category, error) {
+	var c shopping.Category
+	err := s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT category FROM shopping_learned_categories WHERE term = ?`, term,
+	).Scan(&c)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return c, err
+}
+
+func (s *ShoppingStore) LearnCategory(ctx context.Context, term string, c shopping.Category) error {
+	_, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO shopping_learned_categories (term, category, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(term) DO UPDATE SET category = excluded.category, updated_at = excluded.updated_at
+	`, term, c)
+	return err
+}
+
+func (s *ShoppingStore) RecordPurchase(ctx context.Context, p *shopping.Purchase) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO shopping_purchases (item_id, list_id, term, title, category, quantity, unit, purchased_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, p.ItemID, p.ListID, shopping.NormalizeTitle(p.Title), p.Title, p.Category, p.Quantity, p.Unit, p.PurchasedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	p.ID = id
+	return nil
+}
+
+func (s *ShoppingStore) DeletePurchase(ctx context.Context, itemID int64) error {
+	_, err := s.db.conn(ctx).ExecContext(ctx, `
+		DELETE FROM shopping_purchases WHERE id = (
+			SELECT id FROM shopping_purchases WHERE item_id = ?
+			ORDER BY purchased_at DESC LIMIT 1
+		)
+	`, itemID)
+	return err
+}
+
+func (s *ShoppingStore) Purchases(ctx context.Context, filter shopping.PurchaseFilter) ([]shopping.Purchase, error) {
+	query := `
+		SELECT id, item_id, list_id, title, category, quantity, unit, purchased_at
+		FROM shopping_purchases WHERE purchased_at >= ?`
+	args := []any{filter.Since}
+
+	if filter.Prefix != "" {
+		pattern := escapeLike(filter.Prefix) + "%"
+		query += ` AND (term LIKE ? ESCAPE '\' OR term LIKE ? ESCAPE '\')`
+		args = append(args, pattern, "% "+pattern)
+	}
+
+	rows, err := s.db.conn(ctx).QueryContext(ctx, query+` ORDER BY purchased_at`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var purchases []shopping.Purchase
+	for rows.Next() {
+		var p shopping.Purchase
+		if err := rows.Scan(&p.ID, &p.ItemID, &p.ListID, &p.Title, &p.Category,
+			&p.Quantity, &p.Unit, &p.PurchasedAt); err != nil {
+			return nil, err
+		}
+		purchases = append(purchases, p)
+	}
+
+	return purchases, rows.Err()
+}
+
+func (s *ShoppingStore) PendingTitles(ctx context.Context) ([]string, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx,
+		`SELECT title FROM shopping_items WHERE checked = 0`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var titles []string
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, err
+		}
+		titles = append(titles, title)
+	}
+
+	return titles, rows.Err()
+}
+
+// escapeLike escapes the LIKE wildcards in s for use with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// categorizeShoppingItems runs the dictionary over items filed under other,
+// leaving alone any title the household filed by hand.
+func (db *DB) categorizeShoppingItems(ctx context.Context) error {
+	return db.WithinTx(ctx, func(ctx context.Context) error {
+		rows, err := db.conn(ctx).QueryContext(ctx,
+			`SELECT id, title FROM shopping_items WHERE category = ?`, shopping.CategoryOther)
+		if err != nil {
+			return err
+		}
+		titles := make(map[int64]string)
+		for rows.Next() {
+			var id int64
+			var title string
+			if err := rows.Scan(&id, &title); err != nil {
+				rows.Close()
+				return err
+			}
+			titles[id] = title
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		store := NewShoppingStore(db)
+		for id, title := range titles {
+			c := shopping.Categorize(title)
+			if c == shopping.CategoryOther {
+				continue
+			}
+			learned, err := store.LearnedCategory(ctx, shopping.NormalizeTitle(title))
+			if err != nil {
+				return err
+			}
+			if learned != "" {
+				continue
+			}
+			if _, err := db.conn(ctx).ExecContext(ctx,
+				`UPDATE shopping_items SET category = ? WHERE id = ?`, c, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,288 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	"github.com/stadtaev/lofam/backend/internal/tag"
+)
+
+type TagStore struct {
+	db *DB
+}
+
+func NewTagStore(db *DB) *TagStore {
+	return &TagStore{db: db}
+}
+
+const tagColumns = `id, name, color, created_at, updated_at`
+
+func scanTag(row interface{ Scan(...any) error }, t *tag.Tag) error {
+	return row.Scan(&t.ID, &t.Name, &t.Color, &t.CreatedAt, &t.UpdatedAt)
+}
+
+func (s *TagStore) Create(ctx context.Context, t *tag.Tag) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO tags (name, color, created_at, updated_at) VALUES (?, ?, ?, ?)
+	`, t.Name, t.Color, t.CreatedAt, t.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	t.ID = id
+	return nil
+}
+
+func (s *TagStore) GetByID(ctx context.Context, id int64) (*tag.Tag, error) {
+	var t tag.Tag
+	err := scanTag(s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT `+tagColumns+` FROM tags WHERE id = ?`, id), &t)
+	if err == sql.ErrNoRows {
+		return nil, tag.ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []tag.Tag{t}
+	if err := s.loadCounts(ctx, tags); err != nil {
+		return nil, err
+	}
+	return &tags[0], nil
+}
+
+func (s *TagStore) FindByName(ctx context.Context, name string) (*tag.Tag, error) {
+	var t tag.Tag
+	err := scanTag(s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT `+tagColumns+` FROM tags WHERE name = ?`, name), &t)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *TagStore) List(ctx context.Context) ([]tag.Tag, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx,
+		`SELECT `+tagColumns+` FROM tags ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []tag.Tag{}
+	for rows.Next() {
+		var t tag.Tag
+		if err := scanTag(rows, &t); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := s.loadCounts(ctx, tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (s *TagStore) loadCounts(ctx context.Context, tags []tag.Tag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*tag.Tag, len(tags))
+	ids := make([]int64, len(tags))
+	for i := range tags {
+		tags[i].Counts = make(map[tag.Kind]int, len(tag.Kinds))
+		for _, k := range tag.Kinds {
+			tags[i].Counts[k] = 0
+		}
+		byID[tags[i].ID] = &tags[i]
+		ids[i] = tags[i].ID
+	}
+	placeholders, args := inClause(ids)
+
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT tag_id, entity_type, COUNT(*) FROM taggings
+		WHERE tag_id IN (`+placeholders+`)
+		GROUP BY tag_id, entity_type
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tagID int64
+		var kind tag.Kind
+		var count int
+		if err := rows.Scan(&tagID, &kind, &count); err != nil {
+			return err
+		}
+		t := byID[tagID]
+		t.Counts[kind] = count
+		t.Total += count
+	}
+
+	return rows.Err()
+}
+
+func (s *TagStore) Update(ctx context.Context, t *tag.Tag) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE tags SET name = ?, color = ?, updated_at = ? WHERE id = ?
+	`, t.Name, t.Color, t.UpdatedAt, t.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return tag.ErrNotFound(t.ID)
+	}
+
+	return nil
+}
+
+func (s *TagStore) Delete(ctx context.Context, id int64) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`DELETE FROM taggings WHERE tag_id = ?`, id); err != nil {
+			return err
+		}
+
+		result, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM tags WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return tag.ErrNotFound(id)
+		}
+
+		return nil
+	})
+}
+
+func (s *TagStore) Merge(ctx context.Context, fromID, intoID int64) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.db.conn(ctx).ExecContext(ctx, `
+			INSERT OR IGNORE INTO taggings (tag_id, entity_type, entity_id)
+			SELECT ?, entity_type, entity_id FROM taggings WHERE tag_id = ?
+		`, intoID, fromID); err != nil {
+			return err
+		}
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`DELETE FROM taggings WHERE tag_id = ?`, fromID); err != nil {
+			return err
+		}
+		_, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM tags WHERE id = ?`, fromID)
+		return err
+	})
+}
+
+func (s *TagStore) SetTags(ctx context.Context, kind tag.Kind, entityID int64, names []string) ([]string, error) {
+	err := s.db.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`DELETE FROM taggings WHERE entity_type = ? AND entity_id = ?`, kind, entityID); err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if _, err := s.db.conn(ctx).ExecContext(ctx,
+				`INSERT OR IGNORE INTO tags (name, created_at, updated_at)
+				 VALUES (?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, name); err != nil {
+				return err
+			}
+			if _, err := s.db.conn(ctx).ExecContext(ctx, `
+				INSERT INTO taggings (tag_id, entity_type, entity_id)
+				SELECT id, ?, ? FROM tags WHERE name = ?
+			`, kind, entityID, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.db.tagNames(ctx, kind, []int64{entityID})
+	if err != nil {
+		return nil, err
+	}
+	return tags[entityID], nil
+}
+
+// tagNames returns the sorted tag names of the given entities. Every id has
+// an entry, empty when the entity has no tags.
+func (db *DB) tagNames(ctx context.Context, kind tag.Kind, ids []int64) (map[int64][]string, error) {
+	names := make(map[int64][]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+	for _, id := range ids {
+		names[id] = []string{}
+	}
+	placeholders, args := inClause(ids)
+
+	rows, err := db.conn(ctx).QueryContext(ctx, `
+		SELECT tg.entity_id, t.name FROM taggings tg
+		JOIN tags t ON t.id = tg.tag_id
+		WHERE tg.entity_type = ? AND tg.entity_id IN (`+placeholders+`)
+	`, append([]any{kind}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		names[id] = append(names[id], name)
+	}
+	for _, n := range names {
+		sort.Slice(n, func(i, j int) bool { return strings.ToLower(n[i]) < strings.ToLower(n[j]) })
+	}
+
+	return names, rows.Err()
+}
+
+// untag removes the tags of the entities selected by idQuery, which must
+// return entity ids. Stores call it before deleting entities.
+func (db *DB) untag(ctx context.Context, kind tag.Kind, idQuery string, args ...any) error {
+	_, err := db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM taggings WHERE entity_type = ? AND entity_id IN (`+idQuery+`)`,
+		append([]any{kind}, args...)...)
+	return err
+}
+
+// taggedWith returns a WHERE condition matching rows whose column is the id
+// of a kind entity tagged name, or every row when name is empty.
+func taggedWith(kind tag.Kind, column, name string) (string, []any) {
+	return `(? = '' OR ` + column + ` IN (
+		SELECT tg.entity_id FROM taggings tg JOIN tags t ON t.id = tg.tag_id
+		WHERE tg.entity_type = ? AND t.name = ?))`, []any{name, kind, name}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/stadtaev/lofam/backend/internal/rank"
+	"github.com/stadtaev/lofam/backend/internal/tag"
 	"github.com/stadtaev/lofam/backend/internal/task"
 )
 
@@ -15,11 +17,25 @@ func NewTaskStore(db *DB) *TaskStore {
 	return &TaskStore{db: db}
 }
 
+// taskColumns are the columns read by scanTask, ending with the number of
+// comments on the task.
+const taskColumns = `id, title, description, status, priority, due_date, start_at, end_at, all_day,
+	assignee_id, project_id, column_id, rank, created_at,
+	(SELECT COUNT(*) FROM task_comments WHERE task_id = tasks.id)`
+
+func scanTask(row interface{ Scan(...any) error }, t *task.Task) error {
+	return row.Scan(&t.ID, &t.Title, &t.Description, &t.Status, &t.Priority, &t.DueDate,
+		&t.StartAt, &t.EndAt, &t.AllDay, &t.AssigneeID, &t.ProjectID, &t.ColumnID, &t.Rank,
+		&t.CreatedAt, &t.CommentCount)
+}
+
 func (s *TaskStore) Create(ctx context.Context, t *task.Task) error {
-	result, err := s.db.ExecContext(ctx,
-		`INSERT INTO tasks (title, description, status, priority, due_date)
-		 VALUES (?, ?, ?, ?, ?)`,
-		t.Title, t.Description, t.Status, t.Priority, t.DueDate,
+	result, err := s.db.conn(ctx).ExecContext(ctx,
+		`INSERT INTO tasks (title, description, status, priority, due_date, start_at, end_at, all_day,
+		 assignee_id, project_id, column_id, rank)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.Title, t.Description, t.Status, t.Priority, t.DueDate, t.StartAt, t.EndAt, t.AllDay,
+		t.AssigneeID, t.ProjectID, t.ColumnID, t.Rank,
 	)
 	if err != nil {
 		return err
@@ -31,18 +47,16 @@ func (s *TaskStore) Create(ctx context.Context, t *task.Task) error {
 	}
 	t.ID = id
 
-	return s.db.QueryRowContext(ctx,
+	return s.db.conn(ctx).QueryRowContext(ctx,
 		"SELECT created_at FROM tasks WHERE id = ?", id,
 	).Scan(&t.CreatedAt)
 }
 
 func (s *TaskStore) GetByID(ctx context.Context, id int64) (*task.Task, error) {
 	var t task.Task
-	err := s.db.QueryRowContext(ctx,
-		`SELECT id, title, description, status, priority, due_date, created_at
-		 FROM tasks WHERE id = ?`, id,
-	).Scan(&t.ID, &t.Title, &t.Description, &t.Status, &t.Priority,
-		&t.DueDate, &t.CreatedAt)
+	err := scanTask(s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id,
+	), &t)
 
 	if err == sql.ErrNoRows {
 		return nil, task.ErrNotFound(id)
@@ -51,14 +65,43 @@ func (s *TaskStore) GetByID(ctx context.Context, id int64) (*task.Task, error) {
 		return nil, err
 	}
 
+	tags, err := s.db.tagNames(ctx, tag.KindTask, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	t.Tags = tags[id]
+
+	blockedBy, blocks, err := s.dependencies(ctx, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	t.BlockedBy, t.Blocks = blockedBy[id], blocks[id]
+
 	return &t, nil
 }
 
-func (s *TaskStore) List(ctx context.Context) ([]task.Task, error) {
-	rows, err := s.db.QueryContext(ctx,
-		`SELECT id, title, description, status, priority, due_date, created_at
-		 FROM tasks ORDER BY created_at DESC`,
-	)
+func (s *TaskStore) List(ctx context.Context, filter task.Filter) ([]task.Task, error) {
+	tagged, args := taggedWith(tag.KindTask, "id", filter.Tag)
+	query := `SELECT ` + taskColumns + ` FROM tasks
+		WHERE ` + tagged + ` AND (? = 0 OR project_id = ?) AND (? = 0 OR assignee_id = ?)`
+	args = append(args, filter.ProjectID, filter.ProjectID, filter.AssigneeID, filter.AssigneeID)
+
+	// A task overlaps [From, To) if it starts before To and ends after
+	// From; a task without an end must start at or after From.
+	order := ` ORDER BY rank, id`
+	if filter.To != nil {
+		query += ` AND start_at < ?`
+		args = append(args, filter.To.UTC())
+	}
+	if filter.From != nil {
+		query += ` AND (end_at > ? OR (end_at IS NULL AND start_at >= ?))`
+		args = append(args, filter.From.UTC(), filter.From.UTC())
+	}
+	if filter.Ranged() {
+		order = ` ORDER BY start_at, rank, id`
+	}
+
+	rows, err := s.db.conn(ctx).QueryContext(ctx, query+order, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -67,21 +110,44 @@ func (s *TaskStore) List(ctx context.Context) ([]task.Task, error) {
 	var tasks []task.Task
 	for rows.Next() {
 		var t task.Task
-		if err := rows.Scan(&t.ID, &t.Title, &t.Description, &t.Status, &t.Priority,
-			&t.DueDate, &t.CreatedAt); err != nil {
+		if err := scanTask(rows, &t); err != nil {
 			return nil, err
 		}
 		tasks = append(tasks, t)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
 
-	return tasks, rows.Err()
+	ids := make([]int64, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+	tags, err := s.db.tagNames(ctx, tag.KindTask, ids)
+	if err != nil {
+		return nil, err
+	}
+	blockedBy, blocks, err := s.dependencies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		tasks[i].Tags = tags[tasks[i].ID]
+		tasks[i].BlockedBy = blockedBy[tasks[i].ID]
+		tasks[i].Blocks = blocks[tasks[i].ID]
+	}
+
+	return tasks, nil
 }
 
 func (s *TaskStore) Update(ctx context.Context, t *task.Task) error {
-	result, err := s.db.ExecContext(ctx,
-		`UPDATE tasks SET title = ?, description = ?, status = ?, priority = ?, due_date = ?
+	result, err := s.db.conn(ctx).ExecContext(ctx,
+		`UPDATE tasks SET title = ?, description = ?, status = ?, priority = ?, due_date = ?,
+		 start_at = ?, end_at = ?, all_day = ?, assignee_id = ?, project_id = ?, column_id = ?, rank = ?
 		 WHERE id = ?`,
-		t.Title, t.Description, t.Status, t.Priority, t.DueDate, t.ID,
+		t.Title, t.Description, t.Status, t.Priority, t.DueDate, t.StartAt, t.EndAt, t.AllDay,
+		t.AssigneeID, t.ProjectID, t.ColumnID, t.Rank, t.ID,
 	)
 	if err != nil {
 		return err
@@ -99,18 +165,168 @@ func (s *TaskStore) Update(ctx context.Context, t *task.Task) error {
 }
 
 func (s *TaskStore) Delete(ctx context.Context, id int64) error {
-	result, err := s.db.ExecContext(ctx, "DELETE FROM tasks WHERE id = ?", id)
-	if err != nil {
-		return err
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.db.untag(ctx, tag.KindTask, "?", id); err != nil {
+			return err
+		}
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			"DELETE FROM task_dependencies WHERE task_id = ? OR blocker_id = ?", id, id); err != nil {
+			return err
+		}
+		if _, err := s.db.deleteComments(ctx, "task_id = ?", id); err != nil {
+			return err
+		}
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			"DELETE FROM notifications WHERE task_id = ?", id); err != nil {
+			return err
+		}
+
+		result, err := s.db.conn(ctx).ExecContext(ctx, "DELETE FROM tasks WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return task.ErrNotFound(id)
+		}
+
+		return nil
+	})
+}
+
+func (s *TaskStore) PrevRank(ctx context.Context, lane task.Lane, r string, excludeID int64) (string, error) {
+	where, args := laneClause(lane)
+	var prev string
+	err := s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(rank), '') FROM tasks WHERE `+where+` AND rank < ? AND id != ?`,
+		append(args, r, excludeID)...,
+	).Scan(&prev)
+	return prev, err
+}
+
+func (s *TaskStore) NextRank(ctx context.Context, lane task.Lane, r string, excludeID int64) (string, error) {
+	where, args := laneClause(lane)
+	var next string
+	err := s.db.conn(ctx).QueryRowContext(ctx,
+		`SELECT COALESCE(MIN(rank), '') FROM tasks WHERE `+where+` AND rank > ? AND id != ?`,
+		append(args, r, excludeID)...,
+	).Scan(&next)
+	return next, err
+}
+
+func (s *TaskStore) Rebalance(ctx context.Context, lane task.Lane) error {
+	where, args := laneClause(lane)
+	return s.db.rebalanceTasks(ctx, where, args...)
+}
+
+// laneClause returns the condition selecting the tasks of a lane.
+func laneClause(lane task.Lane) (string, []any) {
+	if lane.ColumnID != nil {
+		return "column_id = ?", []any{*lane.ColumnID}
 	}
+	return "column_id IS NULL AND status = ?", []any{lane.Status}
+}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return err
+// rebalanceTasks hands out evenly spread ranks, in the current order, to
+// the tasks matching where. Tasks without a rank, from before ranks
+// existed, come first, newest first.
+func (db *DB) rebalanceTasks(ctx context.Context, where string, args ...any) error {
+	return db.WithinTx(ctx, func(ctx context.Context) error {
+		rows, err := db.conn(ctx).QueryContext(ctx,
+			`SELECT id FROM tasks WHERE `+where+` ORDER BY rank, created_at DESC, id DESC`, args...)
+		if err != nil {
+			return err
+		}
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for i, r := range rank.Spread(len(ids)) {
+			if _, err := db.conn(ctx).ExecContext(ctx,
+				`UPDATE tasks SET rank = ? WHERE id = ?`, r, ids[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *TaskStore) AddBlocker(ctx context.Context, id, blockerID int64) error {
+	_, err := s.db.conn(ctx).ExecContext(ctx,
+		`INSERT OR IGNORE INTO task_dependencies (task_id, blocker_id) VALUES (?, ?)`,
+		id, blockerID,
+	)
+	return err
+}
+
+func (s *TaskStore) RemoveBlocker(ctx context.Context, id, blockerID int64) error {
+	_, err := s.db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM task_dependencies WHERE task_id = ? AND blocker_id = ?`,
+		id, blockerID,
+	)
+	return err
+}
+
+// dependencies loads, for each of the tasks, the tasks blocking it and the
+// tasks it blocks.
+func (s *TaskStore) dependencies(ctx context.Context, ids []int64) (blockedBy, blocks map[int64][]task.Ref, err error) {
+	blockedBy = make(map[int64][]task.Ref, len(ids))
+	blocks = make(map[int64][]task.Ref, len(ids))
+	for _, id := range ids {
+		blockedBy[id] = []task.Ref{}
+		blocks[id] = []task.Ref{}
 	}
-	if rows == 0 {
-		return task.ErrNotFound(id)
+	if len(ids) == 0 {
+		return blockedBy, blocks, nil
 	}
+	placeholders, args := inClause(ids)
 
-	return nil
+	// Each query pairs a task with the task on the other end of the edge.
+	for _, q := range []struct {
+		from, to string
+		refs     map[int64][]task.Ref
+	}{
+		{"task_id", "blocker_id", blockedBy},
+		{"blocker_id", "task_id", blocks},
+	} {
+		rows, err := s.db.conn(ctx).QueryContext(ctx, `
+			SELECT d.`+q.from+`, t.id, t.title, t.status FROM task_dependencies d
+			JOIN tasks t ON t.id = d.`+q.to+`
+			WHERE d.`+q.from+` IN (`+placeholders+`)
+			ORDER BY t.id
+		`, args...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for rows.Next() {
+			var id int64
+			var ref task.Ref
+			if err := rows.Scan(&id, &ref.ID, &ref.Title, &ref.Status); err != nil {
+				rows.Close()
+				return nil, nil, err
+			}
+			q.refs[id] = append(q.refs[id], ref)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return blockedBy, blocks, nil
 }
@@ -3,7 +3,10 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"github.com/stadtaev/lofam/backend/internal/linkmeta"
+	"github.com/stadtaev/lofam/backend/internal/tag"
 	"github.com/stadtaev/lofam/backend/internal/wishlist"
 )
 
@@ -16,10 +19,10 @@ func NewWishlistStore(db *DB) *WishlistStore {
 }
 
 func (s *WishlistStore) Create(ctx context.Context, w *wishlist.Wishlist) error {
-	result, err := s.db.ExecContext(ctx, `
-		INSERT INTO wishlists (title, content, color, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?)
-	`, w.Title, w.Content, w.Color, w.CreatedAt, w.UpdatedAt)
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO wishlists (title, content, color, owner_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, w.Title, w.Content, w.Color, w.OwnerID, w.CreatedAt, w.UpdatedAt)
 	if err != nil {
 		return err
 	}
@@ -35,24 +38,32 @@ func (s *WishlistStore) Create(ctx context.Context, w *wishlist.Wishlist) error
 
 func (s *WishlistStore) GetByID(ctx context.Context, id int64) (*wishlist.Wishlist, error) {
 	var w wishlist.Wishlist
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, title, content, color, created_at, updated_at
+	err := s.db.conn(ctx).QueryRowContext(ctx, `
+		SELECT id, title, content, color, owner_id, created_at, updated_at
 		FROM wishlists WHERE id = ?
-	`, id).Scan(&w.ID, &w.Title, &w.Content, &w.Color, &w.CreatedAt, &w.UpdatedAt)
+	`, id).Scan(&w.ID, &w.Title, &w.Content, &w.Color, &w.OwnerID, &w.CreatedAt, &w.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, wishlist.ErrNotFound(id)
 	}
 	if err != nil {
 		return nil, err
 	}
+
+	tags, err := s.db.tagNames(ctx, tag.KindWishlist, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	w.Tags = tags[id]
+
 	return &w, nil
 }
 
-func (s *WishlistStore) List(ctx context.Context) ([]wishlist.Wishlist, error) {
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, title, content, color, created_at, updated_at
-		FROM wishlists ORDER BY created_at DESC
-	`)
+func (s *WishlistStore) List(ctx context.Context, filter wishlist.Filter) ([]wishlist.Wishlist, error) {
+	tagged, args := taggedWith(tag.KindWishlist, "id", filter.Tag)
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT id, title, content, color, owner_id, created_at, updated_at
+		FROM wishlists WHERE `+tagged+` ORDER BY created_at DESC
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -61,24 +72,42 @@ func (s *WishlistStore) List(ctx context.Context) ([]wishlist.Wishlist, error) {
 	var items []wishlist.Wishlist
 	for rows.Next() {
 		var w wishlist.Wishlist
-		if err := rows.Scan(&w.ID, &w.Title, &w.Content, &w.Color, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		if err := rows.Scan(&w.ID, &w.Title, &w.Content, &w.Color, &w.OwnerID,
+			&w.CreatedAt, &w.UpdatedAt); err != nil {
 			return nil, err
 		}
 		items = append(items, w)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
 	if items == nil {
 		items = []wishlist.Wishlist{}
 	}
 
-	return items, rows.Err()
+	ids := make([]int64, len(items))
+	for i, w := range items {
+		ids[i] = w.ID
+	}
+	tags, err := s.db.tagNames(ctx, tag.KindWishlist, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Tags = tags[items[i].ID]
+	}
+
+	return items, nil
 }
 
 func (s *WishlistStore) Update(ctx context.Context, w *wishlist.Wishlist) error {
-	result, err := s.db.ExecContext(ctx, `
-		UPDATE wishlists SET title = ?, content = ?, color = ?, updated_at = ?
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE wishlists SET title = ?, content = ?, color = ?, owner_id = ?, updated_at = ?
 		WHERE id = ?
-	`, w.Title, w.Content, w.Color, w.UpdatedAt, w.ID)
+	`, w.Title, w.Content, w.Color, w.OwnerID, w.UpdatedAt, w.ID)
 	if err != nil {
 		return err
 	}
@@ -95,7 +124,284 @@ func (s *WishlistStore) Update(ctx context.Context, w *wishlist.Wishlist) error
 }
 
 func (s *WishlistStore) Delete(ctx context.Context, id int64) error {
-	result, err := s.db.ExecContext(ctx, `DELETE FROM wishlists WHERE id = ?`, id)
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`DELETE FROM wishlist_items WHERE wishlist_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`DELETE FROM wishlist_shares WHERE wishlist_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := s.db.conn(ctx).ExecContext(ctx,
+			`DELETE FROM occasion_wishlists WHERE wishlist_id = ?`, id); err != nil {
+			return err
+		}
+		if err := s.db.untag(ctx, tag.KindWishlist, "?", id); err != nil {
+			return err
+		}
+
+		result, err := s.db.conn(ctx).ExecContext(ctx, `DELETE FROM wishlists WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return wishlist.ErrNotFound(id)
+		}
+
+		return nil
+	})
+}
+
+const wishlistItemColumns = `id, wishlist_id, name, url, price_cents, currency, quantity, priority,
+	image_url, reserved_by, reserved_guest, reservation_status, reserved_at, created_at, updated_at`
+
+func scanWishlistItem(row interface{ Scan(...any) error }, item *wishlist.Item) error {
+	var (
+		reservedBy    *int64
+		reservedGuest string
+		status        wishlist.ReservationStatus
+		reservedAt    *time.Time
+	)
+	if err := row.Scan(&item.ID, &item.WishlistID, &item.Name, &item.URL, &item.PriceCents,
+		&item.Currency, &item.Quantity, &item.Priority, &item.ImageURL,
+		&reservedBy, &reservedGuest, &status, &reservedAt, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		return err
+	}
+
+	item.Reservation = nil
+	if reservedAt != nil {
+		item.Reservation = &wishlist.Reservation{
+			MemberID:   reservedBy,
+			GuestName:  reservedGuest,
+			Status:     status,
+			ReservedAt: *reservedAt,
+		}
+	}
+	return nil
+}
+
+func (s *WishlistStore) CreateItem(ctx context.Context, item *wishlist.Item) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO wishlist_items (wishlist_id, name, url, price_cents, currency, quantity,
+			priority, image_url, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, item.WishlistID, item.Name, item.URL, item.PriceCents, item.Currency, item.Quantity,
+		item.Priority, item.ImageURL, item.CreatedAt, item.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	item.ID = id
+	return nil
+}
+
+func (s *WishlistStore) GetItem(ctx context.Context, wishlistID, id int64) (*wishlist.Item, error) {
+	var item wishlist.Item
+	err := scanWishlistItem(s.db.conn(ctx).QueryRowContext(ctx, `
+		SELECT `+wishlistItemColumns+` FROM wishlist_items WHERE id = ? AND wishlist_id = ?
+	`, id, wishlistID), &item)
+	if err == sql.ErrNoRows {
+		return nil, wishlist.ErrItemNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (s *WishlistStore) ListItems(ctx context.Context, wishlistIDs []int64) ([]wishlist.Item, error) {
+	items := []wishlist.Item{}
+	if len(wishlistIDs) == 0 {
+		return items, nil
+	}
+	placeholders, args := inClause(wishlistIDs)
+
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT `+wishlistItemColumns+` FROM wishlist_items
+		WHERE wishlist_id IN (`+placeholders+`)
+		ORDER BY wishlist_id,
+			CASE priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END,
+			created_at, id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item wishlist.Item
+		if err := scanWishlistItem(rows, &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (s *WishlistStore) UpdateItem(ctx context.Context, item *wishlist.Item) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE wishlist_items
+		SET name = ?, url = ?, price_cents = ?, currency = ?, quantity = ?, priority = ?,
+			image_url = ?, updated_at = ?
+		WHERE id = ? AND wishlist_id = ?
+	`, item.Name, item.URL, item.PriceCents, item.Currency, item.Quantity, item.Priority,
+		item.ImageURL, item.UpdatedAt, item.ID, item.WishlistID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return wishlist.ErrItemNotFound(item.ID)
+	}
+
+	return nil
+}
+
+func (s *WishlistStore) FillItemDetails(ctx context.Context, wishlistID, id int64, link string, meta linkmeta.Metadata, updatedAt time.Time) error {
+	// SET expressions all see the row as it was, so the currency is only
+	// taken along with a price that fills an empty one.
+	_, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE wishlist_items
+		SET name = CASE WHEN ? != '' AND (name = '' OR name = url) THEN ? ELSE name END,
+			image_url = CASE WHEN image_url = '' THEN ? ELSE image_url END,
+			currency = CASE WHEN price_cents IS NULL AND ? IS NOT NULL AND currency = '' THEN ? ELSE currency END,
+			price_cents = COALESCE(price_cents, ?),
+			updated_at = ?
+		WHERE id = ? AND wishlist_id = ? AND url = ?
+			AND ((? != '' AND (name = '' OR name = url))
+				OR (? != '' AND image_url = '')
+				OR (? IS NOT NULL AND price_cents IS NULL))
+	`, meta.Title, meta.Title, meta.ImageURL, meta.PriceCents, meta.Currency, meta.PriceCents, updatedAt,
+		id, wishlistID, link, meta.Title, meta.ImageURL, meta.PriceCents)
+	return err
+}
+
+func (s *WishlistStore) DeleteItem(ctx context.Context, wishlistID, id int64) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM wishlist_items WHERE id = ? AND wishlist_id = ?`, id, wishlistID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return wishlist.ErrItemNotFound(id)
+	}
+
+	return nil
+}
+
+func (s *WishlistStore) ReserveItem(ctx context.Context, item *wishlist.Item) error {
+	r := item.Reservation
+	// A NULL member never equals reserved_by, so guests only get items that
+	// are still free.
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE wishlist_items
+		SET reserved_by = ?, reserved_guest = ?, reservation_status = ?, reserved_at = ?
+		WHERE id = ? AND wishlist_id = ? AND (reserved_at IS NULL OR reserved_by = ?)
+	`, r.MemberID, r.GuestName, r.Status, r.ReservedAt, item.ID, item.WishlistID, r.MemberID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return wishlist.ErrAlreadyReserved(item.ID)
+	}
+
+	return nil
+}
+
+func (s *WishlistStore) UnreserveItem(ctx context.Context, wishlistID, id int64) error {
+	_, err := s.db.conn(ctx).ExecContext(ctx, `
+		UPDATE wishlist_items
+		SET reserved_by = NULL, reserved_guest = '', reservation_status = '', reserved_at = NULL
+		WHERE id = ? AND wishlist_id = ?
+	`, id, wishlistID)
+	return err
+}
+
+func (s *WishlistStore) CreateShare(ctx context.Context, share *wishlist.Share) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx, `
+		INSERT INTO wishlist_shares (wishlist_id, token, expires_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`, share.WishlistID, share.Token, share.ExpiresAt, share.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	share.ID = id
+	return nil
+}
+
+func (s *WishlistStore) GetShareByToken(ctx context.Context, token string) (*wishlist.Share, error) {
+	var share wishlist.Share
+	err := s.db.conn(ctx).QueryRowContext(ctx, `
+		SELECT id, wishlist_id, token, expires_at, created_at
+		FROM wishlist_shares WHERE token = ?
+	`, token).Scan(&share.ID, &share.WishlistID, &share.Token, &share.ExpiresAt, &share.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, wishlist.ErrShareNotFound()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+func (s *WishlistStore) ListShares(ctx context.Context, wishlistID int64) ([]wishlist.Share, error) {
+	rows, err := s.db.conn(ctx).QueryContext(ctx, `
+		SELECT id, wishlist_id, token, expires_at, created_at
+		FROM wishlist_shares WHERE wishlist_id = ? ORDER BY created_at DESC, id DESC
+	`, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := []wishlist.Share{}
+	for rows.Next() {
+		var share wishlist.Share
+		if err := rows.Scan(&share.ID, &share.WishlistID, &share.Token, &share.ExpiresAt,
+			&share.CreatedAt); err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+
+	return shares, rows.Err()
+}
+
+func (s *WishlistStore) DeleteShare(ctx context.Context, wishlistID, id int64) error {
+	result, err := s.db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM wishlist_shares WHERE id = ? AND wishlist_id = ?`, id, wishlistID)
 	if err != nil {
 		return err
 	}
@@ -105,7 +411,7 @@ func (s *WishlistStore) Delete(ctx context.Context, id int64) error {
 		return err
 	}
 	if rows == 0 {
-		return wishlist.ErrNotFound(id)
+		return wishlist.ErrShareNotFound()
 	}
 
 	return nil
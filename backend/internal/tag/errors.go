@@ -0,0 +1,41 @@
+package tag
+
+import "fmt"
+
+type ValidationError struct {
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Message
+}
+
+func ErrValidation(msg string) ValidationError {
+	return ValidationError{Message: msg}
+}
+
+type NotFoundError struct {
+	ID int64
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("tag with id %d not found", e.ID)
+}
+
+func ErrNotFound(id int64) NotFoundError {
+	return NotFoundError{ID: id}
+}
+
+// ConflictError is returned when renaming or creating a tag would clash
+// with an existing one; the two can be merged instead.
+type ConflictError struct {
+	Name string
+}
+
+func (e ConflictError) Error() string {
+	return fmt.Sprintf("a tag named %q already exists", e.Name)
+}
+
+func ErrConflict(name string) ConflictError {
+	return ConflictError{Name: name}
+}
@@ -0,0 +1,135 @@
+package tag
+
+import (
+	"context"
+	"time"
+)
+
+type Service struct {
+	store Store
+}
+
+func NewService(store Store) *Service {
+	return &Service{store: store}
+}
+
+func (s *Service) Create(ctx context.Context, req CreateRequest) (*Tag, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNameFree(ctx, req.Name, 0); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	t := &Tag{
+		Name:      req.Name,
+		Color:     req.Color,
+		Counts:    emptyCounts(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.store.Create(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int64) (*Tag, error) {
+	return s.store.GetByID(ctx, id)
+}
+
+// List returns all tags by name, with the number of entities using them.
+func (s *Service) List(ctx context.Context) ([]Tag, error) {
+	return s.store.List(ctx)
+}
+
+func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Tag, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	t, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if err := s.checkNameFree(ctx, *req.Name, id); err != nil {
+			return nil, err
+		}
+		t.Name = *req.Name
+	}
+	if req.Color != nil {
+		t.Color = *req.Color
+	}
+	t.UpdatedAt = time.Now()
+
+	if err := s.store.Update(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	return s.store.Delete(ctx, id)
+}
+
+// Merge folds tag id into req.IntoID: everything tagged with the first is
+// tagged with the second, and the first is deleted. It returns the merged
+// tag.
+func (s *Service) Merge(ctx context.Context, id int64, req MergeRequest) (*Tag, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.IntoID == id {
+		return nil, ErrValidation("a tag cannot be merged into itself")
+	}
+
+	if _, err := s.store.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.GetByID(ctx, req.IntoID); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Merge(ctx, id, req.IntoID); err != nil {
+		return nil, err
+	}
+
+	return s.store.GetByID(ctx, req.IntoID)
+}
+
+// SetTags replaces the tags of an entity, which the caller must have
+// checked exists.
+func (s *Service) SetTags(ctx context.Context, kind Kind, entityID int64, req SetRequest) ([]string, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return s.store.SetTags(ctx, kind, entityID, req.Tags)
+}
+
+// checkNameFree fails with ConflictError when another tag than id already
+// uses name.
+func (s *Service) checkNameFree(ctx context.Context, name string, id int64) error {
+	existing, err := s.store.FindByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != id {
+		return ErrConflict(existing.Name)
+	}
+	return nil
+}
+
+func emptyCounts() map[Kind]int {
+	counts := make(map[Kind]int, len(Kinds))
+	for _, k := range Kinds {
+		counts[k] = 0
+	}
+	return counts
+}
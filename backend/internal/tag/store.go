@@ -0,0 +1,19 @@
+package tag
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, t *Tag) error
+	GetByID(ctx context.Context, id int64) (*Tag, error)
+	// FindByName returns the tag with the given name, ignoring case, or nil
+	// when there is none.
+	FindByName(ctx context.Context, name string) (*Tag, error)
+	List(ctx context.Context) ([]Tag, error)
+	Update(ctx context.Context, t *Tag) error
+	Delete(ctx context.Context, id int64) error
+	// Merge moves the links of tag fromID to intoID and deletes fromID.
+	Merge(ctx context.Context, fromID, intoID int64) error
+	// SetTags replaces the tags of an entity, creating tags for unknown
+	// names, and returns the resulting tag names.
+	SetTags(ctx context.Context, kind Kind, entityID int64, names []string) ([]string, error)
+}
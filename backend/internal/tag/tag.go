@@ -0,0 +1,135 @@
+package tag
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Kind is a type of entity that can be tagged.
+type Kind string
+
+const (
+	KindTask         Kind = "task"
+	KindNote         Kind = "note"
+	KindWishlist     Kind = "wishlist"
+	KindShoppingItem Kind = "shopping_item"
+)
+
+// Kinds lists every taggable entity type.
+var Kinds = []Kind{KindTask, KindNote, KindWishlist, KindShoppingItem}
+
+const maxNameLength = 50
+
+// Tag is a label such as "school" or "car" that groups entities across
+// domains. Names are unique regardless of case.
+type Tag struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	// Counts holds the number of tagged entities per kind; Total is their
+	// sum.
+	Counts    map[Kind]int `json:"counts"`
+	Total     int          `json:"total"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+type CreateRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// Validate also normalises the name.
+func (r *CreateRequest) Validate() error {
+	r.Name = normalizeName(r.Name)
+	if err := validateName(r.Name); err != nil {
+		return err
+	}
+	return validateColor(r.Color)
+}
+
+// UpdateRequest renames or recolours a tag; omitted fields are unchanged.
+type UpdateRequest struct {
+	Name  *string `json:"name,omitempty"`
+	Color *string `json:"color,omitempty"`
+}
+
+// Validate also normalises the name.
+func (r *UpdateRequest) Validate() error {
+	if r.Name != nil {
+		name := normalizeName(*r.Name)
+		r.Name = &name
+		if err := validateName(name); err != nil {
+			return err
+		}
+	}
+	if r.Color != nil {
+		return validateColor(*r.Color)
+	}
+	return nil
+}
+
+// MergeRequest names the tag that takes over the links of the merged one.
+type MergeRequest struct {
+	IntoID int64 `json:"intoId"`
+}
+
+func (r MergeRequest) Validate() error {
+	if r.IntoID == 0 {
+		return ErrValidation("intoId is required")
+	}
+	return nil
+}
+
+// SetRequest replaces the tags of an entity. Unknown names create new tags.
+type SetRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// Validate normalises the names and drops duplicates.
+func (r *SetRequest) Validate() error {
+	seen := make(map[string]bool, len(r.Tags))
+	names := make([]string, 0, len(r.Tags))
+	for _, name := range r.Tags {
+		name = normalizeName(name)
+		if err := validateName(name); err != nil {
+			return err
+		}
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	})
+	r.Tags = names
+	return nil
+}
+
+// normalizeName trims a name and collapses inner whitespace.
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+func validateName(name string) error {
+	if name == "" {
+		return ErrValidation("name is required")
+	}
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return ErrValidation("name must be at most 50 characters")
+	}
+	return nil
+}
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func validateColor(c string) error {
+	if c != "" && !colorPattern.MatchString(c) {
+		return ErrValidation("color must be a hex colour such as #ff8800")
+	}
+	return nil
+}
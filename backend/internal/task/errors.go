@@ -1,6 +1,10 @@
 package task
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 type ValidationError struct {
 	Message string
@@ -25,3 +29,22 @@ func (e NotFoundError) Error() string {
 func ErrNotFound(id int64) NotFoundError {
 	return NotFoundError{ID: id}
 }
+
+// BlockedError is returned when a task is started or finished while tasks
+// blocking it are still open.
+type BlockedError struct {
+	ID       int64
+	Blockers []int64
+}
+
+func (e BlockedError) Error() string {
+	ids := make([]string, len(e.Blockers))
+	for i, id := range e.Blockers {
+		ids[i] = strconv.FormatInt(id, 10)
+	}
+	return fmt.Sprintf("task %d is blocked by open tasks %s", e.ID, strings.Join(ids, ", "))
+}
+
+func ErrBlocked(id int64, blockers []int64) BlockedError {
+	return BlockedError{ID: id, Blockers: blockers}
+}
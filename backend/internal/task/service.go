@@ -1,13 +1,36 @@
 package task
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/member"
+	"github.com/stadtaev/lofam/backend/internal/rank"
+)
+
+// Boards knows the columns of projects, which tasks are placed in.
+type Boards interface {
+	// ColumnStatus returns the status of tasks in a column of the project.
+	ColumnStatus(ctx context.Context, projectID, columnID int64) (Status, error)
+	// ColumnFor returns the project's first column for a status.
+	ColumnFor(ctx context.Context, projectID int64, status Status) (int64, error)
+}
+
+// Members looks up the members tasks are assigned to.
+type Members interface {
+	GetByID(ctx context.Context, id int64) (*member.Member, error)
+}
 
 type Service struct {
-	store Store
+	store   Store
+	boards  Boards
+	members Members
 }
 
-func NewService(store Store) *Service {
-	return &Service{store: store}
+func NewService(store Store, boards Boards, members Members) *Service {
+	return &Service{store: store, boards: boards, members: members}
 }
 
 func (s *Service) Create(ctx context.Context, req CreateRequest) (*Task, error) {
@@ -20,13 +43,38 @@ func (s *Service) Create(ctx context.Context, req CreateRequest) (*Task, error)
 		priority = PriorityMedium
 	}
 
+	start, end, err := schedule(req.StartAt, req.EndAt, req.DurationMinutes, req.AllDay)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAssignee(ctx, req.AssigneeID); err != nil {
+		return nil, err
+	}
+
 	t := &Task{
 		Title:       req.Title,
 		Description: req.Description,
 		Status:      StatusTodo,
 		Priority:    priority,
 		DueDate:     req.DueDate,
+		StartAt:     start,
+		EndAt:       end,
+		AllDay:      req.AllDay,
+		AssigneeID:  req.AssigneeID,
+		ProjectID:   req.ProjectID,
+		Tags:        []string{},
+		BlockedBy:   []Ref{},
+		Blocks:      []Ref{},
+	}
+	if err := s.place(ctx, t, req.ColumnID); err != nil {
+		return nil, err
 	}
+	// New tasks go to the top.
+	r, err := s.newRank(ctx, t, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	t.Rank = r
 
 	if err := s.store.Create(ctx, t); err != nil {
 		return nil, err
@@ -39,8 +87,8 @@ func (s *Service) GetByID(ctx context.Context, id int64) (*Task, error) {
 	return s.store.GetByID(ctx, id)
 }
 
-func (s *Service) List(ctx context.Context) ([]Task, error) {
-	tasks, err := s.store.List(ctx)
+func (s *Service) List(ctx context.Context, filter Filter) ([]Task, error) {
+	tasks, err := s.store.List(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -50,6 +98,27 @@ func (s *Service) List(ctx context.Context) ([]Task, error) {
 	return tasks, nil
 }
 
+// Due returns the unfinished tasks that are due before the given time,
+// earliest first.
+func (s *Service) Due(ctx context.Context, before time.Time) ([]Task, error) {
+	tasks, err := s.store.List(ctx, Filter{})
+	if err != nil {
+		return nil, err
+	}
+
+	due := []Task{}
+	for _, t := range tasks {
+		if t.Status != StatusDone && t.DueDate != nil && t.DueDate.Before(before) {
+			due = append(due, t)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].DueDate.Before(*due[j].DueDate)
+	})
+
+	return due, nil
+}
+
 func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Task, error) {
 	if err := req.Validate(); err != nil {
 		return nil, err
@@ -66,15 +135,60 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Tas
 	if req.Description != nil {
 		t.Description = *req.Description
 	}
-	if req.Status != nil {
-		t.Status = *req.Status
-	}
 	if req.Priority != nil {
 		t.Priority = *req.Priority
 	}
 	if req.DueDate != nil {
 		t.DueDate = req.DueDate
 	}
+	if err := s.reschedule(t, req); err != nil {
+		return nil, err
+	}
+	if req.AssigneeID != nil {
+		t.AssigneeID = req.AssigneeID
+		if *req.AssigneeID == 0 {
+			t.AssigneeID = nil
+		}
+		if err := s.checkAssignee(ctx, t.AssigneeID); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.ProjectID != nil {
+		if t.ProjectID == nil || *t.ProjectID != *req.ProjectID {
+			t.ColumnID = nil
+		}
+		t.ProjectID = req.ProjectID
+		if *req.ProjectID == 0 {
+			t.ProjectID = nil
+		}
+	}
+	if req.ColumnID != nil && t.ProjectID == nil {
+		return nil, ErrValidation("columnId requires the task to be in a project")
+	}
+
+	previous, lane := t.Status, t.Lane()
+	if req.Status != nil {
+		t.Status = *req.Status
+	}
+	if err := s.place(ctx, t, req.ColumnID); err != nil {
+		return nil, err
+	}
+	if req.Status != nil && *req.Status != t.Status {
+		return nil, ErrValidation("status does not match the column's category")
+	}
+	if t.Status != previous && t.Status != StatusTodo && !req.Force {
+		if open := openBlockers(t); len(open) > 0 {
+			return nil, ErrBlocked(id, open)
+		}
+	}
+	// A task joining another lane goes to its top, since its old rank
+	// may tie with one there.
+	if !lane.contains(t) {
+		if t.Rank, err = s.newRank(ctx, t, nil, nil); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := s.store.Update(ctx, t); err != nil {
 		return nil, err
@@ -83,6 +197,308 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Tas
 	return t, nil
 }
 
+// reschedule applies the time fields of an update. Moving the start of a
+// task keeps its length unless the end changes as well.
+func (s *Service) reschedule(t *Task, req UpdateRequest) error {
+	if req.Unschedule {
+		t.StartAt, t.EndAt, t.AllDay = nil, nil, false
+		return nil
+	}
+	if req.StartAt == nil && req.EndAt == nil && req.DurationMinutes == nil && req.AllDay == nil {
+		return nil
+	}
+
+	start, end, allDay := t.StartAt, t.EndAt, t.AllDay
+	if req.AllDay != nil {
+		allDay = *req.AllDay
+	}
+	if req.StartAt != nil {
+		if start != nil && end != nil {
+			moved := req.StartAt.Add(end.Sub(*start))
+			end = &moved
+		}
+		start = req.StartAt
+	}
+	if req.EndAt != nil {
+		end = req.EndAt
+	}
+	duration := 0
+	if req.DurationMinutes != nil {
+		end, duration = nil, *req.DurationMinutes
+	}
+
+	start, end, err := schedule(start, end, duration, allDay)
+	if err != nil {
+		return err
+	}
+	t.StartAt, t.EndAt, t.AllDay = start, end, allDay
+	return nil
+}
+
+func (s *Service) checkAssignee(ctx context.Context, memberID *int64) error {
+	if memberID == nil {
+		return nil
+	}
+	_, err := s.members.GetByID(ctx, *memberID)
+	return err
+}
+
+// Conflicts finds the tasks matching the filter that are assigned to the
+// same member at overlapping times. Timed tasks only clash with timed
+// tasks and all-day tasks with all-day tasks, so an appointment during a
+// day off is not a conflict; tasks without an end never clash.
+func (s *Service) Conflicts(ctx context.Context, filter Filter) ([]Conflict, error) {
+	tasks, err := s.store.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	byMember := map[int64][]Task{}
+	var members []int64
+	for _, t := range tasks {
+		if t.AssigneeID == nil || t.StartAt == nil || t.EndAt == nil {
+			continue
+		}
+		if _, ok := byMember[*t.AssigneeID]; !ok {
+			members = append(members, *t.AssigneeID)
+		}
+		byMember[*t.AssigneeID] = append(byMember[*t.AssigneeID], t)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i] < members[j] })
+
+	conflicts := []Conflict{}
+	for _, memberID := range members {
+		scheduled := byMember[memberID]
+		sort.SliceStable(scheduled, func(i, j int) bool {
+			return scheduled[i].StartAt.Before(*scheduled[j].StartAt)
+		})
+
+		for i, a := range scheduled {
+			for _, b := range scheduled[i+1:] {
+				if !b.StartAt.Before(*a.EndAt) {
+					break
+				}
+				if a.AllDay != b.AllDay {
+					continue
+				}
+				end := *a.EndAt
+				if b.EndAt.Before(end) {
+					end = *b.EndAt
+				}
+				conflicts = append(conflicts, Conflict{
+					MemberID: memberID,
+					Tasks:    [2]Ref{ref(a), ref(b)},
+					Start:    *b.StartAt,
+					End:      end,
+				})
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+func ref(t Task) Ref {
+	return Ref{ID: t.ID, Title: t.Title, Status: t.Status}
+}
+
+// Move reorders a task among its neighbours, moving it to another column
+// or status first if asked to.
+func (s *Service) Move(ctx context.Context, id int64, req MoveRequest) (*Task, error) {
+	if (req.AfterID != nil && *req.AfterID == id) || (req.BeforeID != nil && *req.BeforeID == id) {
+		return nil, ErrValidation("a task cannot be moved next to itself")
+	}
+
+	t, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.ColumnID != nil || req.Status != nil {
+		t, err = s.Update(ctx, id, UpdateRequest{Status: req.Status, ColumnID: req.ColumnID, Force: req.Force})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if t.Rank, err = s.newRank(ctx, t, req.AfterID, req.BeforeID); err != nil {
+		return nil, err
+	}
+	if err := s.store.Update(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// newRank returns a rank for t between the given neighbours in its lane.
+// When ranks there have grown too long, the lane's ranks are spread out
+// first.
+func (s *Service) newRank(ctx context.Context, t *Task, afterID, beforeID *int64) (string, error) {
+	lower, upper, err := s.rankBounds(ctx, t, afterID, beforeID)
+	if err != nil {
+		return "", err
+	}
+	if r := rank.Between(lower, upper); len(r) <= rank.MaxLength {
+		return r, nil
+	}
+
+	if err := s.store.Rebalance(ctx, t.Lane()); err != nil {
+		return "", err
+	}
+	if lower, upper, err = s.rankBounds(ctx, t, afterID, beforeID); err != nil {
+		return "", err
+	}
+	return rank.Between(lower, upper), nil
+}
+
+// rankBounds returns the ranks t must fall between to be placed between
+// the neighbours, which must share its lane. A missing neighbour is taken
+// to be whichever task is adjacent to the other one, so t lands right next
+// to it.
+func (s *Service) rankBounds(ctx context.Context, t *Task, afterID, beforeID *int64) (lower, upper string, err error) {
+	lane := t.Lane()
+	if afterID != nil {
+		after, err := s.store.GetByID(ctx, *afterID)
+		if err != nil {
+			return "", "", err
+		}
+		if !lane.contains(after) {
+			return "", "", ErrValidation("afterId must be in the same column or status as the task")
+		}
+		lower = after.Rank
+	}
+	if beforeID != nil {
+		before, err := s.store.GetByID(ctx, *beforeID)
+		if err != nil {
+			return "", "", err
+		}
+		if !lane.contains(before) {
+			return "", "", ErrValidation("beforeId must be in the same column or status as the task")
+		}
+		upper = before.Rank
+	}
+
+	switch {
+	case afterID != nil && beforeID != nil:
+		if lower >= upper {
+			return "", "", ErrValidation("afterId must come before beforeId")
+		}
+	case afterID != nil:
+		upper, err = s.store.NextRank(ctx, lane, lower, t.ID)
+	case beforeID != nil:
+		lower, err = s.store.PrevRank(ctx, lane, upper, t.ID)
+	default:
+		upper, err = s.store.NextRank(ctx, lane, "", t.ID)
+	}
+	return lower, upper, err
+}
+
+// place puts a task on its project's board: in the given column, whose
+// category becomes the task's status, or else in the first column for the
+// task's status unless its current column already matches.
+func (s *Service) place(ctx context.Context, t *Task, columnID *int64) error {
+	if t.ProjectID == nil {
+		t.ColumnID = nil
+		return nil
+	}
+
+	if columnID != nil {
+		status, err := s.boards.ColumnStatus(ctx, *t.ProjectID, *columnID)
+		if err != nil {
+			return err
+		}
+		t.ColumnID, t.Status = columnID, status
+		return nil
+	}
+
+	if t.ColumnID != nil {
+		status, err := s.boards.ColumnStatus(ctx, *t.ProjectID, *t.ColumnID)
+		if err != nil {
+			return err
+		}
+		if status == t.Status {
+			return nil
+		}
+	}
+
+	id, err := s.boards.ColumnFor(ctx, *t.ProjectID, t.Status)
+	if err != nil {
+		return err
+	}
+	t.ColumnID = &id
+	return nil
+}
+
+// AddBlocker records that a task cannot start until the blocker is done.
+// Dependencies that would form a cycle are rejected.
+func (s *Service) AddBlocker(ctx context.Context, id int64, req AddBlockerRequest) (*Task, error) {
+	if req.BlockerID == 0 {
+		return nil, ErrValidation("blockerId is required")
+	}
+	if req.BlockerID == id {
+		return nil, ErrValidation("a task cannot block itself")
+	}
+	if _, err := s.store.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	cycle, err := s.dependsOn(ctx, req.BlockerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if cycle {
+		return nil, ErrValidation(fmt.Sprintf("task %d already waits on task %d", req.BlockerID, id))
+	}
+
+	if err := s.store.AddBlocker(ctx, id, req.BlockerID); err != nil {
+		return nil, err
+	}
+	return s.store.GetByID(ctx, id)
+}
+
+// RemoveBlocker drops a dependency; removing one that does not exist is
+// not an error.
+func (s *Service) RemoveBlocker(ctx context.Context, id, blockerID int64) error {
+	if _, err := s.store.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return s.store.RemoveBlocker(ctx, id, blockerID)
+}
+
+// dependsOn reports whether task from waits on task target, directly or
+// through other tasks.
+func (s *Service) dependsOn(ctx context.Context, from, target int64) (bool, error) {
+	seen := map[int64]bool{from: true}
+	queue := []int64{from}
+	for len(queue) > 0 {
+		t, err := s.store.GetByID(ctx, queue[0])
+		if err != nil {
+			return false, err
+		}
+		queue = queue[1:]
+
+		for _, blocker := range t.BlockedBy {
+			if blocker.ID == target {
+				return true, nil
+			}
+			if !seen[blocker.ID] {
+				seen[blocker.ID] = true
+				queue = append(queue, blocker.ID)
+			}
+		}
+	}
+	return false, nil
+}
+
+// openBlockers returns the ids of the task's blockers that are not done.
+func openBlockers(t *Task) []int64 {
+	var open []int64
+	for _, blocker := range t.BlockedBy {
+		if blocker.Status != StatusDone {
+			open = append(open, blocker.ID)
+		}
+	}
+	return open
+}
+
 func (s *Service) Delete(ctx context.Context, id int64) error {
 	return s.store.Delete(ctx, id)
 }
@@ -7,7 +7,17 @@ import "context"
 type Store interface {
 	Create(ctx context.Context, task *Task) error
 	GetByID(ctx context.Context, id int64) (*Task, error)
-	List(ctx context.Context) ([]Task, error)
+	List(ctx context.Context, filter Filter) ([]Task, error)
 	Update(ctx context.Context, task *Task) error
 	Delete(ctx context.Context, id int64) error
+	// PrevRank and NextRank return the closest rank below or above the given
+	// one among the lane's tasks other than excludeID, or "" if there is
+	// none.
+	PrevRank(ctx context.Context, lane Lane, rank string, excludeID int64) (string, error)
+	NextRank(ctx context.Context, lane Lane, rank string, excludeID int64) (string, error)
+	// Rebalance spreads the ranks of the lane's tasks out evenly, keeping
+	// their order.
+	Rebalance(ctx context.Context, lane Lane) error
+	AddBlocker(ctx context.Context, id, blockerID int64) error
+	RemoveBlocker(ctx context.Context, id, blockerID int64) error
 }
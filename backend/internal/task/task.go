@@ -18,6 +18,9 @@ const (
 	PriorityHigh   Priority = "high"
 )
 
+// maxDurationMinutes bounds durationMinutes at a year.
+const maxDurationMinutes = 366 * 24 * 60
+
 type Task struct {
 	ID          int64      `json:"id"`
 	Title       string     `json:"title"`
@@ -25,7 +28,86 @@ type Task struct {
 	Status      Status     `json:"status"`
 	Priority    Priority   `json:"priority"`
 	DueDate     *time.Time `json:"dueDate,omitempty"`
-	CreatedAt   time.Time  `json:"createdAt"`
+	// StartAt and EndAt place the task on the calendar, to the minute.
+	// All-day tasks run from midnight UTC on their first day to midnight
+	// after their last, so EndAt is exclusive; the days are the calendar
+	// dates in the offset the client sent. A timed task without EndAt
+	// is a moment rather than a span.
+	StartAt *time.Time `json:"startAt,omitempty"`
+	EndAt   *time.Time `json:"endAt,omitempty"`
+	AllDay  bool       `json:"allDay"`
+	// AssigneeID is the member doing the task.
+	AssigneeID *int64   `json:"assigneeId,omitempty"`
+	Tags       []string `json:"tags"`
+	// ProjectID and ColumnID place the task on a project's board; the
+	// column decides the task's status.
+	ProjectID *int64 `json:"projectId,omitempty"`
+	ColumnID  *int64 `json:"columnId,omitempty"`
+	// Rank orders the task within its lane, see Lane; it is a
+	// lexicographic key, see package rank.
+	Rank string `json:"rank"`
+	// CommentCount is the number of comments in the task's thread.
+	CommentCount int `json:"commentCount"`
+	// BlockedBy lists the tasks that must be done before this one can
+	// start; Blocks lists the tasks waiting on this one.
+	BlockedBy []Ref     `json:"blockedBy"`
+	Blocks    []Ref     `json:"blocks"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Ref is a short reference to a related task.
+type Ref struct {
+	ID     int64  `json:"id"`
+	Title  string `json:"title"`
+	Status Status `json:"status"`
+}
+
+// Lane is the set of tasks a task is ordered among: the tasks in its board
+// column, or for tasks outside projects, the tasks with its status.
+type Lane struct {
+	ColumnID *int64
+	Status   Status
+}
+
+// Lane returns the lane the task is ranked in.
+func (t *Task) Lane() Lane {
+	if t.ColumnID != nil {
+		return Lane{ColumnID: t.ColumnID}
+	}
+	return Lane{Status: t.Status}
+}
+
+func (l Lane) contains(t *Task) bool {
+	other := t.Lane()
+	if l.ColumnID != nil || other.ColumnID != nil {
+		return l.ColumnID != nil && other.ColumnID != nil && *l.ColumnID == *other.ColumnID
+	}
+	return l.Status == other.Status
+}
+
+// Filter narrows List results; zero values match everything.
+type Filter struct {
+	Tag        string
+	ProjectID  int64
+	AssigneeID int64
+	// From and To select scheduled tasks overlapping [From, To); either
+	// may be nil to leave that side open.
+	From *time.Time
+	To   *time.Time
+}
+
+// Ranged reports whether the filter selects by time.
+func (f Filter) Ranged() bool {
+	return f.From != nil || f.To != nil
+}
+
+// Conflict is a pair of tasks assigned to the same member whose times
+// overlap from Start to End.
+type Conflict struct {
+	MemberID int64     `json:"memberId"`
+	Tasks    [2]Ref    `json:"tasks"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
 }
 
 type CreateRequest struct {
@@ -33,20 +115,72 @@ type CreateRequest struct {
 	Description string     `json:"description"`
 	Priority    Priority   `json:"priority"`
 	DueDate     *time.Time `json:"dueDate,omitempty"`
+	// ProjectID adds the task to a project, in ColumnID if given or else
+	// in the project's first to-do column.
+	ProjectID *int64     `json:"projectId,omitempty"`
+	ColumnID  *int64     `json:"columnId,omitempty"`
+	StartAt   *time.Time `json:"startAt,omitempty"`
+	// EndAt or DurationMinutes, not both, end a timed task. All-day tasks
+	// take an exclusive EndAt and default to one day.
+	EndAt           *time.Time `json:"endAt,omitempty"`
+	DurationMinutes int        `json:"durationMinutes,omitempty"`
+	AllDay          bool       `json:"allDay,omitempty"`
+	AssigneeID      *int64     `json:"assigneeId,omitempty"`
 }
 
 type UpdateRequest struct {
-	Title       *string   `json:"title,omitempty"`
-	Description *string   `json:"description,omitempty"`
-	Status      *Status   `json:"status,omitempty"`
-	Priority    *Priority `json:"priority,omitempty"`
+	Title       *string    `json:"title,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Status      *Status    `json:"status,omitempty"`
+	Priority    *Priority  `json:"priority,omitempty"`
 	DueDate     *time.Time `json:"dueDate,omitempty"`
+	// ProjectID moves the task to another project; 0 takes it out of its
+	// project. ColumnID moves it within its project and sets its status.
+	ProjectID *int64 `json:"projectId,omitempty"`
+	ColumnID  *int64 `json:"columnId,omitempty"`
+	// StartAt moves the task, keeping its length unless EndAt or
+	// DurationMinutes change it too. Unschedule takes it off the calendar.
+	StartAt         *time.Time `json:"startAt,omitempty"`
+	EndAt           *time.Time `json:"endAt,omitempty"`
+	DurationMinutes *int       `json:"durationMinutes,omitempty"`
+	AllDay          *bool      `json:"allDay,omitempty"`
+	Unschedule      bool       `json:"unschedule,omitempty"`
+	// AssigneeID assigns the task to a member; 0 unassigns it.
+	AssigneeID *int64 `json:"assigneeId,omitempty"`
+	// Force moves a task to in_progress or done while its blockers are
+	// still open.
+	Force bool `json:"force,omitempty"`
+}
+
+// MoveRequest places a task between two neighbours in its lane: after
+// AfterID and before BeforeID. With one neighbour the task goes right next
+// to it, and with neither it goes to the top. ColumnID, Status and Force
+// work as in UpdateRequest, for moves across columns.
+type MoveRequest struct {
+	AfterID  *int64  `json:"afterId,omitempty"`
+	BeforeID *int64  `json:"beforeId,omitempty"`
+	ColumnID *int64  `json:"columnId,omitempty"`
+	Status   *Status `json:"status,omitempty"`
+	Force    bool    `json:"force,omitempty"`
+}
+
+type AddBlockerRequest struct {
+	BlockerID int64 `json:"blockerId"`
 }
 
 func (r CreateRequest) Validate() error {
 	if r.Title == "" {
 		return ErrValidation("title is required")
 	}
+	if r.ColumnID != nil && r.ProjectID == nil {
+		return ErrValidation("columnId requires projectId")
+	}
+	if r.EndAt != nil && r.DurationMinutes != 0 {
+		return ErrValidation("give endAt or durationMinutes, not both")
+	}
+	if _, _, err := schedule(r.StartAt, r.EndAt, r.DurationMinutes, r.AllDay); err != nil {
+		return err
+	}
 	if r.Priority != "" && !isValidPriority(r.Priority) {
 		return ErrValidation("invalid priority: must be low, medium, or high")
 	}
@@ -60,9 +194,76 @@ func (r UpdateRequest) Validate() error {
 	if r.Priority != nil && !isValidPriority(*r.Priority) {
 		return ErrValidation("invalid priority: must be low, medium, or high")
 	}
+	if r.EndAt != nil && r.DurationMinutes != nil {
+		return ErrValidation("give endAt or durationMinutes, not both")
+	}
+	if r.DurationMinutes != nil && (*r.DurationMinutes <= 0 || *r.DurationMinutes > maxDurationMinutes) {
+		return ErrValidation("durationMinutes must be between 1 and 527040 (a year)")
+	}
+	if r.Unschedule && (r.StartAt != nil || r.EndAt != nil || r.DurationMinutes != nil || r.AllDay != nil) {
+		return ErrValidation("unschedule cannot be combined with new times")
+	}
 	return nil
 }
 
+// schedule checks a task's times and normalises them: to UTC minutes, or
+// to whole days for all-day tasks, with EndAt worked out from a duration
+// in minutes or, for all-day tasks, defaulting to the next midnight.
+func schedule(start, end *time.Time, duration int, allDay bool) (*time.Time, *time.Time, error) {
+	if start == nil {
+		if end != nil || duration != 0 {
+			return nil, nil, ErrValidation("endAt and durationMinutes require startAt")
+		}
+		if allDay {
+			return nil, nil, ErrValidation("all-day tasks require startAt")
+		}
+		return nil, nil, nil
+	}
+	if duration < 0 || duration > maxDurationMinutes {
+		return nil, nil, ErrValidation("durationMinutes must be between 1 and 527040 (a year)")
+	}
+
+	s := start.UTC().Truncate(time.Minute)
+	var e *time.Time
+	if allDay {
+		if duration != 0 {
+			return nil, nil, ErrValidation("all-day tasks take endAt, not durationMinutes")
+		}
+		s = day(*start)
+		next := s.AddDate(0, 0, 1)
+		if end != nil {
+			// An end during a day covers that whole day.
+			if next = day(*end); !startsDay(*end) {
+				next = next.AddDate(0, 0, 1)
+			}
+		}
+		e = &next
+	} else if end != nil {
+		t := end.UTC().Truncate(time.Minute)
+		e = &t
+	} else if duration > 0 {
+		t := s.Add(time.Duration(duration) * time.Minute)
+		e = &t
+	}
+
+	if e != nil && !e.After(s) {
+		return nil, nil, ErrValidation("endAt must be after startAt")
+	}
+	return &s, e, nil
+}
+
+// day returns midnight UTC at the start of t's day. The date is read in
+// t's own offset, so a client's local midnight stays on its calendar day.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// startsDay reports whether t is midnight in its own offset.
+func startsDay(t time.Time) bool {
+	h, m, sec := t.Clock()
+	return h == 0 && m == 0 && sec == 0 && t.Nanosecond() == 0
+}
+
 func isValidStatus(s Status) bool {
 	return s == StatusTodo || s == StatusInProgress || s == StatusDone
 }
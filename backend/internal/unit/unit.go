@@ -0,0 +1,65 @@
+// Package unit recognises the spellings of common measurement units, so
+// that quantities written differently can be compared and added up.
+package unit
+
+import "strings"
+
+// aliases maps the spellings of common cooking units to a canonical
+// abbreviation so that "2 tablespoons" and "1 tbsp" can be merged.
+var aliases = map[string]string{
+	"g": "g", "gr": "g", "gram": "g", "grams": "g", "gramme": "g", "grammes": "g",
+	"kg": "kg", "kilo": "kg", "kilos": "kg", "kilogram": "kg", "kilograms": "kg",
+	"mg": "mg", "milligram": "mg", "milligrams": "mg",
+	"ml": "ml", "milliliter": "ml", "milliliters": "ml", "millilitre": "ml", "millilitres": "ml",
+	"cl": "cl", "dl": "dl",
+	"l": "l", "liter": "l", "liters": "l", "litre": "l", "litres": "l",
+	"tsp": "tsp", "teaspoon": "tsp", "teaspoons": "tsp", "t": "tsp",
+	"tbsp": "tbsp", "tablespoon": "tbsp", "tablespoons": "tbsp", "tbs": "tbsp", "tbl": "tbsp", "T": "tbsp",
+	"cup": "cup", "cups": "cup", "c": "cup",
+	"oz": "oz", "ounce": "oz", "ounces": "oz",
+	"fl oz": "fl oz",
+	"lb":    "lb", "lbs": "lb", "pound": "lb", "pounds": "lb",
+	"pint": "pint", "pints": "pint", "pt": "pint",
+	"quart": "quart", "quarts": "quart", "qt": "quart",
+	"gallon": "gallon", "gallons": "gallon",
+	"pinch": "pinch", "pinches": "pinch",
+	"dash": "dash", "dashes": "dash",
+	"clove": "clove", "cloves": "clove",
+	"can": "can", "cans": "can", "tin": "can", "tins": "can",
+	"package": "package", "packages": "package", "pkg": "package", "pack": "package", "packs": "package",
+	"slice": "slice", "slices": "slice",
+	"bunch": "bunch", "bunches": "bunch",
+	"sprig": "sprig", "sprigs": "sprig",
+	"stick": "stick", "sticks": "stick",
+	"handful": "handful", "handfuls": "handful",
+	"piece": "piece", "pieces": "piece", "pc": "piece", "pcs": "piece",
+}
+
+// Normalize returns the canonical form of a unit, or the trimmed,
+// lowercased input if the unit is not known.
+func Normalize(unit string) string {
+	unit = strings.TrimSuffix(strings.TrimSpace(unit), ".")
+	if canonical, ok := aliases[unit]; ok {
+		return canonical
+	}
+	lower := strings.ToLower(unit)
+	if canonical, ok := aliases[lower]; ok {
+		return canonical
+	}
+	return lower
+}
+
+// Lookup returns the canonical form of a word if it names a unit. Single
+// letters only count when written exactly, since "T" is a tablespoon but
+// "t" a teaspoon.
+func Lookup(word string) (string, bool) {
+	word = strings.TrimSuffix(word, ".")
+	if canonical, ok := aliases[word]; ok {
+		return canonical, true
+	}
+	canonical, ok := aliases[strings.ToLower(word)]
+	if ok && len(word) == 1 {
+		return "", false
+	}
+	return canonical, ok
+}
@@ -0,0 +1,56 @@
+package wishlist
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/linkmeta"
+)
+
+// enrichTimeout bounds a background link lookup, fetch included.
+const enrichTimeout = 30 * time.Second
+
+// LinkPreviewer looks up the title, image and price behind a product link.
+type LinkPreviewer interface {
+	Lookup(ctx context.Context, url string) (*linkmeta.Metadata, error)
+}
+
+// enrichLater fills in an item's missing details from its link without
+// holding up the request that created or changed it.
+func (s *Service) enrichLater(wishlistID, id int64, link string) {
+	if s.links == nil || link == "" {
+		return
+	}
+
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), enrichTimeout)
+		defer cancel()
+
+		if err := s.enrich(ctx, wishlistID, id, link); err != nil {
+			log.Printf("wishlist item %d: link metadata for %s: %v", id, link, err)
+		}
+	}()
+}
+
+// enrich only fills fields that are still empty, so anything the household
+// typed in itself, before or during the lookup, is kept. The store checks
+// this as it writes, so an edit made while the link is fetched is not lost.
+func (s *Service) enrich(ctx context.Context, wishlistID, id int64, link string) error {
+	meta, err := s.links.Lookup(ctx, link)
+	if err != nil {
+		return err
+	}
+	if !currencyPattern.MatchString(meta.Currency) {
+		meta.Currency = ""
+	}
+	return s.store.FillItemDetails(ctx, wishlistID, id, link, *meta, time.Now())
+}
+
+// Wait blocks until background link lookups have finished.
+func (s *Service) Wait() {
+	s.pending.Wait()
+}
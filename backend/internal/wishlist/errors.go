@@ -19,9 +19,57 @@ type NotFoundError struct {
 }
 
 func (e NotFoundError) Error() string {
-	return fmt.Sprintf("wishlist item with id %d not found", e.ID)
+	return fmt.Sprintf("wishlist with id %d not found", e.ID)
 }
 
 func ErrNotFound(id int64) NotFoundError {
 	return NotFoundError{ID: id}
 }
+
+type ItemNotFoundError struct {
+	ID int64
+}
+
+func (e ItemNotFoundError) Error() string {
+	return fmt.Sprintf("wishlist item with id %d not found", e.ID)
+}
+
+func ErrItemNotFound(id int64) ItemNotFoundError {
+	return ItemNotFoundError{ID: id}
+}
+
+type ForbiddenError struct {
+	Message string
+}
+
+func (e ForbiddenError) Error() string {
+	return e.Message
+}
+
+func ErrForbidden(msg string) ForbiddenError {
+	return ForbiddenError{Message: msg}
+}
+
+type AlreadyReservedError struct {
+	ID int64
+}
+
+func (e AlreadyReservedError) Error() string {
+	return fmt.Sprintf("wishlist item with id %d is already reserved by someone else", e.ID)
+}
+
+func ErrAlreadyReserved(id int64) AlreadyReservedError {
+	return AlreadyReservedError{ID: id}
+}
+
+// ShareNotFoundError is returned for share tokens that do not exist, have
+// been revoked or have expired; callers cannot tell these apart.
+type ShareNotFoundError struct{}
+
+func (e ShareNotFoundError) Error() string {
+	return "shared wishlist not found"
+}
+
+func ErrShareNotFound() ShareNotFoundError {
+	return ShareNotFoundError{}
+}
@@ -0,0 +1,117 @@
+package wishlist
+
+import (
+	"net/url"
+	"regexp"
+	"time"
+)
+
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+)
+
+// Item is a single thing on a wishlist.
+type Item struct {
+	ID         int64  `json:"id"`
+	WishlistID int64  `json:"wishlistId"`
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	// PriceCents is the price in minor units of Currency, or nil when
+	// unknown.
+	PriceCents *int64   `json:"priceCents"`
+	Currency   string   `json:"currency"`
+	Quantity   int      `json:"quantity"`
+	Priority   Priority `json:"priority"`
+	ImageURL   string   `json:"imageUrl"`
+	// Reservation is nil when nobody has claimed the item, and is always
+	// nil when shown to the wishlist's owner.
+	Reservation *Reservation `json:"reservation"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+// CreateItemRequest adds an item. Name may be left out when URL is given;
+// it is then filled in from the linked page, as are a missing image and
+// price.
+type CreateItemRequest struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	PriceCents *int64 `json:"priceCents,omitempty"`
+	Currency   string `json:"currency"`
+	// Quantity defaults to 1.
+	Quantity int `json:"quantity"`
+	// Priority defaults to medium.
+	Priority Priority `json:"priority"`
+	ImageURL string   `json:"imageUrl"`
+}
+
+func (r CreateItemRequest) Validate() error {
+	if r.Name == "" && r.URL == "" {
+		return ErrValidation("name or url is required")
+	}
+	return validateItemFields(&r.URL, r.PriceCents, &r.Currency, &r.Quantity, &r.Priority, &r.ImageURL)
+}
+
+// UpdateItemRequest changes the given fields. A negative PriceCents clears
+// the price.
+type UpdateItemRequest struct {
+	Name       *string   `json:"name,omitempty"`
+	URL        *string   `json:"url,omitempty"`
+	PriceCents *int64    `json:"priceCents,omitempty"`
+	Currency   *string   `json:"currency,omitempty"`
+	Quantity   *int      `json:"quantity,omitempty"`
+	Priority   *Priority `json:"priority,omitempty"`
+	ImageURL   *string   `json:"imageUrl,omitempty"`
+}
+
+func (r UpdateItemRequest) Validate() error {
+	if r.Name != nil && *r.Name == "" {
+		return ErrValidation("name must not be empty")
+	}
+	if r.Quantity != nil && *r.Quantity <= 0 {
+		return ErrValidation("quantity must be positive")
+	}
+	if r.Priority != nil && !isValidPriority(*r.Priority) {
+		return ErrValidation("invalid priority: must be low, medium, or high")
+	}
+	return validateItemFields(r.URL, nil, r.Currency, nil, r.Priority, r.ImageURL)
+}
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// validateItemFields checks the fields shared by create and update; nil
+// pointers are skipped.
+func validateItemFields(link *string, priceCents *int64, currency *string, quantity *int, priority *Priority, imageURL *string) error {
+	if link != nil && *link != "" && !isValidURL(*link) {
+		return ErrValidation("url must be an absolute http or https URL")
+	}
+	if priceCents != nil && *priceCents < 0 {
+		return ErrValidation("priceCents must not be negative")
+	}
+	if currency != nil && *currency != "" && !currencyPattern.MatchString(*currency) {
+		return ErrValidation("currency must be a three-letter ISO 4217 code such as EUR")
+	}
+	if quantity != nil && *quantity < 0 {
+		return ErrValidation("quantity must be positive")
+	}
+	if priority != nil && *priority != "" && !isValidPriority(*priority) {
+		return ErrValidation("invalid priority: must be low, medium, or high")
+	}
+	if imageURL != nil && *imageURL != "" && !isValidURL(*imageURL) {
+		return ErrValidation("imageUrl must be an absolute http or https URL")
+	}
+	return nil
+}
+
+func isValidURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func isValidPriority(p Priority) bool {
+	return p == PriorityLow || p == PriorityMedium || p == PriorityHigh
+}
@@ -0,0 +1,44 @@
+package wishlist
+
+import "time"
+
+type ReservationStatus string
+
+const (
+	ReservationReserved ReservationStatus = "reserved"
+	ReservationBought   ReservationStatus = "bought"
+)
+
+// Reservation records that someone has claimed an item as a gift: either a
+// household member, or a guest who reserved it through a share link and is
+// known only by the name they gave.
+type Reservation struct {
+	MemberID   *int64            `json:"memberId"`
+	GuestName  string            `json:"guestName,omitempty"`
+	Status     ReservationStatus `json:"status"`
+	ReservedAt time.Time         `json:"reservedAt"`
+}
+
+func (r *Reservation) heldBy(memberID int64) bool {
+	return r.MemberID != nil && *r.MemberID == memberID
+}
+
+// releasableBy reports whether the member may release the reservation.
+// Guests cannot come back to release their own, so any member who may
+// reserve the item can clear a guest reservation instead.
+func (r *Reservation) releasableBy(memberID int64) bool {
+	return r.MemberID == nil || r.heldBy(memberID)
+}
+
+// hidesReservationsFrom reports whether reservations on w must be kept from
+// the viewer. Owned wishlists hide them from their owner, and from anyone
+// who has not said who they are, since that might be the owner.
+func (w *Wishlist) hidesReservationsFrom(viewerID int64, known bool) bool {
+	return w.OwnerID != nil && (!known || viewerID == *w.OwnerID)
+}
+
+func hideReservations(items []Item) {
+	for i := range items {
+		items[i].Reservation = nil
+	}
+}
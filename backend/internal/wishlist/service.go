@@ -2,15 +2,31 @@ package wishlist
 
 import (
 	"context"
+	"sync"
 	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/member"
+	"github.com/stadtaev/lofam/backend/internal/palette"
 )
 
+// Members looks up the household members that own wishlists and reserve
+// items.
+type Members interface {
+	GetByID(ctx context.Context, id int64) (*member.Member, error)
+}
+
 type Service struct {
-	store Store
+	store   Store
+	members Members
+	colors  palette.Checker
+	links   LinkPreviewer
+	pending sync.WaitGroup
 }
 
-func NewService(store Store) *Service {
-	return &Service{store: store}
+// NewService creates a wishlist service. links may be nil, in which case
+// item links are stored without looking up their details.
+func NewService(store Store, members Members, colors palette.Checker, links LinkPreviewer) *Service {
+	return &Service{store: store, members: members, colors: colors, links: links}
 }
 
 func (s *Service) Create(ctx context.Context, req CreateRequest) (*Wishlist, error) {
@@ -18,11 +34,21 @@ func (s *Service) Create(ctx context.Context, req CreateRequest) (*Wishlist, err
 		return nil, err
 	}
 
+	if err := s.colors.Check(ctx, string(req.Color)); err != nil {
+		return nil, err
+	}
+	if err := s.checkOwner(ctx, req.OwnerID); err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	w := &Wishlist{
 		Title:     req.Title,
 		Content:   req.Content,
 		Color:     req.Color,
+		OwnerID:   req.OwnerID,
+		Items:     []Item{},
+		Tags:      []string{},
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -35,11 +61,47 @@ func (s *Service) Create(ctx context.Context, req CreateRequest) (*Wishlist, err
 }
 
 func (s *Service) GetByID(ctx context.Context, id int64) (*Wishlist, error) {
-	return s.store.GetByID(ctx, id)
+	w, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.Items, err = s.store.ListItems(ctx, []int64{id}); err != nil {
+		return nil, err
+	}
+	s.redact(ctx, w, w.Items)
+
+	return w, nil
 }
 
-func (s *Service) List(ctx context.Context) ([]Wishlist, error) {
-	return s.store.List(ctx)
+func (s *Service) List(ctx context.Context, filter Filter) ([]Wishlist, error) {
+	lists, err := s.store.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(lists))
+	for i, w := range lists {
+		ids[i] = w.ID
+	}
+	items, err := s.store.ListItems(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byList := make(map[int64][]Item, len(lists))
+	for _, item := range items {
+		byList[item.WishlistID] = append(byList[item.WishlistID], item)
+	}
+	for i := range lists {
+		lists[i].Items = byList[lists[i].ID]
+		if lists[i].Items == nil {
+			lists[i].Items = []Item{}
+		}
+		s.redact(ctx, &lists[i], lists[i].Items)
+	}
+
+	return lists, nil
 }
 
 func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Wishlist, error) {
@@ -52,6 +114,20 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Wis
 		return nil, err
 	}
 
+	if err := s.colors.Check(ctx, string(req.Color)); err != nil {
+		return nil, err
+	}
+	switch {
+	case req.OwnerID == nil:
+	case *req.OwnerID == 0:
+		w.OwnerID = nil
+	default:
+		if err := s.checkOwner(ctx, req.OwnerID); err != nil {
+			return nil, err
+		}
+		w.OwnerID = req.OwnerID
+	}
+
 	w.Title = req.Title
 	w.Content = req.Content
 	w.Color = req.Color
@@ -61,9 +137,385 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Wis
 		return nil, err
 	}
 
+	if w.Items, err = s.store.ListItems(ctx, []int64{id}); err != nil {
+		return nil, err
+	}
+	s.redact(ctx, w, w.Items)
+
 	return w, nil
 }
 
 func (s *Service) Delete(ctx context.Context, id int64) error {
 	return s.store.Delete(ctx, id)
 }
+
+func (s *Service) CreateItem(ctx context.Context, wishlistID int64, req CreateItemRequest) (*Item, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.GetByID(ctx, wishlistID); err != nil {
+		return nil, err
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	priority := req.Priority
+	if priority == "" {
+		priority = PriorityMedium
+	}
+
+	// Until the link has been looked up, it stands in for a missing name.
+	name := req.Name
+	if name == "" {
+		name = req.URL
+	}
+
+	now := time.Now()
+	item := &Item{
+		WishlistID: wishlistID,
+		Name:       name,
+		URL:        req.URL,
+		PriceCents: req.PriceCents,
+		Currency:   req.Currency,
+		Quantity:   quantity,
+		Priority:   priority,
+		ImageURL:   req.ImageURL,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := s.store.CreateItem(ctx, item); err != nil {
+		return nil, err
+	}
+	s.enrichLater(wishlistID, item.ID, item.URL)
+
+	return item, nil
+}
+
+func (s *Service) GetItem(ctx context.Context, wishlistID, id int64) (*Item, error) {
+	w, err := s.store.GetByID(ctx, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.store.GetItem(ctx, wishlistID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.redactItem(ctx, w, item)
+
+	return item, nil
+}
+
+func (s *Service) ListItems(ctx context.Context, wishlistID int64) ([]Item, error) {
+	w, err := s.store.GetByID(ctx, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.store.ListItems(ctx, []int64{wishlistID})
+	if err != nil {
+		return nil, err
+	}
+	s.redact(ctx, w, items)
+
+	return items, nil
+}
+
+func (s *Service) UpdateItem(ctx context.Context, wishlistID, id int64, req UpdateItemRequest) (*Item, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	w, err := s.store.GetByID(ctx, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.store.GetItem(ctx, wishlistID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+	linkChanged := req.URL != nil && *req.URL != item.URL
+	if req.URL != nil {
+		item.URL = *req.URL
+	}
+	if req.PriceCents != nil {
+		item.PriceCents = req.PriceCents
+		if *req.PriceCents < 0 {
+			item.PriceCents = nil
+		}
+	}
+	if req.Currency != nil {
+		item.Currency = *req.Currency
+	}
+	if req.Quantity != nil {
+		item.Quantity = *req.Quantity
+	}
+	if req.Priority != nil {
+		item.Priority = *req.Priority
+	}
+	if req.ImageURL != nil {
+		item.ImageURL = *req.ImageURL
+	}
+	item.UpdatedAt = time.Now()
+
+	if err := s.store.UpdateItem(ctx, item); err != nil {
+		return nil, err
+	}
+	if linkChanged {
+		s.enrichLater(wishlistID, id, item.URL)
+	}
+	s.redactItem(ctx, w, item)
+
+	return item, nil
+}
+
+func (s *Service) DeleteItem(ctx context.Context, wishlistID, id int64) error {
+	return s.store.DeleteItem(ctx, wishlistID, id)
+}
+
+// Reserve claims an item for the member making the request, so that other
+// members know not to buy it too. Reserving an item the member already
+// holds keeps its current status.
+func (s *Service) Reserve(ctx context.Context, wishlistID, id int64) (*Item, error) {
+	return s.reserve(ctx, wishlistID, id, ReservationReserved)
+}
+
+// MarkBought records that the member making the request has bought the
+// item, reserving it first if nobody has.
+func (s *Service) MarkBought(ctx context.Context, wishlistID, id int64) (*Item, error) {
+	return s.reserve(ctx, wishlistID, id, ReservationBought)
+}
+
+func (s *Service) reserve(ctx context.Context, wishlistID, id int64, status ReservationStatus) (*Item, error) {
+	memberID, err := s.reserver(ctx, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.store.GetItem(ctx, wishlistID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if r := item.Reservation; r != nil {
+		if !r.heldBy(memberID) {
+			return nil, ErrAlreadyReserved(id)
+		}
+		if status == ReservationReserved {
+			status = r.Status
+		}
+	}
+
+	item.Reservation = &Reservation{
+		MemberID:   &memberID,
+		Status:     status,
+		ReservedAt: time.Now(),
+	}
+	if err := s.store.ReserveItem(ctx, item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// Unreserve releases the requesting member's claim on an item, or a claim
+// made by a guest through a share link.
+func (s *Service) Unreserve(ctx context.Context, wishlistID, id int64) (*Item, error) {
+	memberID, err := s.reserver(ctx, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.store.GetItem(ctx, wishlistID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.Reservation == nil {
+		return item, nil
+	}
+	if !item.Reservation.releasableBy(memberID) {
+		return nil, ErrForbidden("only the member who reserved an item can release it")
+	}
+
+	if err := s.store.UnreserveItem(ctx, wishlistID, id); err != nil {
+		return nil, err
+	}
+	item.Reservation = nil
+
+	return item, nil
+}
+
+// reserver returns the member making the request after checking that they
+// may reserve items on the wishlist.
+func (s *Service) reserver(ctx context.Context, wishlistID int64) (int64, error) {
+	w, err := s.store.GetByID(ctx, wishlistID)
+	if err != nil {
+		return 0, err
+	}
+
+	memberID, ok := member.IDFromContext(ctx)
+	if !ok {
+		return 0, ErrForbidden("reserving items requires a household member")
+	}
+	if _, err := s.members.GetByID(ctx, memberID); err != nil {
+		return 0, err
+	}
+	if w.OwnerID != nil && *w.OwnerID == memberID {
+		return 0, ErrForbidden("items on your own wishlist cannot be reserved by you")
+	}
+
+	return memberID, nil
+}
+
+func (s *Service) checkOwner(ctx context.Context, ownerID *int64) error {
+	if ownerID == nil {
+		return nil
+	}
+	_, err := s.members.GetByID(ctx, *ownerID)
+	return err
+}
+
+// redact removes reservations from items that the requesting member must
+// not see.
+func (s *Service) redact(ctx context.Context, w *Wishlist, items []Item) {
+	viewerID, known := member.IDFromContext(ctx)
+	if w.hidesReservationsFrom(viewerID, known) {
+		hideReservations(items)
+	}
+}
+
+func (s *Service) redactItem(ctx context.Context, w *Wishlist, item *Item) {
+	viewerID, known := member.IDFromContext(ctx)
+	if w.hidesReservationsFrom(viewerID, known) {
+		item.Reservation = nil
+	}
+}
+
+func (s *Service) CreateShare(ctx context.Context, wishlistID int64, req CreateShareRequest) (*Share, error) {
+	now := time.Now()
+	if err := req.Validate(now); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.GetByID(ctx, wishlistID); err != nil {
+		return nil, err
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	share := &Share{
+		WishlistID: wishlistID,
+		Token:      token,
+		ExpiresAt:  req.ExpiresAt,
+		CreatedAt:  now,
+	}
+
+	if err := s.store.CreateShare(ctx, share); err != nil {
+		return nil, err
+	}
+
+	return share, nil
+}
+
+func (s *Service) ListShares(ctx context.Context, wishlistID int64) ([]Share, error) {
+	if _, err := s.store.GetByID(ctx, wishlistID); err != nil {
+		return nil, err
+	}
+	return s.store.ListShares(ctx, wishlistID)
+}
+
+// RevokeShare deletes a share so that its link stops working.
+func (s *Service) RevokeShare(ctx context.Context, wishlistID, id int64) error {
+	return s.store.DeleteShare(ctx, wishlistID, id)
+}
+
+// GetShared returns the public view of the wishlist behind a share token.
+func (s *Service) GetShared(ctx context.Context, token string) (*SharedWishlist, error) {
+	share, err := s.validShare(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := s.store.GetByID(ctx, share.WishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.store.ListItems(ctx, []int64{w.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	shared := &SharedWishlist{
+		Title:     w.Title,
+		Content:   w.Content,
+		Color:     w.Color,
+		Items:     make([]SharedItem, len(items)),
+		ExpiresAt: share.ExpiresAt,
+	}
+	for i, item := range items {
+		shared.Items[i] = newSharedItem(item)
+	}
+
+	return shared, nil
+}
+
+// ReserveShared lets a guest with a share link reserve an item that nobody
+// has reserved yet.
+func (s *Service) ReserveShared(ctx context.Context, token string, itemID int64, req GuestReservationRequest) (*SharedItem, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	share, err := s.validShare(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.store.GetItem(ctx, share.WishlistID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Reservation != nil {
+		return nil, ErrAlreadyReserved(itemID)
+	}
+
+	item.Reservation = &Reservation{
+		GuestName:  req.Name,
+		Status:     ReservationReserved,
+		ReservedAt: time.Now(),
+	}
+	if err := s.store.ReserveItem(ctx, item); err != nil {
+		return nil, err
+	}
+
+	shared := newSharedItem(*item)
+	return &shared, nil
+}
+
+func (s *Service) validShare(ctx context.Context, token string) (*Share, error) {
+	if token == "" {
+		return nil, ErrShareNotFound()
+	}
+	share, err := s.store.GetShareByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if share.expired(time.Now()) {
+		return nil, ErrShareNotFound()
+	}
+	return share, nil
+}
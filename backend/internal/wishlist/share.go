@@ -0,0 +1,100 @@
+package wishlist
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxGuestNameLength limits the name a guest leaves when reserving an item.
+const maxGuestNameLength = 100
+
+// Share is a link that lets people outside the household view a wishlist
+// and reserve items on it without signing in.
+type Share struct {
+	ID         int64      `json:"id"`
+	WishlistID int64      `json:"wishlistId"`
+	Token      string     `json:"token"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func (s *Share) expired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
+
+type CreateShareRequest struct {
+	// ExpiresAt is optional; shares without it stay valid until revoked.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+func (r CreateShareRequest) Validate(now time.Time) error {
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(now) {
+		return ErrValidation("expiresAt must be in the future")
+	}
+	return nil
+}
+
+// SharedWishlist is the public view of a wishlist behind a share link. It
+// leaves out everything that identifies household members, including who
+// has reserved what.
+type SharedWishlist struct {
+	Title     string       `json:"title"`
+	Content   string       `json:"content"`
+	Color     Color        `json:"color"`
+	Items     []SharedItem `json:"items"`
+	ExpiresAt *time.Time   `json:"expiresAt"`
+}
+
+type SharedItem struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	PriceCents *int64   `json:"priceCents"`
+	Currency   string   `json:"currency"`
+	Quantity   int      `json:"quantity"`
+	Priority   Priority `json:"priority"`
+	ImageURL   string   `json:"imageUrl"`
+	Reserved   bool     `json:"reserved"`
+}
+
+func newSharedItem(item Item) SharedItem {
+	return SharedItem{
+		ID:         item.ID,
+		Name:       item.Name,
+		URL:        item.URL,
+		PriceCents: item.PriceCents,
+		Currency:   item.Currency,
+		Quantity:   item.Quantity,
+		Priority:   item.Priority,
+		ImageURL:   item.ImageURL,
+		Reserved:   item.Reservation != nil,
+	}
+}
+
+// GuestReservationRequest reserves an item through a share link.
+type GuestReservationRequest struct {
+	Name string `json:"name"`
+}
+
+func (r *GuestReservationRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return ErrValidation("name is required")
+	}
+	if utf8.RuneCountInString(r.Name) > maxGuestNameLength {
+		return ErrValidation("name must be at most 100 characters")
+	}
+	return nil
+}
+
+// newShareToken returns an unguessable URL-safe token.
+func newShareToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
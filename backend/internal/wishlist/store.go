@@ -1,11 +1,42 @@
 package wishlist
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/stadtaev/lofam/backend/internal/linkmeta"
+)
 
 type Store interface {
 	Create(ctx context.Context, w *Wishlist) error
 	GetByID(ctx context.Context, id int64) (*Wishlist, error)
-	List(ctx context.Context) ([]Wishlist, error)
+	List(ctx context.Context, filter Filter) ([]Wishlist, error)
 	Update(ctx context.Context, w *Wishlist) error
+	// Delete removes a wishlist together with its items.
 	Delete(ctx context.Context, id int64) error
+
+	CreateItem(ctx context.Context, item *Item) error
+	// GetItem returns an item of the given wishlist; items of other
+	// wishlists are reported as not found.
+	GetItem(ctx context.Context, wishlistID, id int64) (*Item, error)
+	// ListItems returns the items of the given wishlists, highest priority
+	// first.
+	ListItems(ctx context.Context, wishlistIDs []int64) ([]Item, error)
+	UpdateItem(ctx context.Context, item *Item) error
+	// FillItemDetails copies the title, image and price from meta into
+	// the item's empty fields, unless its link is no longer link.
+	FillItemDetails(ctx context.Context, wishlistID, id int64, link string, meta linkmeta.Metadata, updatedAt time.Time) error
+	DeleteItem(ctx context.Context, wishlistID, id int64) error
+	// ReserveItem stores item.Reservation unless the item is already
+	// reserved by someone else. Guest reservations only succeed on items
+	// nobody has reserved.
+	ReserveItem(ctx context.Context, item *Item) error
+	UnreserveItem(ctx context.Context, wishlistID, id int64) error
+
+	CreateShare(ctx context.Context, share *Share) error
+	// GetShareByToken returns the share with the given token, expired or
+	// not.
+	GetShareByToken(ctx context.Context, token string) (*Share, error)
+	ListShares(ctx context.Context, wishlistID int64) ([]Share, error)
+	DeleteShare(ctx context.Context, wishlistID, id int64) error
 }
@@ -2,35 +2,41 @@ package wishlist
 
 import "time"
 
+// Color names an entry of the configurable palette.
 type Color string
 
-const (
-	ColorYellow Color = "yellow"
-	ColorPink   Color = "pink"
-	ColorGreen  Color = "green"
-)
-
 type Wishlist struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	Color     Color     `json:"color"`
+	ID      int64  `json:"id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Color   Color  `json:"color"`
+	// OwnerID is the member the wishlist belongs to, or nil for a wishlist
+	// shared by the whole household.
+	OwnerID   *int64    `json:"ownerId"`
+	Items     []Item    `json:"items"`
+	Tags      []string  `json:"tags"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// Filter narrows List results; zero values match everything.
+type Filter struct {
+	Tag string
+}
+
 type CreateRequest struct {
 	Title   string `json:"title"`
 	Content string `json:"content"`
 	Color   Color  `json:"color"`
+	OwnerID *int64 `json:"ownerId,omitempty"`
 }
 
 func (r CreateRequest) Validate() error {
 	if r.Title == "" {
 		return ErrValidation("title is required")
 	}
-	if !isValidColor(r.Color) {
-		return ErrValidation("color must be yellow, pink, or green")
+	if r.Color == "" {
+		return ErrValidation("color is required")
 	}
 	return nil
 }
@@ -39,18 +45,17 @@ type UpdateRequest struct {
 	Title   string `json:"title"`
 	Content string `json:"content"`
 	Color   Color  `json:"color"`
+	// OwnerID leaves the owner unchanged when omitted; 0 makes the wishlist
+	// shared by the whole household.
+	OwnerID *int64 `json:"ownerId,omitempty"`
 }
 
 func (r UpdateRequest) Validate() error {
 	if r.Title == "" {
 		return ErrValidation("title is required")
 	}
-	if !isValidColor(r.Color) {
-		return ErrValidation("color must be yellow, pink, or green")
+	if r.Color == "" {
+		return ErrValidation("color is required")
 	}
 	return nil
 }
-
-func isValidColor(c Color) bool {
-	return c == ColorYellow || c == ColorPink || c == ColorGreen
-}